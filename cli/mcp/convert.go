@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"common-tasks-mcp/mcp/server"
+	"common-tasks-mcp/pkg/graph_manager"
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	// Export and import command flags
+	convertDirectory string
+	convertDBPath    string
+	convertNodesDir  string
+	convertVerbose   bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export nodes from the bolt database to YAML files",
+	Long: `Export every node from the bolt database to the nodes directory as one YAML
+file per node, so changes can be reviewed and committed with git.
+
+The nodes directory is made to match the database exactly: files for nodes that
+are not in the database are removed. Nodes are validated against the
+relationships.yaml and mcp.yaml in the directory. The server must not be running
+against the database while exporting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runConvert(func(log *zap.Logger) (store.Store, store.Store, error) {
+			paths := convertPaths()
+			dbStore, err := store.NewBoltStore(paths.db, log)
+			if err != nil {
+				return nil, nil, err
+			}
+			return dbStore, store.NewDirStore(paths.nodes, log), nil
+		})
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import nodes from YAML files into the bolt database",
+	Long: `Import every node from the nodes directory into the bolt database, for
+example after pulling changes made to the YAML files with git.
+
+The database is made to match the nodes directory exactly: nodes that have no
+YAML file are removed. Nodes are validated against the relationships.yaml and
+mcp.yaml in the directory. The import is applied in a single transaction. The
+server must not be running against the database while importing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runConvert(func(log *zap.Logger) (store.Store, store.Store, error) {
+			paths := convertPaths()
+			dbStore, err := store.NewBoltStore(paths.db, log)
+			if err != nil {
+				return nil, nil, err
+			}
+			return store.NewDirStore(paths.nodes, log), dbStore, nil
+		})
+	},
+}
+
+// storagePaths holds the locations used by the export and import commands
+type storagePaths struct {
+	db    string
+	nodes string
+}
+
+// convertPaths resolves the database file and nodes directory from the flags
+func convertPaths() storagePaths {
+	paths := storagePaths{db: convertDBPath, nodes: convertNodesDir}
+	if paths.db == "" {
+		paths.db = filepath.Join(convertDirectory, "nodes.db")
+	}
+	if paths.nodes == "" {
+		paths.nodes = filepath.Join(convertDirectory, "nodes")
+	}
+	return paths
+}
+
+// runConvert loads and validates the nodes of the source store and mirrors them
+// to the target store
+func runConvert(open func(log *zap.Logger) (source, target store.Store, err error)) {
+	log, err := logger.New(convertVerbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	source, target, err := open(log)
+	if err != nil {
+		log.Error("Failed to open storage", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error opening storage: %v\n", err)
+		os.Exit(1)
+	}
+
+	count, err := convert(log, convertDirectory, source, target)
+
+	source.Close()
+	target.Close()
+
+	if err != nil {
+		log.Error("Conversion failed", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Converted %d nodes\n", count)
+}
+
+// convert loads the nodes of the source store with the relationships, kinds and
+// attributes configured in directory, so they are validated the way the server
+// validates them, and mirrors them to the target store. It returns the number
+// of nodes converted.
+func convert(log *zap.Logger, directory string, source, target store.Store) (int, error) {
+	mcpConfig, err := server.LoadMCPConfig(directory)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load mcp.yaml: %w", err)
+	}
+
+	manager := graph_manager.NewManager(log)
+	err = manager.SetNodeKinds(mcpConfig.NodeKinds())
+	if err == nil {
+		err = manager.SetAttributeDefinitions(mcpConfig.Attributes)
+	}
+	if err == nil {
+		err = manager.LoadRelationshipsFromDir(directory)
+	}
+	if err == nil {
+		err = manager.LoadFromStore(source)
+	}
+	if err == nil {
+		err = manager.MirrorTo(target)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(manager.ListAllNodes()), nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{exportCmd, importCmd} {
+		cmd.Flags().StringVarP(&convertDirectory, "directory", "d", ".", "directory where tasks are stored (git repository)")
+		cmd.Flags().StringVar(&convertDBPath, "db", "", "bolt database file (default <directory>/nodes.db)")
+		cmd.Flags().StringVar(&convertNodesDir, "nodes-dir", "", "YAML nodes directory (default <directory>/nodes)")
+		cmd.Flags().BoolVarP(&convertVerbose, "verbose", "v", false, "enable verbose logging")
+
+		rootCmd.AddCommand(cmd)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestConvertRoundTripsSymmetricEdges(t *testing.T) {
+	// The recipes example pairs chicken-parmesan and garlic-bread with each
+	// other through the symmetric pairs_with relationship
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(filepath.Join("..", "..", "docs", "examples", "recipes"))); err != nil {
+		t.Fatalf("Failed to copy example: %v", err)
+	}
+
+	log := logger.NewNop()
+	dbStore, err := store.NewBoltStore(filepath.Join(dir, "nodes.db"), log)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer dbStore.Close()
+
+	imported, err := convert(log, dir, store.NewDirStore(filepath.Join(dir, "nodes"), log), dbStore)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	exportDir := filepath.Join(dir, "exported")
+	exported, err := convert(log, dir, dbStore, store.NewDirStore(exportDir, log))
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if exported != imported {
+		t.Errorf("Exported %d nodes, imported %d", exported, imported)
+	}
+
+	nodes, err := store.NewDirStore(exportDir, log).LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to read exported nodes: %v", err)
+	}
+	if len(nodes) != imported {
+		t.Errorf("Expected %d exported node files, got %d", imported, len(nodes))
+	}
+	pairs := make(map[string][]string)
+	for _, node := range nodes {
+		pairs[node.ID] = node.EdgeIDs["pairs_with"]
+	}
+	if !slices.Contains(pairs["chicken-parmesan"], "garlic-bread") {
+		t.Errorf("Expected chicken-parmesan to pair with garlic-bread, got %v", pairs["chicken-parmesan"])
+	}
+	if !slices.Contains(pairs["garlic-bread"], "chicken-parmesan") {
+		t.Errorf("Expected garlic-bread to pair with chicken-parmesan, got %v", pairs["garlic-bread"])
+	}
+}
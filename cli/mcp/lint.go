@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"common-tasks-mcp/mcp/server"
+	"common-tasks-mcp/pkg/graph_manager"
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	// Lint command flags
+	lintDirectory string
+	lintDBPath    string
+	lintVerbose   bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check nodes for problems that don't stop them from loading",
+	Long: `Load the relationships and nodes and report problems that should be fixed in
+the data, such as an edge of a relationship with an inverse whose target has no
+matching edge back, or a node missing a field its kind in mcp.yaml requires or an attribute value
+mcp.yaml doesn't allow.
+
+Nodes are read from <directory>/nodes, or from a bolt database with --db.
+Exits with status 1 if any problems are found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log, err := logger.New(lintVerbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+			os.Exit(1)
+		}
+		defer log.Sync()
+
+		mcpConfig, err := server.LoadMCPConfig(lintDirectory)
+		if err != nil {
+			log.Error("Failed to load mcp.yaml", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var nodeStore store.Store = store.NewDirStore(filepath.Join(lintDirectory, "nodes"), log)
+		if lintDBPath != "" {
+			boltStore, err := store.NewBoltStore(lintDBPath, log)
+			if err != nil {
+				log.Error("Failed to open storage", zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error opening storage: %v\n", err)
+				os.Exit(1)
+			}
+			nodeStore = boltStore
+		}
+
+		manager := graph_manager.NewManager(log)
+		err = manager.SetNodeKinds(mcpConfig.NodeKinds())
+		if err == nil {
+			err = manager.SetAttributeDefinitions(mcpConfig.Attributes)
+		}
+		if err == nil {
+			err = manager.LoadRelationshipsFromDir(lintDirectory)
+		}
+		if err == nil {
+			err = manager.LoadFromStore(nodeStore)
+		}
+		nodeStore.Close()
+		if err != nil {
+			log.Error("Failed to load nodes", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		issues := manager.Lint()
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			fmt.Printf("\n%d problem(s) found in %d nodes\n", len(issues), len(manager.ListAllNodes()))
+			os.Exit(1)
+		}
+
+		fmt.Printf("No problems found in %d nodes\n", len(manager.ListAllNodes()))
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintDirectory, "directory", "d", ".", "directory where tasks are stored (git repository)")
+	lintCmd.Flags().StringVar(&lintDBPath, "db", "", "lint a bolt database instead of <directory>/nodes")
+	lintCmd.Flags().BoolVarP(&lintVerbose, "verbose", "v", false, "enable verbose logging")
+
+	rootCmd.AddCommand(lintCmd)
+}
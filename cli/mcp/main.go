@@ -200,11 +200,18 @@ Transport modes:
 			if err != nil {
 				log.Error("Server exited with error", zap.Error(err))
 				fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+				srv.Close()
 				os.Exit(1)
 			}
 			log.Info("Server exited normally")
 		}
 
+		// Write any changes still waiting for the write-behind interval
+		if err := srv.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write pending changes: %v\n", err)
+			os.Exit(1)
+		}
+
 		log.Info("Shutdown complete")
 	},
 }
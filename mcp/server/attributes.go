@@ -0,0 +1,77 @@
+package server
+
+import (
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// withAttributes returns a copy of a tool's properties with the attributes
+// argument added, or the properties unchanged when mcp.yaml declares no
+// attributes. With required set the schema lists the required attributes that
+// have no default; with nullable set an attribute may be null to remove it or
+// reset it to its default.
+func (s *Server) withAttributes(properties map[string]interface{}, description string, required, nullable bool) map[string]interface{} {
+	definitions := s.taskManager.GetAttributeDefinitions()
+	if len(definitions) == 0 {
+		return properties
+	}
+
+	attributeProperties := make(map[string]interface{}, len(definitions))
+	var requiredNames []string
+	for _, definition := range definitions {
+		attributeProperties[definition.Name] = attributeSchema(definition, nullable)
+		if definition.Required && definition.Default == nil {
+			requiredNames = append(requiredNames, definition.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           attributeProperties,
+		"additionalProperties": false,
+		"description":          description,
+	}
+	if required && len(requiredNames) > 0 {
+		schema["required"] = requiredNames
+	}
+
+	withAttributes := make(map[string]interface{}, len(properties)+1)
+	for name, property := range properties {
+		withAttributes[name] = property
+	}
+	withAttributes["attributes"] = schema
+	return withAttributes
+}
+
+// attributeSchema builds the JSON schema of an attribute's values
+func attributeSchema(definition types.AttributeDefinition, nullable bool) map[string]interface{} {
+	var schemaType interface{} = string(definition.Type)
+	if nullable {
+		schemaType = []string{string(definition.Type), "null"}
+	}
+	schema := map[string]interface{}{
+		"type": schemaType,
+	}
+	if definition.Description != "" {
+		schema["description"] = definition.Description
+	}
+	if len(definition.Enum) > 0 {
+		schema["enum"] = definition.Enum
+	}
+	if definition.Pattern != "" {
+		schema["pattern"] = definition.Pattern
+	}
+	if definition.Default != nil {
+		schema["default"] = definition.Default
+	}
+	return schema
+}
+
+// matchesAttributes reports whether the node has every attribute value in filter
+func matchesAttributes(node *types.Node, filter map[string]any) bool {
+	for name, value := range filter {
+		nodeValue, exists := node.Attributes[name]
+		if !exists || !types.AttributeValuesEqual(nodeValue, value) {
+			return false
+		}
+	}
+	return true
+}
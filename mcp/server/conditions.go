@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager"
+)
+
+// contextSchema builds the JSON schema of the context argument, the facts the
+// conditions of edges and nodes are evaluated against
+func contextSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"additionalProperties": map[string]interface{}{
+			"type":  []string{"string", "number", "boolean", "array"},
+			"items": map[string]interface{}{"type": []string{"string", "number", "boolean"}},
+		},
+		"description": fmt.Sprintf("Facts about the situation, such as {\"environment\": \"prod\", \"language\": \"go\", \"changed_paths\": [\"db/schema.sql\"]}. When given, only the edges whose conditions hold are followed, and those left out are listed with the reason. Edges to %s whose own condition doesn't hold are left out too.", naming.Plural),
+	}
+}
+
+// conditionSchema builds the JSON schema of the condition argument of the
+// write tools
+func conditionSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": fmt.Sprintf("When the %s applies, as a condition over the request context such as environment == \"prod\" && changed_paths contains \"db/\". Empty means always.", naming.Singular),
+	}
+}
+
+// formatExcludedEdgesAsMarkdown lists the edges left out in the request
+// context, or returns an empty string if there are none
+func formatExcludedEdgesAsMarkdown(excluded []graph_manager.ExcludedEdge) string {
+	if len(excluded) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**Left out in this context** (%d):\n\n", len(excluded)))
+	for _, edge := range excluded {
+		sb.WriteString(fmt.Sprintf("- `%s` %s `%s`: %s\n", edge.From, edge.Relationship, edge.To, edge.Reason))
+	}
+	return sb.String()
+}
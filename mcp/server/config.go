@@ -1,6 +1,10 @@
 package server
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Config holds the configuration for the MCP server
 type Config struct {
@@ -13,12 +17,31 @@ type Config struct {
 	// Directory where tasks are stored (git repository)
 	Directory string `env:"MCP_DIRECTORY" yaml:"directory" default:"."`
 
+	// Storage backend for nodes: "dir" (one YAML file per node), "bolt" (single
+	// embedded database file) or "memory" (not persisted)
+	Storage string `env:"MCP_STORAGE" yaml:"storage" default:"dir"`
+
+	// Location of the storage backend. For "dir" this defaults to the nodes
+	// directory inside Directory, for "bolt" to nodes.db inside Directory.
+	StoragePath string `env:"MCP_STORAGE_PATH" yaml:"storagePath" default:""`
+
 	// Verbose logging
 	Verbose bool `env:"MCP_VERBOSE" yaml:"verbose" default:"false"`
 
 	// Read-only mode (suppresses write tools)
 	ReadOnly bool `env:"MCP_READ_ONLY" yaml:"readOnly" default:"false"`
 
+	// Allow apply_changes operations to reference nodes added later in the same batch
+	AllowForwardReferences bool `env:"MCP_ALLOW_FORWARD_REFERENCES" yaml:"allowForwardReferences" default:"false"`
+
+	// How long to coalesce changes before writing them to disk, as a duration
+	// string (e.g. "2s"). "0s" writes every change immediately.
+	WriteBehindInterval string `env:"MCP_WRITE_BEHIND_INTERVAL" yaml:"writeBehindInterval" default:"0s"`
+
+	// Stop watching Directory for changes to nodes, relationships.yaml, prompts
+	// and mcp.yaml. By default the server reloads them when they change.
+	DisableHotReload bool `env:"MCP_DISABLE_HOT_RELOAD" yaml:"disableHotReload" default:"false"`
+
 	// MCP-specific configuration (loaded from mcp.yaml in Directory)
 	MCP MCPConfig
 }
@@ -35,5 +58,28 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if !isSupportedStorage(c.Storage) {
+		return fmt.Errorf("storage must be one of %s, got '%s'", strings.Join(supportedStorage, ", "), c.Storage)
+	}
+
+	if c.WriteBehindInterval != "" {
+		interval, err := time.ParseDuration(c.WriteBehindInterval)
+		if err != nil {
+			return fmt.Errorf("writeBehindInterval must be a duration such as '2s', got '%s'", c.WriteBehindInterval)
+		}
+		if interval < 0 {
+			return fmt.Errorf("writeBehindInterval cannot be negative, got '%s'", c.WriteBehindInterval)
+		}
+	}
+
 	return nil
 }
+
+// WriteBehindDuration returns the parsed write-behind interval, or zero if it is invalid
+func (c Config) WriteBehindDuration() time.Duration {
+	interval, err := time.ParseDuration(c.WriteBehindInterval)
+	if err != nil || interval < 0 {
+		return 0
+	}
+	return interval
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// edgesSchema builds the JSON schema of the edges argument from the registered
+// relationships: one array of target IDs per relationship, described by the
+// relationship's description. Other relationship names are not allowed. With
+// withAttributes set a target may also be an object with the ID and the
+// attributes of the edge.
+func (s *Server) edgesSchema(description string, withAttributes bool) map[string]interface{} {
+	naming := s.mcpConfig().Naming.Node
+	relationships := s.taskManager.GetAllRelationships()
+
+	var items interface{} = map[string]string{"type": "string"}
+	if withAttributes {
+		items = map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]string{"type": "string"},
+				edgeTargetSchema(naming),
+			},
+		}
+	}
+
+	properties := make(map[string]interface{}, len(relationships))
+	for name, rel := range relationships {
+		relDescription := rel.Description
+		if relDescription == "" {
+			relDescription = fmt.Sprintf("%s IDs related through %s", naming.DisplaySingular, name)
+		}
+		properties[name] = map[string]interface{}{
+			"type":        "array",
+			"items":       items,
+			"description": relDescription,
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+		"description":          description,
+	}
+}
+
+// edgeTargetSchema builds the JSON schema of an edge target given with the
+// attributes of the edge
+func edgeTargetSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Target %s ID", naming.Singular),
+			},
+			"note": map[string]interface{}{
+				"type":        "string",
+				"description": "Why the edge exists",
+			},
+			"condition": map[string]interface{}{
+				"type":        "string",
+				"description": "When the edge applies, e.g. changed_paths contains \"db/\"",
+			},
+			"weight": map[string]interface{}{
+				"type":        "number",
+				"description": "Weight or priority of the edge among the relationship's targets",
+			},
+		},
+		"required":             []string{"id"},
+		"additionalProperties": false,
+	}
+}
+
+// relationshipNameSchema builds the JSON schema of a relationship name argument,
+// restricted to the registered relationships
+func (s *Server) relationshipNameSchema() map[string]interface{} {
+	names := s.taskManager.GetRegisteredRelationshipNames()
+	sort.Strings(names)
+	return map[string]interface{}{
+		"type": "string",
+		"enum": names,
+	}
+}
+
+// validateEdges checks that every relationship used in edges is registered
+func (s *Server) validateEdges(edges map[string][]string) error {
+	names := make([]string, 0, len(edges))
+	for name := range edges {
+		names = append(names, name)
+	}
+	return s.validateRelationshipNames(names)
+}
+
+// validateRelationshipNames checks that every relationship name is registered
+func (s *Server) validateRelationshipNames(names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if !s.taskManager.IsRelationshipRegistered(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	known := s.taskManager.GetRegisteredRelationshipNames()
+	if len(known) == 0 {
+		return fmt.Errorf("unknown relationship(s) %s: no relationships are configured", strings.Join(unknown, ", "))
+	}
+	sort.Strings(known)
+	return fmt.Errorf("unknown relationship(s) %s: expected one of %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+// mergeEdgeAttributes combines the edge attributes given in several edges
+// arguments into one map, or nil if there are none
+func mergeEdgeAttributes(sets ...map[string]map[string]types.EdgeAttributes) map[string]map[string]types.EdgeAttributes {
+	var merged map[string]map[string]types.EdgeAttributes
+	for _, set := range sets {
+		for relationshipName, targets := range set {
+			if merged == nil {
+				merged = make(map[string]map[string]types.EdgeAttributes)
+			}
+			if merged[relationshipName] == nil {
+				merged[relationshipName] = make(map[string]types.EdgeAttributes)
+			}
+			for id, attributes := range targets {
+				merged[relationshipName][id] = attributes
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEdgesSchemaListsRegisteredRelationships(t *testing.T) {
+	s := newTestServer(t)
+	session, _ := connectTestClient(t, s)
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+
+	expected := map[string]string{
+		"prerequisites": "Tasks that must be completed before this node",
+		"related":       "Task IDs related through related",
+	}
+	checked := 0
+	for _, tool := range result.Tools {
+		if tool.Name != "add_task" && tool.Name != "update_task" {
+			continue
+		}
+		checked++
+
+		encoded, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			t.Fatalf("Failed to encode schema of %s: %v", tool.Name, err)
+		}
+		var schema struct {
+			Properties struct {
+				Edges struct {
+					Properties map[string]struct {
+						Description string `json:"description"`
+					} `json:"properties"`
+					AdditionalProperties bool `json:"additionalProperties"`
+				} `json:"edges"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(encoded, &schema); err != nil {
+			t.Fatalf("Failed to decode schema of %s: %v", tool.Name, err)
+		}
+
+		edges := schema.Properties.Edges
+		if len(edges.Properties) != len(expected) {
+			t.Errorf("%s: expected edges for %d relationships, got %v", tool.Name, len(expected), edges.Properties)
+		}
+		for name, description := range expected {
+			property, exists := edges.Properties[name]
+			if !exists {
+				t.Errorf("%s: expected edges for %s", tool.Name, name)
+			} else if property.Description != description {
+				t.Errorf("%s: expected %s described as %q, got %q", tool.Name, name, description, property.Description)
+			}
+		}
+		if edges.AdditionalProperties {
+			t.Errorf("%s: expected other relationships to be disallowed", tool.Name)
+		}
+	}
+	if checked != 2 {
+		t.Errorf("Expected add_task and update_task to be listed, checked %d", checked)
+	}
+}
+
+func TestValidateEdgesRejectsUnknownRelationships(t *testing.T) {
+	s := newTestServer(t)
+
+	if err := s.validateEdges(map[string][]string{"prerequisites": {"build"}, "related": nil}); err != nil {
+		t.Errorf("Expected registered relationships to be accepted, got %v", err)
+	}
+
+	err := s.validateEdges(map[string][]string{"prerequisites": {"build"}, "blocks": {"deploy"}})
+	if err == nil {
+		t.Fatal("Expected an unknown relationship to be rejected")
+	}
+	for _, want := range []string{"blocks", "expected one of prerequisites, related"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+
+	// The write tools refuse the edges without changing the graph
+	result := callTestTool(t, s.handleAddTask, map[string]any{
+		"id":    "deploy",
+		"name":  "Deploy",
+		"edges": map[string]any{"blocks": []string{"build"}},
+	})
+	if !result.IsError || !strings.Contains(resultText(result), "unknown relationship(s) blocks") {
+		t.Errorf("Expected add_task to reject the unknown relationship, got %s", resultText(result))
+	}
+	if _, err := s.taskManager.GetNode("deploy"); err == nil {
+		t.Error("Expected the node not to be added")
+	}
+}
@@ -16,15 +16,71 @@ func formatNodesAsMarkdown(nodes []*types.Node) string {
 
 	var sb strings.Builder
 	for _, node := range nodes {
-		sb.WriteString(fmt.Sprintf("%s - %s\n", node.ID, node.Summary))
+		if node.Kind != "" {
+			sb.WriteString(fmt.Sprintf("%s (%s)%s - %s\n", node.ID, node.Kind, formatStatusLabel(node), node.Summary))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s%s - %s\n", node.ID, formatStatusLabel(node), node.Summary))
+		}
 	}
 
 	return sb.String()
 }
 
-// formatNodeAsMarkdown formats a single node with full details as markdown
-func formatNodeAsMarkdown(node *types.Node, tm *graph_manager.Manager) string {
+// formatEdgeAttributes formats the attributes of an edge for display after its
+// target ID, e.g. " (weight 2, when `env == "prod"`): Needs the build output"
+func formatEdgeAttributes(attributes types.EdgeAttributes) string {
+	var details []string
+	if attributes.Weight != 0 {
+		details = append(details, "weight "+types.FormatAttributeValue(attributes.Weight))
+	}
+	if attributes.Condition != "" {
+		details = append(details, fmt.Sprintf("when `%s`", attributes.Condition))
+	}
+
+	var formatted string
+	if len(details) > 0 {
+		formatted = fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+	if attributes.Note != "" {
+		formatted += ": " + attributes.Note
+	}
+	return formatted
+}
+
+// includedEdgeIDs returns the targets of a relationship that aren't excluded
+func includedEdgeIDs(node *types.Node, relationshipName string, excluded map[string]bool) []string {
+	var ids []string
+	for _, id := range node.GetEdgeIDs(relationshipName) {
+		if !excluded[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// formatNodeAsMarkdown formats a single node with full details as markdown,
+// calling it by the naming in notices about it.
+// With facts, the edges whose conditions don't hold in that context are listed
+// separately with the reason instead of with the others.
+func formatNodeAsMarkdown(node *types.Node, naming NodeNaming, tm *graph_manager.Manager, facts types.Facts) string {
 	var sb strings.Builder
+	sb.WriteString(formatStatusNotice(node, naming))
+
+	// Edges left out in the request context, by relationship and target ID
+	excluded := make(map[string]map[string]bool)
+	var excludedEdges []graph_manager.ExcludedEdge
+	if facts != nil {
+		if holds, explanation := types.EvaluateCondition(node.Condition, facts); !holds {
+			sb.WriteString(fmt.Sprintf("_This %s does not apply in this context: it applies when `%s`, but %s._\n\n", naming.Singular, node.Condition, explanation))
+		}
+		excludedEdges, _ = tm.ExcludedEdges(node.ID, graph_manager.WithFacts(facts))
+		for _, edge := range excludedEdges {
+			if excluded[edge.Relationship] == nil {
+				excluded[edge.Relationship] = make(map[string]bool)
+			}
+			excluded[edge.Relationship][edge.To] = true
+		}
+	}
 
 	// Get all registered relationships from the manager
 	allRelationships := tm.GetAllRelationships()
@@ -56,15 +112,16 @@ func formatNodeAsMarkdown(node *types.Node, tm *graph_manager.Manager) string {
 	// Display backward relationships first (things that come before)
 	for _, relName := range backwardRels {
 		rel := allRelationships[relName]
-		edgeIDs := node.GetEdgeIDs(relName)
+		edgeIDs := includedEdgeIDs(node, relName, excluded[relName])
 		if len(edgeIDs) > 0 {
 			sb.WriteString(fmt.Sprintf("**%s:**\n\n", capitalizeFirst(rel.Description)))
 			for _, id := range edgeIDs {
 				relatedNode, err := tm.GetNode(id)
+				edgeDetails := formatEdgeAttributes(node.GetEdgeAttributes(relName, id))
 				if err == nil {
-					sb.WriteString(fmt.Sprintf("`%s`\n\n%s\n\n", id, relatedNode.Description))
+					sb.WriteString(fmt.Sprintf("`%s`%s%s\n\n%s\n\n", id, formatStatusLabel(relatedNode), edgeDetails, relatedNode.Description))
 				} else {
-					sb.WriteString(fmt.Sprintf("`%s` (not found)\n\n", id))
+					sb.WriteString(fmt.Sprintf("`%s` (not found)%s\n\n", id, edgeDetails))
 				}
 			}
 		}
@@ -76,15 +133,16 @@ func formatNodeAsMarkdown(node *types.Node, tm *graph_manager.Manager) string {
 	// Display forward relationships (things that come after)
 	for _, relName := range forwardRels {
 		rel := allRelationships[relName]
-		edgeIDs := node.GetEdgeIDs(relName)
+		edgeIDs := includedEdgeIDs(node, relName, excluded[relName])
 		if len(edgeIDs) > 0 {
 			sb.WriteString(fmt.Sprintf("**%s:**\n\n", capitalizeFirst(rel.Description)))
 			for _, id := range edgeIDs {
 				relatedNode, err := tm.GetNode(id)
+				edgeDetails := formatEdgeAttributes(node.GetEdgeAttributes(relName, id))
 				if err == nil {
-					sb.WriteString(fmt.Sprintf("`%s`\n\n%s\n\n", id, relatedNode.Description))
+					sb.WriteString(fmt.Sprintf("`%s`%s%s\n\n%s\n\n", id, formatStatusLabel(relatedNode), edgeDetails, relatedNode.Description))
 				} else {
-					sb.WriteString(fmt.Sprintf("`%s` (not found)\n\n", id))
+					sb.WriteString(fmt.Sprintf("`%s` (not found)%s\n\n", id, edgeDetails))
 				}
 			}
 		}
@@ -92,7 +150,7 @@ func formatNodeAsMarkdown(node *types.Node, tm *graph_manager.Manager) string {
 
 	// Display relationships with no temporal direction
 	for _, relName := range noneRels {
-		edgeIDs := node.GetEdgeIDs(relName)
+		edgeIDs := includedEdgeIDs(node, relName, excluded[relName])
 		if len(edgeIDs) > 0 {
 			// Try to get description from registered relationship, otherwise use name
 			label := relName
@@ -102,15 +160,197 @@ func formatNodeAsMarkdown(node *types.Node, tm *graph_manager.Manager) string {
 			sb.WriteString(fmt.Sprintf("**%s:**\n\n", capitalizeFirst(label)))
 			for _, id := range edgeIDs {
 				relatedNode, err := tm.GetNode(id)
+				edgeDetails := formatEdgeAttributes(node.GetEdgeAttributes(relName, id))
 				if err == nil {
-					sb.WriteString(fmt.Sprintf("`%s`\n\n%s\n\n", id, relatedNode.Description))
+					sb.WriteString(fmt.Sprintf("`%s`%s%s\n\n%s\n\n", id, formatStatusLabel(relatedNode), edgeDetails, relatedNode.Description))
 				} else {
-					sb.WriteString(fmt.Sprintf("`%s` (not found)\n\n", id))
+					sb.WriteString(fmt.Sprintf("`%s` (not found)%s\n\n", id, edgeDetails))
 				}
 			}
 		}
 	}
 
+	if len(excludedEdges) > 0 {
+		sb.WriteString("**Left out in this context:**\n\n")
+		for _, edge := range excludedEdges {
+			sb.WriteString(fmt.Sprintf("- %s `%s`: %s\n", edge.Relationship, edge.To, edge.Reason))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Display the nodes whose edges point at this node
+	if dependents, err := tm.GetDependents(node.ID); err == nil {
+		relNames := make([]string, 0, len(dependents))
+		for relName := range dependents {
+			relNames = append(relNames, relName)
+		}
+		sort.Strings(relNames)
+		for _, relName := range relNames {
+			sb.WriteString(fmt.Sprintf("**%s:**\n\n", dependentsLabel(relName, allRelationships)))
+			for _, dependent := range dependents[relName] {
+				sb.WriteString(fmt.Sprintf("`%s` - %s\n", dependent.ID, dependent.Summary))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if node.Kind != "" {
+		sb.WriteString(fmt.Sprintf("Kind: `%s`\n", node.Kind))
+	}
+	if node.Status != "" {
+		sb.WriteString(fmt.Sprintf("Status: `%s`\n", node.Status))
+	}
+	if node.SupersededBy != "" {
+		sb.WriteString(fmt.Sprintf("Superseded by: `%s`\n", node.SupersededBy))
+	}
+	if node.Condition != "" {
+		sb.WriteString(fmt.Sprintf("Applies when: `%s`\n", node.Condition))
+	}
+	if len(node.Attributes) > 0 {
+		names := make([]string, 0, len(node.Attributes))
+		for name := range node.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("**Attributes:**\n\n")
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", name, types.FormatAttributeValue(node.Attributes[name])))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Revision to pass as expectedRevision when changing the node
+	sb.WriteString(fmt.Sprintf("Revision: `%s`\n", node.Revision()))
+
+	return strings.TrimSpace(sb.String())
+}
+
+// formatRelatedNodesAsMarkdown formats the nodes transitively related to a node,
+// one section per relationship, as markdown
+func formatRelatedNodesAsMarkdown(id, direction string, relationships []string, related map[string][]graph_manager.TraversalResult, excluded []graph_manager.ExcludedEdge) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Related nodes of `%s` (%s)\n\n", id, direction))
+
+	for _, relationshipName := range relationships {
+		results := related[relationshipName]
+		sb.WriteString(fmt.Sprintf("**%s** (%d):\n\n", relationshipName, len(results)))
+		if len(results) == 0 {
+			sb.WriteString("None\n\n")
+			continue
+		}
+		for _, result := range results {
+			sb.WriteString(fmt.Sprintf("- depth %d: `%s` - %s\n", result.Depth, result.Node.ID, result.Node.Summary))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(formatExcludedEdgesAsMarkdown(excluded))
+
+	return strings.TrimSpace(sb.String())
+}
+
+// formatExecutionPlanAsMarkdown formats an execution plan as a numbered
+// checklist, one section per stage of steps that can run in parallel
+func formatExecutionPlanAsMarkdown(plan *graph_manager.ExecutionPlan) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Execution plan for `%s` (%d steps)\n\n", plan.TargetID, len(plan.Steps())))
+
+	step := 1
+	for i, level := range plan.Levels {
+		if len(level) > 1 {
+			sb.WriteString(fmt.Sprintf("**Stage %d** (steps can be done in parallel):\n\n", i+1))
+		} else {
+			sb.WriteString(fmt.Sprintf("**Stage %d:**\n\n", i+1))
+		}
+		for _, node := range level {
+			line := fmt.Sprintf("%d. [ ] `%s` - %s", step, node.ID, node.Summary)
+			if node.ID == plan.TargetID {
+				line += " **(target)**"
+			}
+			sb.WriteString(line + "\n")
+			step++
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(formatExcludedEdgesAsMarkdown(plan.Excluded))
+
+	return strings.TrimSpace(sb.String())
+}
+
+// dependentsLabel describes the nodes pointing at a node through a relationship,
+// from the point of view of the node they point at
+func dependentsLabel(relName string, allRelationships map[string]types.Relationship) string {
+	direction := types.DirectionNone
+	if rel, exists := allRelationships[relName]; exists {
+		direction = rel.Direction
+	}
+
+	switch direction {
+	case types.DirectionBackward:
+		return fmt.Sprintf("Required by (%s)", relName)
+	case types.DirectionForward:
+		return fmt.Sprintf("Required after (%s)", relName)
+	default:
+		return fmt.Sprintf("Referenced by (%s)", relName)
+	}
+}
+
+// formatDependentsAsMarkdown formats the nodes pointing at a node, one section
+// per relationship, as markdown
+func formatDependentsAsMarkdown(id string, relationships []string, dependents map[string][]*types.Node, allRelationships map[string]types.Relationship) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Dependents of `%s`\n\n", id))
+
+	for _, relName := range relationships {
+		nodes := dependents[relName]
+		sb.WriteString(fmt.Sprintf("**%s** (%d):\n\n", dependentsLabel(relName, allRelationships), len(nodes)))
+		if len(nodes) == 0 {
+			sb.WriteString("None\n\n")
+			continue
+		}
+		for _, node := range nodes {
+			sb.WriteString(fmt.Sprintf("- `%s` - %s\n", node.ID, node.Summary))
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// formatChangeImpactAsMarkdown formats the impact of a previewed change as markdown
+func formatChangeImpactAsMarkdown(impact *graph_manager.ChangeImpact) string {
+	var sb strings.Builder
+	verb := "Updating"
+	if impact.Operation == graph_manager.OperationDelete {
+		verb = "Deleting"
+	}
+	sb.WriteString(fmt.Sprintf("Dry run: %s `%s` would make the following changes. Nothing was changed.\n\n", verb, impact.ID))
+
+	writeEdges := func(title string, edges []graph_manager.ImpactedEdge) {
+		sb.WriteString(fmt.Sprintf("**%s** (%d):\n\n", title, len(edges)))
+		if len(edges) == 0 {
+			sb.WriteString("None\n\n")
+			return
+		}
+		for _, edge := range edges {
+			sb.WriteString(fmt.Sprintf("- `%s` %s → `%s`\n", edge.From, edge.Relationship, edge.To))
+		}
+		sb.WriteString("\n")
+	}
+	writeEdges("Edges removed", impact.RemovedEdges)
+	writeEdges("Edges added", impact.AddedEdges)
+
+	if len(impact.AffectedNodes) > 0 {
+		sb.WriteString(fmt.Sprintf("**Other nodes affected:** `%s`\n\n", strings.Join(impact.AffectedNodes, "`, `")))
+	}
+
+	sb.WriteString(fmt.Sprintf("**Workflows losing steps** (%d):\n\n", len(impact.LostSteps)))
+	if len(impact.LostSteps) == 0 {
+		sb.WriteString("None\n")
+	}
+	for _, lost := range impact.LostSteps {
+		sb.WriteString(fmt.Sprintf("- `%s` would no longer reach through %s: `%s`\n", lost.NodeID, lost.Relationship, strings.Join(lost.Lost, "`, `")))
+	}
+
 	return strings.TrimSpace(sb.String())
 }
 
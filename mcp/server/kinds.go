@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerKindTools registers the list, get and add tools of a node kind
+// declared in mcp.yaml. They call the generic tools with the kind set.
+func (s *Server) registerKindTools(kind KindConfig) {
+	naming := kind.Naming
+
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("list_%s", naming.Plural),
+		Description: fmt.Sprintf("Browse the %s, optionally filtered by tags and attributes. Returns their IDs and summaries. If you provide multiple tags, you'll get %s that match any of them. Only active %s are listed unless you filter by status.", naming.Plural, naming.Plural, naming.Plural),
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": s.listProperties(naming),
+		},
+	}, forKind(kind.Name, s.handleListTasks))
+
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("get_%s", naming.Singular),
+		Description: fmt.Sprintf("Get a %s by its ID, with its full description and the nodes it is related to. Pass a context to leave out the edges whose conditions don't hold. A superseded %s redirects to the %s replacing it.", naming.Singular, naming.Singular, naming.Singular),
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": fmt.Sprintf("%s ID", naming.DisplaySingular),
+				},
+				"context":  contextSchema(naming),
+				"redirect": redirectSchema(naming),
+			},
+			"required": []string{"id"},
+		},
+	}, forKind(kind.Name, s.handleGetTask))
+
+	if s.config.ReadOnly {
+		return
+	}
+
+	required := []string{"id", "name"}
+	for _, field := range kind.RequiredFields {
+		if field != "name" {
+			required = append(required, field)
+		}
+	}
+	description := fmt.Sprintf("Create a new %s, linking it to other nodes through edges.", naming.Singular)
+	if len(kind.Relationships) > 0 {
+		description += fmt.Sprintf(" %s may only have edges of these relationships: %v.", naming.DisplayPlural, kind.Relationships)
+	}
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("add_%s", naming.Singular),
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": s.addProperties(naming),
+			"required":   required,
+		},
+	}, forKind(kind.Name, s.handleAddTask))
+}
+
+// forKind returns a handler for a tool of a node kind, which calls handler with
+// the kind argument set
+func forKind(kind string, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := make(map[string]json.RawMessage)
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("failed to parse arguments: %v", err),
+						},
+					},
+				}, nil
+			}
+		}
+		args["kind"], _ = json.Marshal(kind)
+		return callWithArguments(ctx, req, handler, args)
+	}
+}
+
+// withKind returns a copy of a tool's properties with the kind argument added,
+// or the properties unchanged when mcp.yaml declares no node kinds
+func (s *Server) withKind(properties map[string]interface{}, description string) map[string]interface{} {
+	kinds := s.mcpConfig().Kinds
+	if len(kinds) == 0 {
+		return properties
+	}
+
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = kind.Name
+	}
+	withKind := make(map[string]interface{}, len(properties)+1)
+	for name, property := range properties {
+		withKind[name] = property
+	}
+	withKind["kind"] = map[string]interface{}{
+		"type":        "string",
+		"enum":        names,
+		"description": description,
+	}
+	return withKind
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+
+	"common-tasks-mcp/pkg/graph_manager"
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// statusSchema builds the JSON schema of the status argument of the write tools
+func statusSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        types.NodeStatuses,
+		"description": fmt.Sprintf("Lifecycle status of the %s: draft while it is being written, active once in use (the default), deprecated when it should no longer be used, or superseded when supersededBy replaces it", naming.Singular),
+	}
+}
+
+// lifecycleProperties returns the status, supersededBy and
+// allowDeprecatedTargets properties of the write tools
+func lifecycleProperties(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"status": statusSchema(naming),
+		"supersededBy": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("ID of the %s replacing this one, which requires status superseded", naming.Singular),
+		},
+		"allowDeprecatedTargets": map[string]interface{}{
+			"type":        "boolean",
+			"description": fmt.Sprintf("Allow new edges to deprecated or superseded %s, which are otherwise refused (default false)", naming.Plural),
+		},
+	}
+}
+
+// withLifecycle returns a copy of a write tool's properties with the lifecycle
+// properties added
+func withLifecycle(properties map[string]interface{}, naming NodeNaming) map[string]interface{} {
+	withLifecycle := make(map[string]interface{}, len(properties)+3)
+	for name, property := range properties {
+		withLifecycle[name] = property
+	}
+	for name, property := range lifecycleProperties(naming) {
+		withLifecycle[name] = property
+	}
+	return withLifecycle
+}
+
+// listStatusSchema builds the JSON schema of the status filter of the list tools
+func listStatusSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string", "enum": types.NodeStatuses},
+		"description": fmt.Sprintf("Only list %s with one of these lifecycle statuses (default [\"active\"], which hides drafts and deprecated or superseded %s)", naming.Plural, naming.Plural),
+	}
+}
+
+// matchesStatus reports whether a node has one of the statuses, treating nodes
+// without a status as active. No statuses means only active nodes.
+func matchesStatus(node *types.Node, statuses []types.NodeStatus) bool {
+	if len(statuses) == 0 {
+		statuses = []types.NodeStatus{types.StatusActive}
+	}
+	for _, status := range statuses {
+		if node.Status.OrActive() == status.OrActive() {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedTargetsOption returns the write options allowing edges to
+// deprecated nodes if allow is set
+func deprecatedTargetsOption(allow bool) []graph_manager.WriteOption {
+	if !allow {
+		return nil
+	}
+	return []graph_manager.WriteOption{graph_manager.AllowDeprecatedTargets()}
+}
+
+// formatStatusLabel labels a node that isn't active for display after its ID,
+// e.g. " [superseded by `build-v2`]"
+func formatStatusLabel(node *types.Node) string {
+	switch {
+	case node.Status == types.StatusSuperseded && node.SupersededBy != "":
+		return fmt.Sprintf(" [superseded by `%s`]", node.SupersededBy)
+	case node.Status.OrActive() != types.StatusActive:
+		return fmt.Sprintf(" [%s]", node.Status)
+	}
+	return ""
+}
+
+// formatStatusNotice warns about a node that isn't active, or returns an empty
+// string for active nodes
+func formatStatusNotice(node *types.Node, naming NodeNaming) string {
+	switch node.Status {
+	case types.StatusDraft:
+		return fmt.Sprintf("_This %s is a draft and may still change._\n\n", naming.Singular)
+	case types.StatusDeprecated:
+		return fmt.Sprintf("_This %s is deprecated and should no longer be used._\n\n", naming.Singular)
+	case types.StatusSuperseded:
+		return fmt.Sprintf("_This %s is superseded by `%s`, which should be used instead._\n\n", naming.Singular, node.SupersededBy)
+	}
+	return ""
+}
+
+// redirectSchema builds the JSON schema of the redirect argument of the get tools
+func redirectSchema(naming NodeNaming) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "boolean",
+		"description": fmt.Sprintf("Show the %s replacing a superseded %s instead of the %s itself (default true)", naming.Singular, naming.Singular, naming.Singular),
+	}
+}
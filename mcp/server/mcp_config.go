@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,6 +15,10 @@ import (
 type MCPConfig struct {
 	Server ServerMetadata `yaml:"server"`
 	Naming NamingConfig   `yaml:"naming"`
+	Kinds  []KindConfig   `yaml:"kinds"`
+
+	// Attributes declares the typed attributes nodes may carry
+	Attributes []types.AttributeDefinition `yaml:"attributes"`
 }
 
 // ServerMetadata contains the MCP server identification and description
@@ -34,6 +41,33 @@ type NodeNaming struct {
 	DisplayPlural   string `yaml:"display_plural"`
 }
 
+// KindConfig declares a kind of node with its own schema and the naming of its
+// list, get and add tools
+type KindConfig struct {
+	types.NodeKind `yaml:",inline"`
+	Naming         NodeNaming `yaml:"naming"`
+}
+
+// NodeKinds returns the node kinds declared in the configuration
+func (c MCPConfig) NodeKinds() []types.NodeKind {
+	kinds := make([]types.NodeKind, len(c.Kinds))
+	for i, kind := range c.Kinds {
+		kinds[i] = kind.NodeKind
+	}
+	return kinds
+}
+
+// kindNaming returns what to call nodes of the given kind: the naming of the
+// kind if mcp.yaml declares it, or the naming of all nodes
+func (c MCPConfig) kindNaming(kind string) NodeNaming {
+	for _, declared := range c.Kinds {
+		if declared.Name == kind {
+			return declared.Naming
+		}
+	}
+	return c.Naming.Node
+}
+
 // DefaultMCPConfig returns the default configuration
 func DefaultMCPConfig() MCPConfig {
 	return MCPConfig{
@@ -101,5 +135,49 @@ func LoadMCPConfig(directory string) (MCPConfig, error) {
 		config.Naming.Node.DisplayPlural = "Tasks"
 	}
 
+	// Kinds are named after themselves unless told otherwise, and their tools
+	// may not clash with the generic tools or with each other
+	toolNames := map[string]bool{config.Naming.Node.Singular: true, config.Naming.Node.Plural: true}
+	kindNames := make(map[string]bool, len(config.Kinds))
+	for i := range config.Kinds {
+		kind := &config.Kinds[i]
+		if err := kind.Validate(); err != nil {
+			return MCPConfig{}, fmt.Errorf("invalid kind %s in mcp.yaml: %w", kind.Name, err)
+		}
+		if kindNames[kind.Name] {
+			return MCPConfig{}, fmt.Errorf("kind %s is declared more than once in mcp.yaml", kind.Name)
+		}
+		kindNames[kind.Name] = true
+		if kind.Naming.Singular == "" {
+			kind.Naming.Singular = kind.Name
+		}
+		if kind.Naming.Plural == "" {
+			kind.Naming.Plural = kind.Naming.Singular + "s"
+		}
+		if kind.Naming.DisplaySingular == "" {
+			kind.Naming.DisplaySingular = capitalizeFirst(strings.ReplaceAll(kind.Naming.Singular, "_", " "))
+		}
+		if kind.Naming.DisplayPlural == "" {
+			kind.Naming.DisplayPlural = capitalizeFirst(strings.ReplaceAll(kind.Naming.Plural, "_", " "))
+		}
+		for _, name := range []string{kind.Naming.Singular, kind.Naming.Plural} {
+			if toolNames[name] {
+				return MCPConfig{}, fmt.Errorf("kind %s in mcp.yaml: the name %s is already used by another kind or the node naming", kind.Name, name)
+			}
+			toolNames[name] = true
+		}
+	}
+
+	attributeNames := make(map[string]bool, len(config.Attributes))
+	for _, attribute := range config.Attributes {
+		if err := attribute.Validate(); err != nil {
+			return MCPConfig{}, fmt.Errorf("invalid attribute %s in mcp.yaml: %w", attribute.Name, err)
+		}
+		if attributeNames[attribute.Name] {
+			return MCPConfig{}, fmt.Errorf("attribute %s is declared more than once in mcp.yaml", attribute.Name)
+		}
+		attributeNames[attribute.Name] = true
+	}
+
 	return config, nil
 }
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager"
+	"common-tasks-mcp/pkg/graph_manager/store"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay is how long changes must settle before the server reloads, so a
+// git pull touching many files causes a single reload
+const reloadDelay = 250 * time.Millisecond
+
+// pendingReload records what needs reloading once changes settle
+type pendingReload struct {
+	graph   bool // nodes or relationships.yaml
+	mcp     bool // mcp.yaml
+	prompts bool // prompts/
+}
+
+// startHotReload watches the data directory and reloads nodes, relationships,
+// prompts and mcp.yaml when they change, until ctx is cancelled. Failing to set
+// up the watches is logged and the server keeps running without hot reload.
+func (s *Server) startHotReload(ctx context.Context) {
+	if s.config.DisableHotReload {
+		s.logger.Info("Hot reload disabled")
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		s.logger.Warn("Hot reload unavailable: failed to create file watcher", zap.Error(err))
+		return
+	}
+	if err := watcher.Add(s.config.Directory); err != nil {
+		s.logger.Warn("Hot reload unavailable: failed to watch directory",
+			zap.String("directory", s.config.Directory),
+			zap.Error(err),
+		)
+		watcher.Close()
+		return
+	}
+	promptsDir := filepath.Join(s.config.Directory, "prompts")
+	if _, err := os.Stat(promptsDir); err == nil {
+		if err := watcher.Add(promptsDir); err != nil {
+			s.logger.Warn("Failed to watch prompts directory", zap.String("path", promptsDir), zap.Error(err))
+		}
+	}
+
+	// Only a nodes directory can be changed by someone else while the server
+	// runs: the bolt database is locked by the server and memory storage is
+	// private to it. The directory leaves out the files the server writes, so
+	// tool calls don't trigger reloads.
+	var nodeEvents <-chan store.Event
+	if dirStore, ok := s.taskManager.Store().(*store.DirStore); ok {
+		nodeEvents, err = dirStore.Watch(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to watch nodes directory", zap.String("path", dirStore.Dir()), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Hot reload enabled", zap.String("directory", s.config.Directory))
+
+	go s.watchForChanges(ctx, watcher, nodeEvents)
+}
+
+// watchForChanges collects change events and reloads once they settle
+func (s *Server) watchForChanges(ctx context.Context, watcher *fsnotify.Watcher, nodeEvents <-chan store.Event) {
+	defer watcher.Close()
+
+	promptsDir := filepath.Join(s.config.Directory, "prompts")
+	settle := time.NewTimer(reloadDelay)
+	settle.Stop()
+	var pending pendingReload
+
+	for {
+		select {
+		case <-ctx.Done():
+			settle.Stop()
+			return
+
+		case event, ok := <-nodeEvents:
+			if !ok {
+				nodeEvents = nil
+				continue
+			}
+			s.logger.Debug("Node changed", zap.String("node_id", event.ID), zap.String("type", string(event.Type)))
+			pending.graph = true
+			settle.Reset(reloadDelay)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			switch {
+			case event.Name == filepath.Join(s.config.Directory, "relationships.yaml"):
+				pending.graph = true
+			case event.Name == filepath.Join(s.config.Directory, "mcp.yaml"):
+				pending.mcp = true
+			case event.Name == promptsDir:
+				// The prompts directory was created or removed
+				if event.Has(fsnotify.Create) {
+					if err := watcher.Add(promptsDir); err != nil {
+						s.logger.Warn("Failed to watch prompts directory", zap.String("path", promptsDir), zap.Error(err))
+					}
+				}
+				pending.prompts = true
+			case filepath.Dir(event.Name) == promptsDir:
+				pending.prompts = true
+			default:
+				continue
+			}
+			s.logger.Debug("File changed", zap.String("path", event.Name), zap.String("op", event.Op.String()))
+			settle.Reset(reloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warn("File watcher error", zap.Error(err))
+
+		case <-settle.C:
+			pending = s.reload(pending)
+			if pending.graph {
+				settle.Reset(reloadDelay)
+			}
+		}
+	}
+}
+
+// reload applies the pending reloads and returns those that need to be retried.
+// Reloads that fail are logged and leave the server serving what it had before.
+func (s *Server) reload(pending pendingReload) pendingReload {
+	var retry pendingReload
+	toolsChanged := false
+	if pending.graph {
+		// The edges arguments of the write tools are generated from the relationships
+		relationships := s.taskManager.GetAllRelationships()
+		err := s.reloadGraph()
+		toolsChanged = !reflect.DeepEqual(relationships, s.taskManager.GetAllRelationships())
+		switch {
+		case errors.Is(err, graph_manager.ErrPendingChanges):
+			// A tool call changed the graph while it was being reloaded
+			s.logger.Info("Reload postponed until pending changes are written")
+			retry.graph = true
+		case err != nil:
+			s.logger.Error("Rejected reload of nodes and relationships", zap.Error(err))
+		}
+	}
+
+	if pending.mcp {
+		changed, err := s.reloadMCPConfig()
+		if err != nil {
+			s.logger.Error("Rejected reload of mcp.yaml", zap.Error(err))
+		}
+		toolsChanged = toolsChanged || changed
+	}
+	if pending.prompts {
+		hadPrompts := len(s.currentPrompts()) > 0
+		s.reloadPrompts()
+		// The prompt tools are only registered while there are prompts
+		toolsChanged = toolsChanged || hadPrompts != (len(s.currentPrompts()) > 0)
+	}
+
+	if toolsChanged {
+		s.refreshTools()
+	}
+
+	return retry
+}
+
+// reloadGraph reloads the nodes and relationships from disk
+func (s *Server) reloadGraph() error {
+	// Write our own pending changes first so the reload doesn't discard them
+	if err := s.persister.Flush(); err != nil {
+		return fmt.Errorf("failed to write pending changes: %w", err)
+	}
+
+	changed, err := s.taskManager.Reload(func(fresh *graph_manager.Manager) error {
+		return fresh.LoadRelationshipsFromDir(s.config.Directory)
+	})
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		s.logger.Info("Reloaded nodes and relationships",
+			zap.Int("count", len(s.taskManager.ListAllNodes())),
+		)
+	}
+	return nil
+}
+
+// reloadMCPConfig reloads mcp.yaml and reports whether the tool naming, the
+// node kinds or the attributes changed
+func (s *Server) reloadMCPConfig() (bool, error) {
+	mcpConfig, err := LoadMCPConfig(s.config.Directory)
+	if err != nil {
+		return false, err
+	}
+
+	previous := s.mcpConfig()
+	if reflect.DeepEqual(previous, mcpConfig) {
+		return false, nil
+	}
+	if previous.Server != mcpConfig.Server {
+		s.logger.Warn("Server name and instructions in mcp.yaml only take effect after a restart")
+	}
+
+	kindsChanged := !reflect.DeepEqual(previous.Kinds, mcpConfig.Kinds)
+	attributesChanged := !reflect.DeepEqual(previous.Attributes, mcpConfig.Attributes)
+	if kindsChanged {
+		if err := s.taskManager.SetNodeKinds(mcpConfig.NodeKinds()); err != nil {
+			return false, err
+		}
+	}
+	if attributesChanged {
+		if err := s.taskManager.SetAttributeDefinitions(mcpConfig.Attributes); err != nil {
+			return false, err
+		}
+	}
+	if kindsChanged || attributesChanged {
+		for _, issue := range s.taskManager.Lint() {
+			s.logger.Warn("Node data problem (run 'mcp lint' to list all)", zap.String("node_id", issue.NodeID), zap.String("problem", issue.Message))
+		}
+	}
+
+	s.mu.Lock()
+	s.config.MCP = mcpConfig
+	s.mu.Unlock()
+	s.logger.Info("Reloaded mcp.yaml")
+
+	return previous.Naming != mcpConfig.Naming || kindsChanged || attributesChanged, nil
+}
+
+// reloadPrompts reloads the prompts directory, registering new and changed
+// prompts and removing deleted ones. The MCP server notifies clients of the change.
+func (s *Server) reloadPrompts() {
+	promptsPath := filepath.Join(s.config.Directory, "prompts")
+	prompts, err := s.loadPrompts(promptsPath)
+	if err != nil {
+		s.logger.Debug("Prompts not loaded", zap.String("directory", promptsPath), zap.Error(err))
+		prompts = make(map[string]*PromptInfo)
+	}
+
+	s.mu.Lock()
+	previous := s.prompts
+	s.prompts = prompts
+	s.mu.Unlock()
+
+	var removed []string
+	for name := range previous {
+		if _, exists := prompts[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.mcp.RemovePrompts(removed...)
+	}
+
+	updated := 0
+	for name, info := range prompts {
+		if old, exists := previous[name]; exists && *old == *info {
+			continue
+		}
+		s.registerPrompt(name, info)
+		updated++
+	}
+
+	if len(removed) > 0 || updated > 0 {
+		s.logger.Info("Reloaded prompts",
+			zap.Int("count", len(prompts)),
+			zap.Int("updated", updated),
+			zap.Int("removed", len(removed)),
+		)
+	}
+}
+
+// refreshTools re-registers the tools after their names or descriptions changed
+// and removes tools that no longer exist. The MCP server notifies clients of the change.
+func (s *Server) refreshTools() {
+	s.mu.Lock()
+	previous := s.toolNames
+	s.toolNames = nil
+	s.mu.Unlock()
+	s.registerTools()
+
+	toolNames := s.registeredToolNames()
+	current := make(map[string]bool, len(toolNames))
+	for _, name := range toolNames {
+		current[name] = true
+	}
+	var stale []string
+	for _, name := range previous {
+		if !current[name] {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		s.mcp.RemoveTools(stale...)
+	}
+
+	s.logger.Info("Re-registered tools",
+		zap.Int("count", len(toolNames)),
+		zap.Strings("removed", stale),
+	)
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const testRelationships = `relationships:
+  - name: prerequisites
+    description: Tasks that must be completed before this node
+    direction: backward
+`
+
+// newReloadTestServer creates a server on a data directory holding the
+// relationships and a nodes directory, logging to the returned observer
+func newReloadTestServer(t *testing.T) (*Server, *observer.ObservedLogs) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "relationships.yaml"), testRelationships)
+	if err := os.Mkdir(filepath.Join(dir, "nodes"), 0755); err != nil {
+		t.Fatalf("Failed to create nodes directory: %v", err)
+	}
+
+	core, logs := observer.New(zap.DebugLevel)
+	s, err := New(Config{Directory: dir, Storage: "dir"}, zap.New(core))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, logs
+}
+
+// connectTestClient connects a client to the server, counting the tool and
+// prompt list_changed notifications it receives
+func connectTestClient(t *testing.T, s *Server) (*mcp.ClientSession, chan string) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	notifications := make(chan string, 16)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	go s.mcp.Run(ctx, serverTransport)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.1.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			notifications <- "tools"
+		},
+		PromptListChangedHandler: func(context.Context, *mcp.PromptListChangedRequest) {
+			notifications <- "prompts"
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session, notifications
+}
+
+// callTestTool calls a tool handler with the given arguments, failing the test
+// if the handler returns an error rather than an error result
+func callTestTool(t *testing.T, handler mcp.ToolHandler, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("Failed to encode arguments: %v", err)
+	}
+	result, err := handler(context.Background(), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Arguments: arguments},
+	})
+	if err != nil {
+		t.Fatalf("Tool handler failed: %v", err)
+	}
+	return result
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	return text.String()
+}
+
+// writeTestFile writes a file, failing the test on error
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// waitForNotification waits until the client receives the named notification
+func waitForNotification(t *testing.T, notifications chan string, expected string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case notification := <-notifications:
+			if notification == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s list_changed", expected)
+		}
+	}
+}
+
+// listToolNames returns the names of the tools the client sees
+func listToolNames(t *testing.T, session *mcp.ClientSession) map[string]bool {
+	t.Helper()
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	names := make(map[string]bool, len(result.Tools))
+	for _, tool := range result.Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func TestReloadGraph(t *testing.T) {
+	s, _ := newReloadTestServer(t)
+	nodesDir := filepath.Join(s.config.Directory, "nodes")
+
+	writeTestFile(t, filepath.Join(nodesDir, "build.yaml"), "id: build\nname: Build\n")
+	if retry := s.reload(pendingReload{graph: true}); retry != (pendingReload{}) {
+		t.Errorf("Expected nothing to retry, got %+v", retry)
+	}
+	if _, err := s.taskManager.GetNode("build"); err != nil {
+		t.Fatalf("Expected the new node to be loaded: %v", err)
+	}
+
+	// A graph with a dangling reference is rejected and not retried
+	writeTestFile(t, filepath.Join(nodesDir, "deploy.yaml"), "id: deploy\nname: Deploy\nedges:\n  prerequisites: [missing]\n")
+	if retry := s.reload(pendingReload{graph: true}); retry != (pendingReload{}) {
+		t.Errorf("Expected a rejected reload not to be retried, got %+v", retry)
+	}
+	if _, err := s.taskManager.GetNode("deploy"); err == nil {
+		t.Error("Expected the rejected graph not to be loaded")
+	}
+	if _, err := s.taskManager.GetNode("build"); err != nil {
+		t.Errorf("Expected the previous graph to be kept: %v", err)
+	}
+}
+
+// mutatingStore runs a function the next time the graph is loaded from it
+type mutatingStore struct {
+	store.Store
+	onLoad func()
+}
+
+func (s *mutatingStore) LoadAll() ([]*types.Node, error) {
+	if onLoad := s.onLoad; onLoad != nil {
+		s.onLoad = nil
+		onLoad()
+	}
+	return s.Store.LoadAll()
+}
+
+func TestReloadRetriesAfterPendingChanges(t *testing.T) {
+	s, _ := newReloadTestServer(t)
+
+	mutating := &mutatingStore{Store: s.taskManager.Store()}
+	if err := s.taskManager.LoadFromStore(mutating); err != nil {
+		t.Fatalf("Failed to load from store: %v", err)
+	}
+
+	// A tool call changes the graph while it is being reloaded
+	mutating.onLoad = func() {
+		if err := s.taskManager.AddNode(&types.Node{ID: "build", Name: "Build"}); err != nil {
+			t.Errorf("Failed to add node: %v", err)
+		}
+	}
+	if retry := s.reload(pendingReload{graph: true}); !retry.graph {
+		t.Fatal("Expected the reload to be retried")
+	}
+	if _, err := s.taskManager.GetNode("build"); err != nil {
+		t.Fatalf("Expected the pending change to be kept: %v", err)
+	}
+
+	// The retry writes the change first and then reloads
+	if retry := s.reload(pendingReload{graph: true}); retry.graph {
+		t.Fatal("Expected the retried reload to succeed")
+	}
+	if _, err := os.Stat(filepath.Join(s.config.Directory, "nodes", "build.yaml")); err != nil {
+		t.Errorf("Expected the pending change to be written: %v", err)
+	}
+	if _, err := s.taskManager.GetNode("build"); err != nil {
+		t.Errorf("Expected the reloaded graph to hold the change: %v", err)
+	}
+}
+
+func TestReloadRefreshesTools(t *testing.T) {
+	s, _ := newReloadTestServer(t)
+	session, notifications := connectTestClient(t, s)
+
+	writeTestFile(t, filepath.Join(s.config.Directory, "mcp.yaml"), "naming:\n  node:\n    singular: recipe\n    plural: recipes\n")
+	s.reload(pendingReload{mcp: true})
+	waitForNotification(t, notifications, "tools")
+
+	tools := listToolNames(t, session)
+	if !tools["list_recipes"] || !tools["add_recipe"] {
+		t.Errorf("Expected tools named after recipes, got %v", tools)
+	}
+	if tools["list_tasks"] || tools["add_task"] {
+		t.Errorf("Expected the tools named after tasks to be removed, got %v", tools)
+	}
+	for _, name := range s.registeredToolNames() {
+		if !tools[name] {
+			t.Errorf("Registered tool %s is not listed", name)
+		}
+	}
+}
+
+// TestReloadWhileServingToolCalls is most useful when run with the race detector:
+//
+//	go test -race ./mcp/server/...
+func TestReloadWhileServingToolCalls(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "relationships.yaml"), testRelationships)
+	s, err := New(Config{Directory: dir, Storage: "memory"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := s.taskManager.AddNode(&types.Node{ID: "build", Name: "Build"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	// The handlers are called directly, and nothing is logged, so no lock
+	// shared with the reload orders their reads of the configuration, as
+	// calls through a session would be ordered by the MCP server's own locking
+	done := make(chan struct{})
+	var wg, started sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				result := callTestTool(t, s.handleGetTask, map[string]any{"id": "build"})
+				if n == 0 {
+					started.Done()
+				}
+				if result.IsError {
+					t.Errorf("get_task failed during reload: %s", resultText(result))
+					return
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	// Changing the display names changes the tool descriptions, so the tools
+	// are re-registered on every reload
+	started.Wait()
+	for i := 0; i < 50; i++ {
+		display := "Task"
+		if i%2 == 0 {
+			display = "Job"
+		}
+		writeTestFile(t, filepath.Join(s.config.Directory, "mcp.yaml"), "naming:\n  node:\n    display_singular: "+display+"\n")
+		s.reload(pendingReload{mcp: true})
+	}
+
+	close(done)
+	wg.Wait()
+
+	if got := s.mcpConfig().Naming.Node.DisplaySingular; got != "Task" {
+		t.Errorf("Expected the last mcp.yaml to be loaded, got display name %s", got)
+	}
+}
+
+func TestReloadPrompts(t *testing.T) {
+	s, _ := newReloadTestServer(t)
+	session, notifications := connectTestClient(t, s)
+
+	if tools := listToolNames(t, session); tools["list_prompts"] {
+		t.Fatal("Expected no prompt tools without prompts")
+	}
+
+	promptsDir := filepath.Join(s.config.Directory, "prompts")
+	if err := os.Mkdir(promptsDir, 0755); err != nil {
+		t.Fatalf("Failed to create prompts directory: %v", err)
+	}
+	writeTestFile(t, filepath.Join(promptsDir, "review.md"), "---\ndescription: Review a change\n---\nReview it.\n")
+	s.reload(pendingReload{prompts: true})
+	waitForNotification(t, notifications, "prompts")
+	waitForNotification(t, notifications, "tools")
+
+	result, err := session.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to list prompts: %v", err)
+	}
+	if len(result.Prompts) != 1 || result.Prompts[0].Name != "review" {
+		t.Errorf("Expected the review prompt, got %v", result.Prompts)
+	}
+	if tools := listToolNames(t, session); !tools["list_prompts"] || !tools["get_prompt"] {
+		t.Errorf("Expected the prompt tools once there are prompts, got %v", tools)
+	}
+}
+
+func TestHotReloadSettlesAndSkipsOwnWrites(t *testing.T) {
+	s, logs := newReloadTestServer(t)
+	nodesDir := filepath.Join(s.config.Directory, "nodes")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startHotReload(ctx)
+
+	// Changes arriving together are reloaded once
+	for _, id := range []string{"build", "test", "deploy"} {
+		writeTestFile(t, filepath.Join(nodesDir, id+".yaml"), "id: "+id+"\nname: "+id+"\n")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.taskManager.ListAllNodes()) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the reload, have %d nodes", len(s.taskManager.ListAllNodes()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(4 * reloadDelay)
+	reloads := logs.FilterMessage("Reloading graph from store").Len()
+	if reloads != 1 {
+		t.Errorf("Expected a single reload, got %d", reloads)
+	}
+
+	// Nodes the server writes itself don't trigger a reload
+	if err := s.taskManager.AddNode(&types.Node{ID: "release", Name: "Release"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err := s.persister.Schedule(); err != nil {
+		t.Fatalf("Failed to write node: %v", err)
+	}
+	if err := s.taskManager.DeleteNode("test"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := s.persister.Schedule(); err != nil {
+		t.Fatalf("Failed to write deletion: %v", err)
+	}
+	time.Sleep(4 * reloadDelay)
+	if got := logs.FilterMessage("Reloading graph from store").Len(); got != reloads {
+		t.Errorf("Expected the server's own writes not to trigger a reload, got %d more", got-reloads)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"common-tasks-mcp/pkg/graph_manager"
 
@@ -31,8 +32,17 @@ type Server struct {
 	mcp         *mcp.Server
 	config      Config
 	taskManager *graph_manager.Manager
+	persister   *graph_manager.WriteBehind
 	logger      *zap.Logger
-	prompts     map[string]*PromptInfo // Map of prompt name to prompt info
+
+	// mu guards prompts, config.MCP and toolNames, which the hot reload
+	// replaces while tool calls are served. The prompts map and the slices of
+	// config.MCP are replaced, never modified.
+	mu      sync.RWMutex
+	prompts map[string]*PromptInfo // Map of prompt name to prompt info
+
+	// toolNames holds the names of the registered tools
+	toolNames []string
 }
 
 // New creates a new MCP server instance with a node manager
@@ -58,6 +68,13 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 
 	// Create node manager
 	taskMgr := graph_manager.NewManager(logger)
+	taskMgr.SetAllowForwardReferences(cfg.AllowForwardReferences)
+	if err := taskMgr.SetNodeKinds(mcpConfig.NodeKinds()); err != nil {
+		return nil, fmt.Errorf("failed to set node kinds: %w", err)
+	}
+	if err := taskMgr.SetAttributeDefinitions(mcpConfig.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to set attribute definitions: %w", err)
+	}
 
 	// Load relationships configuration if it exists
 	relationshipsPath := filepath.Join(cfg.Directory, "relationships.yaml")
@@ -76,18 +93,33 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 		logger.Info("Relationships loaded successfully", zap.String("path", relationshipsPath))
 	}
 
-	// Load nodes from directory if any exist
-	nodesPath := filepath.Join(cfg.Directory, "nodes")
-	logger.Info("Loading nodes from directory", zap.String("path", nodesPath))
-	if err := taskMgr.LoadNodesFromDir(nodesPath); err != nil {
-		logger.Error("Failed to load nodes from directory",
-			zap.String("directory", nodesPath),
+	// Load nodes from the configured storage backend
+	nodeStore, err := newStore(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Loading nodes from store", zap.String("storage", cfg.Storage))
+	if err := taskMgr.LoadFromStore(nodeStore); err != nil {
+		logger.Error("Failed to load nodes from store",
+			zap.String("storage", cfg.Storage),
 			zap.Error(err),
 		)
+		nodeStore.Close()
 		return nil, fmt.Errorf("failed to load nodes: %w", err)
 	}
 	nodeCount := len(taskMgr.ListAllNodes())
 	logger.Info("Nodes loaded successfully", zap.Int("count", nodeCount))
+	for _, issue := range taskMgr.Lint() {
+		logger.Warn("Node data problem (run 'mcp lint' to list all)", zap.String("node_id", issue.NodeID), zap.String("problem", issue.Message))
+	}
+
+	// Changes are written back to the nodes directory, coalesced over the
+	// write-behind interval when one is configured
+	writeBehindInterval := cfg.WriteBehindDuration()
+	if writeBehindInterval > 0 {
+		logger.Info("Write-behind persistence enabled", zap.Duration("interval", writeBehindInterval))
+	}
+	persister := graph_manager.NewWriteBehind(taskMgr, writeBehindInterval)
 
 	// Create MCP server using configuration from mcp.yaml
 	logger.Debug("Initializing MCP server instance")
@@ -102,6 +134,7 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 		mcp:         mcpServer,
 		config:      cfg,
 		taskManager: taskMgr,
+		persister:   persister,
 		logger:      logger,
 		prompts:     make(map[string]*PromptInfo),
 	}
@@ -109,10 +142,11 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 	// Load prompts from disk (optional)
 	promptsPath := filepath.Join(cfg.Directory, "prompts")
 	logger.Debug("Loading prompts from directory", zap.String("path", promptsPath))
-	if err := srv.loadPrompts(promptsPath); err != nil {
+	if prompts, err := srv.loadPrompts(promptsPath); err != nil {
 		logger.Debug("Prompts not loaded", zap.String("directory", promptsPath), zap.Error(err))
 		// Continue without prompts - they're optional
 	} else {
+		srv.prompts = prompts
 		logger.Info("Prompts loaded successfully", zap.Int("count", len(srv.prompts)))
 	}
 
@@ -136,6 +170,7 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 // RunHTTP starts the MCP server with HTTP transport
 func (s *Server) RunHTTP(ctx context.Context) error {
 	s.logger.Info("Initializing HTTP transport", zap.Int("port", s.config.HTTPPort))
+	s.startHotReload(ctx)
 
 	// Create streamable HTTP handler
 	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
@@ -181,9 +216,20 @@ func (s *Server) RunHTTP(ctx context.Context) error {
 	}
 }
 
+// Close flushes any changes that haven't been written to disk yet and closes the store
+func (s *Server) Close() error {
+	s.logger.Debug("Flushing pending changes")
+	if err := s.persister.Close(); err != nil {
+		s.logger.Error("Failed to flush pending changes", zap.Error(err))
+		return err
+	}
+	return s.taskManager.Store().Close()
+}
+
 // Run starts the MCP server with stdio transport
 func (s *Server) Run(ctx context.Context) error {
 	s.logger.Info("Starting MCP server with stdio transport")
+	s.startHotReload(ctx)
 	err := s.mcp.Run(ctx, &mcp.StdioTransport{})
 	if err != nil {
 		s.logger.Error("Stdio server error", zap.Error(err))
@@ -194,19 +240,19 @@ func (s *Server) Run(ctx context.Context) error {
 }
 
 // loadPrompts loads prompt files from the specified directory
-func (s *Server) loadPrompts(promptsDir string) error {
+func (s *Server) loadPrompts(promptsDir string) (map[string]*PromptInfo, error) {
 	// Check if prompts directory exists
 	if _, err := os.Stat(promptsDir); os.IsNotExist(err) {
-		return fmt.Errorf("prompts directory does not exist: %s", promptsDir)
+		return nil, fmt.Errorf("prompts directory does not exist: %s", promptsDir)
 	}
 
 	// Read all .md files from the prompts directory
 	entries, err := os.ReadDir(promptsDir)
 	if err != nil {
-		return fmt.Errorf("failed to read prompts directory: %w", err)
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
 	}
 
-	loadedCount := 0
+	prompts := make(map[string]*PromptInfo)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -234,8 +280,7 @@ func (s *Server) loadPrompts(promptsDir string) error {
 		// Parse frontmatter and content
 		promptInfo := s.parsePromptFile(string(content), promptName)
 
-		s.prompts[promptName] = promptInfo
-		loadedCount++
+		prompts[promptName] = promptInfo
 
 		s.logger.Debug("Loaded prompt",
 			zap.String("name", promptName),
@@ -245,11 +290,11 @@ func (s *Server) loadPrompts(promptsDir string) error {
 		)
 	}
 
-	if loadedCount == 0 {
-		return fmt.Errorf("no prompt files found in directory")
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompt files found in directory")
 	}
 
-	return nil
+	return prompts, nil
 }
 
 // parsePromptFile parses a prompt file with optional YAML frontmatter
@@ -297,31 +342,53 @@ func (s *Server) parsePromptFile(content string, promptName string) *PromptInfo
 // registerPrompts registers all MCP prompts with the server
 func (s *Server) registerPrompts() {
 	// Register all prompts that were successfully loaded
-	for promptName, promptInfo := range s.prompts {
-		prompt := &mcp.Prompt{
-			Name:        promptName,
-			Description: promptInfo.Description,
-		}
-
-		s.mcp.AddPrompt(prompt, s.handlePrompt)
-		s.logger.Debug("Registered prompt",
-			zap.String("name", promptName),
-			zap.String("description", promptInfo.Description),
-		)
+	prompts := s.currentPrompts()
+	for promptName, promptInfo := range prompts {
+		s.registerPrompt(promptName, promptInfo)
 	}
 
-	if len(s.prompts) == 0 {
+	if len(prompts) == 0 {
 		s.logger.Info("No prompts registered (none found in prompts directory)")
 	}
 }
 
+// registerPrompt registers a single prompt, replacing any prompt with the same name
+func (s *Server) registerPrompt(promptName string, promptInfo *PromptInfo) {
+	prompt := &mcp.Prompt{
+		Name:        promptName,
+		Description: promptInfo.Description,
+	}
+
+	s.mcp.AddPrompt(prompt, s.handlePrompt)
+	s.logger.Debug("Registered prompt",
+		zap.String("name", promptName),
+		zap.String("description", promptInfo.Description),
+	)
+}
+
+// currentPrompts returns the loaded prompts. The returned map must not be modified.
+func (s *Server) currentPrompts() map[string]*PromptInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.prompts
+}
+
+// mcpConfig returns the current mcp.yaml configuration. Its slices must not be modified.
+func (s *Server) mcpConfig() MCPConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.config.MCP
+}
+
 // handlePrompt is a generic handler for all prompts
 func (s *Server) handlePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 	promptName := req.Params.Name
 	s.logger.Debug("Handling prompt request", zap.String("name", promptName))
 
 	// Get prompt info from loaded prompts
-	promptInfo, exists := s.prompts[promptName]
+	promptInfo, exists := s.currentPrompts()[promptName]
 	if !exists {
 		s.logger.Error("Prompt not found", zap.String("name", promptName))
 		return nil, fmt.Errorf("prompt %s not found", promptName)
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const testWorkflowRelationships = `relationships:
+  - name: prerequisites
+    description: Tasks that must be completed before this node
+    direction: backward
+  - name: related
+    direction: none
+`
+
+// newTestServer creates a server with the workflow relationships and nodes
+// held in memory
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "relationships.yaml"), testWorkflowRelationships)
+	s, err := New(Config{Directory: dir, Storage: "memory"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+
+	"go.uber.org/zap"
+)
+
+// supportedStorage lists the storage backends that can be configured
+var supportedStorage = []string{"dir", "bolt", "memory"}
+
+// isSupportedStorage reports whether the storage backend name is known
+func isSupportedStorage(name string) bool {
+	for _, supported := range supportedStorage {
+		if name == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// newStore creates the storage backend selected by the configuration
+func newStore(cfg Config, logger *zap.Logger) (store.Store, error) {
+	switch cfg.Storage {
+	case "dir":
+		path := cfg.StoragePath
+		if path == "" {
+			path = filepath.Join(cfg.Directory, "nodes")
+		}
+		logger.Info("Using directory storage", zap.String("path", path))
+		return store.NewDirStore(path, logger), nil
+	case "bolt":
+		path := cfg.StoragePath
+		if path == "" {
+			path = filepath.Join(cfg.Directory, "nodes.db")
+		}
+		logger.Info("Using bolt database storage", zap.String("path", path))
+		boltStore, err := store.NewBoltStore(path, logger)
+		if err != nil {
+			return nil, err
+		}
+		return boltStore, nil
+	case "memory":
+		logger.Warn("Using in-memory storage: changes will not survive a restart")
+		return store.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend '%s'", cfg.Storage)
+	}
+}
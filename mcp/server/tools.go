@@ -1,43 +1,107 @@
 package server
 
 import (
+	"common-tasks-mcp/pkg/graph_manager"
 	"common-tasks-mcp/pkg/graph_manager/types"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
 )
 
+// addTool registers a tool, replacing any tool with the same name, and records its name
+func (s *Server) addTool(tool *mcp.Tool, handler mcp.ToolHandler) {
+	s.mcp.AddTool(tool, handler)
+
+	s.mu.Lock()
+	s.toolNames = append(s.toolNames, tool.Name)
+	s.mu.Unlock()
+}
+
+// registeredToolNames returns the names of the registered tools
+func (s *Server) registeredToolNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.toolNames...)
+}
+
 // registerTools registers all MCP tools with the server
 func (s *Server) registerTools() {
-	naming := s.config.MCP.Naming.Node
+	config := s.mcpConfig()
+	naming := config.Naming.Node
 
 	// Read-only tools (always registered)
 
 	// List tasks tool
-	s.mcp.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        fmt.Sprintf("list_%s", naming.Plural),
-		Description: fmt.Sprintf("Browse available %s, optionally filtered by tags (e.g., 'backend', 'database', 'deployment'). Returns %s summaries with ID, name, and a brief description. Use this to discover relevant workflows when starting work in a new area or looking for standard procedures. If you provide multiple tags, you'll get %s that match any of them.", naming.Plural, naming.Singular, naming.Plural),
+		Description: fmt.Sprintf("Browse available %s, optionally filtered by tags (e.g., 'backend', 'database', 'deployment'). Returns %s summaries with ID, name, and a brief description. Use this to discover relevant workflows when starting work in a new area or looking for standard procedures. If you provide multiple tags, you'll get %s that match any of them. Only active %s are listed unless you filter by status.", naming.Plural, naming.Singular, naming.Plural, naming.Plural),
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": s.withKind(s.listProperties(naming), fmt.Sprintf("Only list %s of this kind", naming.Plural)),
+		},
+	}, s.handleListTasks)
+
+	// Get task tool
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("get_%s", naming.Singular),
+		Description: fmt.Sprintf("Get the complete workflow for a specific %s by its ID. Returns the full %s description plus related %s: what must be done first (prerequisites), what must follow (required), and what's recommended (suggested). Use this before starting any %s to understand the complete workflow, not just the immediate action. This helps you avoid missing critical steps. The returned revision can be passed as expectedRevision when updating or deleting the %s, so changes made by someone else in the meantime aren't overwritten. Pass a context describing the situation (environment, language, changed paths) to leave out the edges whose conditions don't hold. A superseded %s redirects to the %s replacing it.", naming.Singular, naming.Singular, naming.Plural, naming.Singular, naming.Singular, naming.Singular, naming.Singular),
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
-				"tags": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": fmt.Sprintf("%s ID", naming.DisplaySingular),
+				},
+				"context":  contextSchema(naming),
+				"redirect": redirectSchema(naming),
+			},
+			"required": []string{"id"},
+		},
+	}, s.handleGetTask)
+
+	// Related tasks tool
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("get_related_%s", naming.Plural),
+		Description: fmt.Sprintf("Get every %s transitively connected to a %s through one or more relationships, with the depth at which each was reached. Use direction 'descendants' to follow the relationship from the %s (for example all prerequisites of prerequisites), or 'ancestors' to find the %s that lead to it. This replaces repeated get_%s calls when you need the complete chain. Pass a context to follow only the edges whose conditions hold.", naming.Singular, naming.Singular, naming.Singular, naming.Plural, naming.Singular),
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": fmt.Sprintf("%s ID to start from", naming.DisplaySingular),
+				},
+				"relationships": map[string]interface{}{
 					"type":        "array",
-					"items":       map[string]string{"type": "string"},
-					"description": "Optional array of tags to filter by",
+					"items":       s.relationshipNameSchema(),
+					"description": "Relationships to follow, each reported separately (default: all)",
 				},
+				"direction": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"descendants", "ancestors"},
+					"description": "Follow edges from the start (descendants, default) or towards it (ancestors)",
+				},
+				"maxDepth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of edges to follow (default: no limit)",
+				},
+				"context": contextSchema(naming),
 			},
+			"required": []string{"id"},
 		},
-	}, s.handleListTasks)
+	}, s.handleGetRelatedTasks)
 
-	// Get task tool
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        fmt.Sprintf("get_%s", naming.Singular),
-		Description: fmt.Sprintf("Get the complete workflow for a specific %s by its ID. Returns the full %s description plus related %s: what must be done first (prerequisites), what must follow (required), and what's recommended (suggested). Use this before starting any %s to understand the complete workflow, not just the immediate action. This helps you avoid missing critical steps.", naming.Singular, naming.Singular, naming.Plural, naming.Singular),
+	// Dependents tool
+	s.addTool(&mcp.Tool{
+		Name:        "get_dependents",
+		Description: fmt.Sprintf("Get the %s whose relationships point directly at a %s, such as every %s that lists it as a prerequisite. Use this before changing or deleting a %s to see what relies on it.", naming.Plural, naming.Singular, naming.Singular, naming.Singular),
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -45,13 +109,35 @@ func (s *Server) registerTools() {
 					"type":        "string",
 					"description": fmt.Sprintf("%s ID", naming.DisplaySingular),
 				},
+				"relationships": map[string]interface{}{
+					"type":        "array",
+					"items":       s.relationshipNameSchema(),
+					"description": "Relationships to include, each reported separately (default: all)",
+				},
 			},
 			"required": []string{"id"},
 		},
-	}, s.handleGetTask)
+	}, s.handleGetDependents)
+
+	// Plan task tool
+	s.addTool(&mcp.Tool{
+		Name:        fmt.Sprintf("plan_%s", naming.Singular),
+		Description: fmt.Sprintf("Get an ordered, numbered checklist for carrying out a %s: everything that must come before it, the %s itself, and everything that must follow, transitively. Steps are grouped into stages; steps in the same stage don't depend on each other and can be done in parallel. Follow the checklist from top to bottom. Pass a context describing the situation (environment, language, changed paths) to plan only the steps whose conditions hold; the steps left out are listed with the reason.", naming.Singular, naming.Singular),
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": fmt.Sprintf("%s ID to plan", naming.DisplaySingular),
+				},
+				"context": contextSchema(naming),
+			},
+			"required": []string{"id"},
+		},
+	}, s.handlePlanTask)
 
 	// List tags tool
-	s.mcp.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "list_tags",
 		Description: fmt.Sprintf("Get all unique tags used across all %s. Returns each tag with the count of %s that use it. Use this to discover available tags for filtering and categorization.", naming.Plural, naming.Plural),
 		InputSchema: map[string]interface{}{
@@ -61,9 +147,9 @@ func (s *Server) registerTools() {
 	}, s.handleListTags)
 
 	// Prompt tools (only registered if prompts are available)
-	if len(s.prompts) > 0 {
+	if len(s.currentPrompts()) > 0 {
 		// List prompts tool
-		s.mcp.AddTool(&mcp.Tool{
+		s.addTool(&mcp.Tool{
 			Name:        "list_prompts",
 			Description: "Get all available prompts that can be used with this MCP server. Returns prompt names with their descriptions. Prompts are loaded from the prompts/ directory and can be customized per deployment.",
 			InputSchema: map[string]interface{}{
@@ -73,7 +159,7 @@ func (s *Server) registerTools() {
 		}, s.handleListPrompts)
 
 		// Get prompt tool
-		s.mcp.AddTool(&mcp.Tool{
+		s.addTool(&mcp.Tool{
 			Name:        "get_prompt",
 			Description: "Get the full content of a specific prompt by name. Use list_prompts to discover available prompts first.",
 			InputSchema: map[string]interface{}{
@@ -92,125 +178,230 @@ func (s *Server) registerTools() {
 	// Write tools (only registered when not in read-only mode)
 	if !s.config.ReadOnly {
 		// Add task tool
-		s.mcp.AddTool(&mcp.Tool{
+		s.addTool(&mcp.Tool{
 			Name:        fmt.Sprintf("add_%s", naming.Singular),
-			Description: fmt.Sprintf("Create a new %s with its complete workflow. Include what needs to happen before this %s (prerequisites), what must happen after (required follow-ups), and what's recommended after (suggested follow-ups). Use this to document repeatable workflows so future work can follow the same process. The system ensures workflows stay consistent by preventing circular dependencies.", naming.Singular, naming.Singular),
+			Description: fmt.Sprintf("Create a new %s with its complete workflow. Link it to related %s through edges, giving the IDs for each relationship type. Use this to document repeatable workflows so future work can follow the same process. The system ensures workflows stay consistent by preventing circular dependencies.", naming.Singular, naming.Plural),
 			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("Unique %s identifier", naming.Singular),
-					},
-					"name": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("%s name", naming.DisplaySingular),
-					},
-					"summary": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("Brief summary of the %s", naming.Singular),
-					},
-					"description": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("Detailed description of the %s", naming.Singular),
-					},
-					"tags": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": "Array of tags for categorization",
-					},
-					"prerequisiteIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of prerequisite %s IDs that must be completed first", naming.Singular),
-					},
-					"downstreamRequiredIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of required downstream %s IDs that must follow", naming.Singular),
-					},
-					"downstreamSuggestedIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of suggested downstream %s IDs", naming.Singular),
-					},
-				},
-				"required": []string{"id", "name"},
+				"type":       "object",
+				"properties": s.withKind(s.addProperties(naming), fmt.Sprintf("Kind of %s, which decides the fields it requires and the relationships it may use", naming.Singular)),
+				"required":   []string{"id", "name"},
 			},
 		}, s.handleAddTask)
 
 		// Update task tool
-		s.mcp.AddTool(&mcp.Tool{
+		updateProperties := s.withAttributes(s.withKind(withLifecycle(map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("%s ID (must exist)", naming.DisplaySingular),
+			},
+			"expectedRevision": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Revision of the %s your change is based on, as returned by get_%s. If the %s has changed since, nothing is updated and its current state is returned.", naming.Singular, naming.Singular, naming.Singular),
+			},
+			"replace": map[string]interface{}{
+				"type":        "boolean",
+				"description": fmt.Sprintf("Replace the whole %s: omitted fields are cleared and name is required (default false)", naming.Singular),
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("%s name", naming.DisplaySingular),
+			},
+			"summary": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Brief summary of the %s", naming.Singular),
+			},
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Detailed description of the %s", naming.Singular),
+			},
+			"condition": conditionSchema(naming),
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]string{"type": "string"},
+				"description": "Array of tags for categorization, replacing the existing tags",
+			},
+			"addTags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]string{"type": "string"},
+				"description": "Tags to add",
+			},
+			"removeTags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]string{"type": "string"},
+				"description": "Tags to remove",
+			},
+			"edges":       s.edgesSchema(fmt.Sprintf("Related %s IDs, keyed by relationship. Replaces the targets of each relationship given, and the attributes of their edges; an empty array removes the relationship.", naming.Singular), true),
+			"addEdges":    s.edgesSchema(fmt.Sprintf("%s IDs to add, keyed by relationship. Giving attributes for an existing target replaces the attributes of its edge.", naming.DisplaySingular), true),
+			"removeEdges": s.edgesSchema(fmt.Sprintf("%s IDs to remove, keyed by relationship", naming.DisplaySingular), false),
+		}, naming), fmt.Sprintf("Kind of %s", naming.Singular)), fmt.Sprintf("Attributes to set, keeping the others. A null value removes the attribute, or resets it to its default. With replace, the complete attributes of the %s, defaults filling in those left out.", naming.Singular), false, true)
+		s.addTool(&mcp.Tool{
 			Name:        fmt.Sprintf("update_%s", naming.Singular),
-			Description: fmt.Sprintf("Modify an existing %s's description or workflow relationships. Use this when a process changes and you need to update the documented workflow - for example, adding a new required step, removing an outdated prerequisite, or refining the %s description. The %s ID must already exist.", naming.Singular, naming.Singular, naming.Singular),
+			Description: fmt.Sprintf("Modify an existing %s's description or workflow relationships. Use this when a process changes and you need to update the documented workflow - for example, adding a new required step, removing an outdated prerequisite, or refining the %s description. The %s ID must already exist. Only the fields you send are changed: omitted fields keep their values, and an empty value clears a field. Use addTags/removeTags and addEdges/removeEdges to change individual tags and edge targets. Set replace to true to replace the whole %s instead.", naming.Singular, naming.Singular, naming.Singular, naming.Singular),
 			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("%s ID (must exist)", naming.DisplaySingular),
-					},
-					"name": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("%s name", naming.DisplaySingular),
-					},
-					"summary": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("Brief summary of the %s", naming.Singular),
-					},
-					"description": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("Detailed description of the %s", naming.Singular),
-					},
-					"tags": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": "Array of tags for categorization",
-					},
-					"prerequisiteIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of prerequisite %s IDs that must be completed first", naming.Singular),
-					},
-					"downstreamRequiredIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of required downstream %s IDs that must follow", naming.Singular),
-					},
-					"downstreamSuggestedIDs": map[string]interface{}{
-						"type":        "array",
-						"items":       map[string]string{"type": "string"},
-						"description": fmt.Sprintf("Array of suggested downstream %s IDs", naming.Singular),
-					},
-				},
-				"required": []string{"id", "name"},
+				"type":       "object",
+				"properties": withDryRun(updateProperties, naming.Singular),
+				"required":   []string{"id"},
 			},
 		}, s.handleUpdateTask)
 
 		// Delete task tool
-		s.mcp.AddTool(&mcp.Tool{
+		deleteProperties := map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("%s ID to delete", naming.DisplaySingular),
+			},
+			"expectedRevision": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Revision of the %s as returned by get_%s. If the %s has changed since, it is not deleted and its current state is returned.", naming.Singular, naming.Singular, naming.Singular),
+			},
+		}
+		s.addTool(&mcp.Tool{
 			Name:        fmt.Sprintf("delete_%s", naming.Singular),
-			Description: fmt.Sprintf("Remove a %s entirely. This automatically cleans up any references to this %s in other %s' workflows. Use this when a %s is no longer relevant or has been superseded by a different workflow. This action cannot be undone.", naming.Singular, naming.Singular, naming.Singular, naming.Singular),
+			Description: fmt.Sprintf("Remove a %s entirely. This automatically cleans up any references to this %s in other %s' workflows. Use this when a %s is no longer relevant or has been superseded by a different workflow. This action cannot be undone, so set dryRun to true or use preview_change first to see what would be affected.", naming.Singular, naming.Singular, naming.Singular, naming.Singular),
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": withDryRun(deleteProperties, naming.Singular),
+				"required":   []string{"id"},
+			},
+		}, s.handleDeleteTask)
+
+		// Preview change tool
+		previewProperties := map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"update", "delete"},
+				"description": "Kind of change to preview",
+			},
+		}
+		for name, property := range updateProperties {
+			previewProperties[name] = property
+		}
+		s.addTool(&mcp.Tool{
+			Name:        "preview_change",
+			Description: fmt.Sprintf("Preview an update or deletion of a %s without applying it. Reports the edges that would be removed or added, the other %s affected, and which workflows would lose steps, directly or transitively. Takes the same arguments as update_%s or delete_%s, plus the operation. Use this before deleting a %s or removing relationships.", naming.Singular, naming.Plural, naming.Singular, naming.Singular, naming.Singular),
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": previewProperties,
+				"required":   []string{"operation", "id"},
+			},
+		}, s.handlePreviewChange)
+
+		// Apply changes tool
+		applyChangesDescription := fmt.Sprintf("Apply a batch of %s additions, updates and deletions as a single atomic change. The final result is validated once (no circular dependencies, no references to missing %s, only known relationship types), so you can restructure a workflow in several steps. If any operation is invalid, nothing is changed.", naming.Singular, naming.Plural)
+		if s.config.AllowForwardReferences {
+			applyChangesDescription += fmt.Sprintf(" Operations may reference %s that are added later in the same batch.", naming.Plural)
+		} else {
+			applyChangesDescription += fmt.Sprintf(" Operations may only reference %s that already exist or are added earlier in the batch.", naming.Plural)
+		}
+		s.addTool(&mcp.Tool{
+			Name:        "apply_changes",
+			Description: applyChangesDescription,
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": fmt.Sprintf("%s ID to delete", naming.DisplaySingular),
+					"operations": map[string]interface{}{
+						"type":        "array",
+						"description": "Operations to apply, in order",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": s.withAttributes(s.withKind(withLifecycle(map[string]interface{}{
+								"op": map[string]interface{}{
+									"type":        "string",
+									"enum":        []string{"add", "update", "delete"},
+									"description": "Operation type",
+								},
+								"id": map[string]interface{}{
+									"type":        "string",
+									"description": fmt.Sprintf("%s ID", naming.DisplaySingular),
+								},
+								"name": map[string]interface{}{
+									"type":        "string",
+									"description": fmt.Sprintf("%s name (add and update)", naming.DisplaySingular),
+								},
+								"summary": map[string]interface{}{
+									"type":        "string",
+									"description": fmt.Sprintf("Brief summary of the %s (add and update)", naming.Singular),
+								},
+								"description": map[string]interface{}{
+									"type":        "string",
+									"description": fmt.Sprintf("Detailed description of the %s (add and update)", naming.Singular),
+								},
+								"condition": conditionSchema(naming),
+								"tags": map[string]interface{}{
+									"type":        "array",
+									"items":       map[string]string{"type": "string"},
+									"description": "Array of tags for categorization (add and update)",
+								},
+								"edges": s.edgesSchema(fmt.Sprintf("Related %s IDs, keyed by relationship (add and update)", naming.Singular), true),
+								"expectedRevision": map[string]interface{}{
+									"type":        "string",
+									"description": fmt.Sprintf("Revision of the %s as returned by get_%s (update and delete). If the %s has changed since, nothing is changed and its current state is returned.", naming.Singular, naming.Singular, naming.Singular),
+								},
+							}, naming), fmt.Sprintf("Kind of %s (add and update)", naming.Singular)), fmt.Sprintf("Attributes of the %s (add and update)", naming.Singular), false, false),
+							"required": []string{"op", "id"},
+						},
 					},
 				},
-				"required": []string{"id"},
+				"required": []string{"operations"},
 			},
-		}, s.handleDeleteTask)
+		}, s.handleApplyChanges)
+	}
+
+	for _, kind := range config.Kinds {
+		s.registerKindTools(kind)
 	}
 }
 
+// listProperties returns the properties of the list tools
+func (s *Server) listProperties(naming NodeNaming) map[string]interface{} {
+	return s.withAttributes(map[string]interface{}{
+		"tags": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]string{"type": "string"},
+			"description": "Optional array of tags to filter by",
+		},
+		"status": listStatusSchema(naming),
+	}, fmt.Sprintf("Only list %s with all of these attribute values", naming.Plural), false, false)
+}
+
+// addProperties returns the properties of the add tools
+func (s *Server) addProperties(naming NodeNaming) map[string]interface{} {
+	return s.withAttributes(withLifecycle(map[string]interface{}{
+		"id": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("Unique %s identifier", naming.Singular),
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("%s name", naming.DisplaySingular),
+		},
+		"summary": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("Brief summary of the %s", naming.Singular),
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("Detailed description of the %s", naming.Singular),
+		},
+		"condition": conditionSchema(naming),
+		"tags": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]string{"type": "string"},
+			"description": "Array of tags for categorization",
+		},
+		"edges": s.edgesSchema(fmt.Sprintf("Related %s IDs, keyed by relationship", naming.Singular), true),
+	}, naming), fmt.Sprintf("Attributes of the %s", naming.Singular), true, false)
+}
+
 // handleListTasks handles the list_tasks tool
 func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Handling list_tasks request")
 
 	var args struct {
-		Tags []string `json:"tags"`
+		Tags       []string           `json:"tags"`
+		Kind       string             `json:"kind"`
+		Status     []types.NodeStatus `json:"status"`
+		Attributes map[string]any     `json:"attributes"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -225,7 +416,7 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 		}, nil
 	}
 
-	s.logger.Info("Listing nodes", zap.Strings("tags", args.Tags))
+	s.logger.Info("Listing nodes", zap.Strings("tags", args.Tags), zap.String("kind", args.Kind))
 
 	var nodes []*types.Node
 
@@ -248,6 +439,15 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 		s.logger.Debug("Retrieved all nodes", zap.Int("count", len(nodes)))
 	}
 
+	// Drafts and deprecated nodes are only listed when asked for
+	matching := nodes[:0:0]
+	for _, node := range nodes {
+		if (args.Kind == "" || node.Kind == args.Kind) && matchesStatus(node, args.Status) && matchesAttributes(node, args.Attributes) {
+			matching = append(matching, node)
+		}
+	}
+	nodes = matching
+
 	s.logger.Info("Successfully listed nodes", zap.Int("node_count", len(nodes)))
 
 	return &mcp.CallToolResult{
@@ -263,8 +463,12 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 func (s *Server) handleGetTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Handling get_task request")
 
+	// Kind is set by the get tools of the node kinds
 	var args struct {
-		ID string `json:"id"`
+		ID       string      `json:"id"`
+		Kind     string      `json:"kind"`
+		Context  types.Facts `json:"context"`
+		Redirect *bool       `json:"redirect"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -282,6 +486,9 @@ func (s *Server) handleGetTask(ctx context.Context, req *mcp.CallToolRequest) (*
 	s.logger.Info("Getting node", zap.String("node_id", args.ID))
 
 	node, err := s.taskManager.GetNode(args.ID)
+	if err == nil && args.Kind != "" && node.Kind != args.Kind {
+		err = fmt.Errorf("node %s is %s, not %s", args.ID, types.KindName(node.Kind), args.Kind)
+	}
 	if err != nil {
 		s.logger.Error("Failed to get node", zap.String("node_id", args.ID), zap.Error(err))
 		return &mcp.CallToolResult{
@@ -294,12 +501,240 @@ func (s *Server) handleGetTask(ctx context.Context, req *mcp.CallToolRequest) (*
 		}, nil
 	}
 
-	s.logger.Info("Successfully retrieved node", zap.String("node_id", args.ID), zap.String("node_name", node.Name))
+	// Agents asking for a superseded node are sent to its replacement
+	var redirected string
+	if node.Status == types.StatusSuperseded && (args.Redirect == nil || *args.Redirect) {
+		replacement, err := s.taskManager.ResolveSuperseded(node.ID)
+		if err != nil {
+			s.logger.Warn("Failed to resolve superseded node", zap.String("node_id", node.ID), zap.Error(err))
+		} else if replacement.ID != node.ID {
+			redirected = fmt.Sprintf("_`%s` is superseded by `%s`, shown below. Pass redirect false to see `%s` itself._\n\n", node.ID, replacement.ID, node.ID)
+			node = replacement
+		}
+	}
+
+	s.logger.Info("Successfully retrieved node", zap.String("node_id", node.ID), zap.String("node_name", node.Name))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: formatNodeAsMarkdown(node, s.taskManager),
+				Text: redirected + formatNodeAsMarkdown(node, s.mcpConfig().kindNaming(node.Kind), s.taskManager, args.Context),
+			},
+		},
+	}, nil
+}
+
+// handleGetRelatedTasks handles the get_related_tasks tool
+func (s *Server) handleGetRelatedTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Handling get_related_tasks request")
+
+	var args struct {
+		ID            string      `json:"id"`
+		Relationships []string    `json:"relationships"`
+		Direction     string      `json:"direction"`
+		MaxDepth      int         `json:"maxDepth"`
+		Context       types.Facts `json:"context"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		s.logger.Error("Failed to parse get_related_tasks arguments", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to parse arguments: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	if args.Direction == "" {
+		args.Direction = "descendants"
+	}
+	traverse := s.taskManager.Descendants
+	switch args.Direction {
+	case "descendants":
+	case "ancestors":
+		traverse = s.taskManager.Ancestors
+	default:
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("direction must be 'descendants' or 'ancestors', got '%s'", args.Direction),
+				},
+			},
+		}, nil
+	}
+
+	if len(args.Relationships) == 0 {
+		args.Relationships = s.taskManager.GetRegisteredRelationshipNames()
+		sort.Strings(args.Relationships)
+	}
+	if err := s.validateRelationshipNames(args.Relationships); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Getting related nodes",
+		zap.String("node_id", args.ID),
+		zap.Strings("relationships", args.Relationships),
+		zap.String("direction", args.Direction),
+		zap.Int("max_depth", args.MaxDepth),
+	)
+
+	related := make(map[string][]graph_manager.TraversalResult, len(args.Relationships))
+	var excluded []graph_manager.ExcludedEdge
+	reportExcluded := graph_manager.ReportExcluded(func(edge graph_manager.ExcludedEdge) {
+		excluded = append(excluded, edge)
+	})
+	for _, relationshipName := range args.Relationships {
+		results, err := traverse(args.ID, relationshipName, args.MaxDepth, graph_manager.WithFacts(args.Context), reportExcluded)
+		if err != nil {
+			s.logger.Error("Failed to traverse graph",
+				zap.String("node_id", args.ID),
+				zap.String("relationship", relationshipName),
+				zap.Error(err),
+			)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("failed to get related nodes: %v", err),
+					},
+				},
+			}, nil
+		}
+		related[relationshipName] = results
+	}
+
+	s.logger.Info("Successfully retrieved related nodes", zap.String("node_id", args.ID))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatRelatedNodesAsMarkdown(args.ID, args.Direction, args.Relationships, related, excluded),
+			},
+		},
+	}, nil
+}
+
+// handleGetDependents handles the get_dependents tool
+func (s *Server) handleGetDependents(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Handling get_dependents request")
+
+	var args struct {
+		ID            string   `json:"id"`
+		Relationships []string `json:"relationships"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		s.logger.Error("Failed to parse get_dependents arguments", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to parse arguments: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	if len(args.Relationships) == 0 {
+		args.Relationships = s.taskManager.GetRegisteredRelationshipNames()
+		sort.Strings(args.Relationships)
+	}
+	if err := s.validateRelationshipNames(args.Relationships); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Getting dependents",
+		zap.String("node_id", args.ID),
+		zap.Strings("relationships", args.Relationships),
+	)
+
+	dependents, err := s.taskManager.GetDependents(args.ID)
+	if err != nil {
+		s.logger.Error("Failed to get dependents", zap.String("node_id", args.ID), zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to get dependents: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Successfully retrieved dependents", zap.String("node_id", args.ID))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatDependentsAsMarkdown(args.ID, args.Relationships, dependents, s.taskManager.GetAllRelationships()),
+			},
+		},
+	}, nil
+}
+
+// handlePlanTask handles the plan_task tool
+func (s *Server) handlePlanTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Handling plan_task request")
+
+	var args struct {
+		ID      string      `json:"id"`
+		Context types.Facts `json:"context"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		s.logger.Error("Failed to parse plan_task arguments", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to parse arguments: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Planning node", zap.String("node_id", args.ID))
+
+	plan, err := s.taskManager.ExecutionPlan(args.ID, graph_manager.WithFacts(args.Context))
+	if err != nil {
+		s.logger.Error("Failed to plan node", zap.String("node_id", args.ID), zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to plan node: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Successfully planned node",
+		zap.String("node_id", args.ID),
+		zap.Int("steps", len(plan.Steps())),
+	)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatExecutionPlanAsMarkdown(plan),
 			},
 		},
 	}, nil
@@ -328,7 +763,7 @@ func (s *Server) handleListPrompts(ctx context.Context, req *mcp.CallToolRequest
 	s.logger.Debug("Handling list_prompts request")
 
 	// Get all loaded prompts
-	prompts := s.prompts
+	prompts := s.currentPrompts()
 
 	s.logger.Info("Successfully retrieved prompts", zap.Int("prompt_count", len(prompts)))
 
@@ -364,7 +799,7 @@ func (s *Server) handleGetPrompt(ctx context.Context, req *mcp.CallToolRequest)
 	s.logger.Info("Getting prompt", zap.String("name", args.Name))
 
 	// Get prompt info
-	promptInfo, exists := s.prompts[args.Name]
+	promptInfo, exists := s.currentPrompts()[args.Name]
 	if !exists {
 		s.logger.Error("Prompt not found", zap.String("name", args.Name))
 		return &mcp.CallToolResult{
@@ -396,14 +831,18 @@ func (s *Server) handleAddTask(ctx context.Context, req *mcp.CallToolRequest) (*
 	s.logger.Debug("Handling add_task request")
 
 	var args struct {
-		ID                     string   `json:"id"`
-		Name                   string   `json:"name"`
-		Summary                string   `json:"summary"`
-		Description            string   `json:"description"`
-		Tags                   []string `json:"tags"`
-		PrerequisiteIDs        []string `json:"prerequisiteIDs"`
-		DownstreamRequiredIDs  []string `json:"downstreamRequiredIDs"`
-		DownstreamSuggestedIDs []string `json:"downstreamSuggestedIDs"`
+		ID                     string                        `json:"id"`
+		Name                   string                        `json:"name"`
+		Kind                   string                        `json:"kind"`
+		Status                 types.NodeStatus              `json:"status"`
+		SupersededBy           string                        `json:"supersededBy"`
+		Condition              string                        `json:"condition"`
+		Summary                string                        `json:"summary"`
+		Description            string                        `json:"description"`
+		Tags                   []string                      `json:"tags"`
+		Attributes             map[string]any                `json:"attributes"`
+		Edges                  map[string][]types.EdgeTarget `json:"edges"`
+		AllowDeprecatedTargets bool                          `json:"allowDeprecatedTargets"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -418,29 +857,45 @@ func (s *Server) handleAddTask(ctx context.Context, req *mcp.CallToolRequest) (*
 		}, nil
 	}
 
+	edgeIDs, edgeAttributes := types.SplitEdgeTargets(args.Edges)
+	if err := s.validateEdges(edgeIDs); err != nil {
+		s.logger.Error("Rejected add_task arguments", zap.String("node_id", args.ID), zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("invalid edges: %v", err),
+				},
+			},
+		}, nil
+	}
+
 	s.logger.Info("Adding new node",
 		zap.String("node_id", args.ID),
 		zap.String("node_name", args.Name),
+		zap.String("kind", args.Kind),
 		zap.Strings("tags", args.Tags),
 	)
 
 	now := time.Now()
 	node := &types.Node{
-		ID:          args.ID,
-		Name:        args.Name,
-		Summary:     args.Summary,
-		Description: args.Description,
-		Tags:        args.Tags,
-		EdgeIDs: map[string][]string{
-			"prerequisites":        args.PrerequisiteIDs,
-			"downstream_required":  args.DownstreamRequiredIDs,
-			"downstream_suggested": args.DownstreamSuggestedIDs,
-		},
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:             args.ID,
+		Name:           args.Name,
+		Kind:           args.Kind,
+		Status:         args.Status,
+		SupersededBy:   args.SupersededBy,
+		Condition:      args.Condition,
+		Summary:        args.Summary,
+		Description:    args.Description,
+		Tags:           args.Tags,
+		Attributes:     args.Attributes,
+		EdgeIDs:        edgeIDs,
+		EdgeAttributes: edgeAttributes,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
-	if err := s.taskManager.AddNode(node); err != nil {
+	if err := s.taskManager.AddNode(node, deprecatedTargetsOption(args.AllowDeprecatedTargets)...); err != nil {
 		s.logger.Error("Failed to add node",
 			zap.String("node_id", args.ID),
 			zap.String("node_name", args.Name),
@@ -457,11 +912,9 @@ func (s *Server) handleAddTask(ctx context.Context, req *mcp.CallToolRequest) (*
 	}
 
 	// Persist changes to disk
-	nodesPath := filepath.Join(s.config.Directory, "nodes")
-	if err := s.taskManager.PersistToDir(nodesPath); err != nil {
+	if err := s.persister.Schedule(); err != nil {
 		s.logger.Error("Failed to persist node to disk",
 			zap.String("node_id", args.ID),
-			zap.String("path", nodesPath),
 			zap.Error(err),
 		)
 		return &mcp.CallToolResult{
@@ -476,10 +929,15 @@ func (s *Server) handleAddTask(ctx context.Context, req *mcp.CallToolRequest) (*
 
 	s.logger.Info("Successfully added node", zap.String("node_id", args.ID), zap.String("node_name", args.Name))
 
+	// The stored node has the default attribute values filled in
+	if stored, err := s.taskManager.GetNode(node.ID); err == nil {
+		node = stored
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Node `%s` created successfully\n\n%s", node.ID, formatNodeAsMarkdown(node, s.taskManager)),
+				Text: fmt.Sprintf("✓ Node `%s` created successfully\n\n%s", node.ID, formatNodeAsMarkdown(node, s.mcpConfig().kindNaming(node.Kind), s.taskManager, nil)),
 			},
 		},
 	}, nil
@@ -489,15 +947,27 @@ func (s *Server) handleAddTask(ctx context.Context, req *mcp.CallToolRequest) (*
 func (s *Server) handleUpdateTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Handling update_task request")
 
+	// Pointer fields distinguish omitted fields (nil) from fields set to empty
 	var args struct {
-		ID                     string   `json:"id"`
-		Name                   string   `json:"name"`
-		Summary                string   `json:"summary"`
-		Description            string   `json:"description"`
-		Tags                   []string `json:"tags"`
-		PrerequisiteIDs        []string `json:"prerequisiteIDs"`
-		DownstreamRequiredIDs  []string `json:"downstreamRequiredIDs"`
-		DownstreamSuggestedIDs []string `json:"downstreamSuggestedIDs"`
+		ID                     string                        `json:"id"`
+		ExpectedRevision       string                        `json:"expectedRevision"`
+		Replace                bool                          `json:"replace"`
+		DryRun                 bool                          `json:"dryRun"`
+		Name                   *string                       `json:"name"`
+		Kind                   *string                       `json:"kind"`
+		Status                 *types.NodeStatus             `json:"status"`
+		SupersededBy           *string                       `json:"supersededBy"`
+		Condition              *string                       `json:"condition"`
+		Summary                *string                       `json:"summary"`
+		Description            *string                       `json:"description"`
+		Tags                   *[]string                     `json:"tags"`
+		Attributes             map[string]any                `json:"attributes"`
+		AddTags                []string                      `json:"addTags"`
+		RemoveTags             []string                      `json:"removeTags"`
+		Edges                  map[string][]types.EdgeTarget `json:"edges"`
+		AddEdges               map[string][]types.EdgeTarget `json:"addEdges"`
+		RemoveEdges            map[string][]string           `json:"removeEdges"`
+		AllowDeprecatedTargets bool                          `json:"allowDeprecatedTargets"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -512,66 +982,108 @@ func (s *Server) handleUpdateTask(ctx context.Context, req *mcp.CallToolRequest)
 		}, nil
 	}
 
+	edgeIDs, edgeAttributes := types.SplitEdgeTargets(args.Edges)
+	addEdgeIDs, addEdgeAttributes := types.SplitEdgeTargets(args.AddEdges)
+	var argsErr error
+	for _, edges := range []map[string][]string{edgeIDs, addEdgeIDs, args.RemoveEdges} {
+		if err := s.validateEdges(edges); err != nil {
+			argsErr = fmt.Errorf("invalid edges: %w", err)
+			break
+		}
+	}
+	if argsErr == nil && args.Replace {
+		if len(args.AddTags) > 0 || len(args.RemoveTags) > 0 || len(args.AddEdges) > 0 || len(args.RemoveEdges) > 0 {
+			argsErr = fmt.Errorf("addTags, removeTags, addEdges and removeEdges cannot be combined with replace")
+		} else if args.Name == nil {
+			argsErr = fmt.Errorf("name is required when replace is true")
+		}
+	}
+	if argsErr != nil {
+		s.logger.Error("Rejected update_task arguments", zap.String("node_id", args.ID), zap.Error(argsErr))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: argsErr.Error(),
+				},
+			},
+		}, nil
+	}
+
 	s.logger.Info("Updating node",
 		zap.String("node_id", args.ID),
-		zap.String("node_name", args.Name),
-		zap.Strings("tags", args.Tags),
+		zap.Bool("replace", args.Replace),
+		zap.Bool("dry_run", args.DryRun),
 	)
 
-	// Get existing node to preserve CreatedAt timestamp
-	existingNode, err := s.taskManager.GetNode(args.ID)
+	var node *types.Node
+	var impact *graph_manager.ChangeImpact
+	var err error
+	opts := append(deprecatedTargetsOption(args.AllowDeprecatedTargets), graph_manager.WithExpectedRevision(args.ExpectedRevision))
+	if args.Replace {
+		node, err = s.replacementNode(args.ID, args.Name, args.Kind, args.Status, args.SupersededBy, args.Condition, args.Summary, args.Description, args.Tags, args.Attributes, edgeIDs, edgeAttributes)
+		if err == nil && args.DryRun {
+			impact, err = s.taskManager.PreviewUpdate(node, opts...)
+		} else if err == nil {
+			err = s.taskManager.UpdateNode(node, opts...)
+		}
+	} else {
+		patch := graph_manager.NodePatch{
+			Name:           args.Name,
+			Kind:           args.Kind,
+			Status:         args.Status,
+			SupersededBy:   args.SupersededBy,
+			Condition:      args.Condition,
+			Summary:        args.Summary,
+			Description:    args.Description,
+			Tags:           args.Tags,
+			AddTags:        args.AddTags,
+			RemoveTags:     args.RemoveTags,
+			Attributes:     args.Attributes,
+			Edges:          edgeIDs,
+			AddEdges:       addEdgeIDs,
+			RemoveEdges:    args.RemoveEdges,
+			EdgeAttributes: mergeEdgeAttributes(edgeAttributes, addEdgeAttributes),
+			UpdatedAt:      time.Now(),
+		}
+		if args.DryRun {
+			impact, err = s.taskManager.PreviewPatch(args.ID, patch, opts...)
+		} else {
+			node, err = s.taskManager.PatchNode(args.ID, patch, opts...)
+		}
+	}
 	if err != nil {
-		s.logger.Error("Failed to get existing node for update",
+		s.logger.Error("Failed to update node",
 			zap.String("node_id", args.ID),
 			zap.Error(err),
 		)
+		if result := s.conflictResult(err); result != nil {
+			return result, nil
+		}
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("failed to get existing node: %v", err),
+					Text: fmt.Sprintf("failed to update node: %v", err),
 				},
 			},
 		}, nil
 	}
 
-	node := &types.Node{
-		ID:          args.ID,
-		Name:        args.Name,
-		Summary:     args.Summary,
-		Description: args.Description,
-		Tags:        args.Tags,
-		EdgeIDs: map[string][]string{
-			"prerequisites":        args.PrerequisiteIDs,
-			"downstream_required":  args.DownstreamRequiredIDs,
-			"downstream_suggested": args.DownstreamSuggestedIDs,
-		},
-		CreatedAt: existingNode.CreatedAt,
-		UpdatedAt: time.Now(),
-	}
-
-	if err := s.taskManager.UpdateNode(node); err != nil {
-		s.logger.Error("Failed to update node",
-			zap.String("node_id", args.ID),
-			zap.String("node_name", args.Name),
-			zap.Error(err),
-		)
+	if impact != nil {
 		return &mcp.CallToolResult{
-			IsError: true,
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("failed to update node: %v", err),
+					Text: formatChangeImpactAsMarkdown(impact),
 				},
 			},
 		}, nil
 	}
 
 	// Persist changes to disk
-	nodesPath := filepath.Join(s.config.Directory, "nodes")
-	if err := s.taskManager.PersistToDir(nodesPath); err != nil {
+	if err := s.persister.Schedule(); err != nil {
 		s.logger.Error("Failed to persist node to disk",
 			zap.String("node_id", args.ID),
-			zap.String("path", nodesPath),
 			zap.Error(err),
 		)
 		return &mcp.CallToolResult{
@@ -584,23 +1096,79 @@ func (s *Server) handleUpdateTask(ctx context.Context, req *mcp.CallToolRequest)
 		}, nil
 	}
 
-	s.logger.Info("Successfully updated node", zap.String("node_id", args.ID), zap.String("node_name", args.Name))
+	s.logger.Info("Successfully updated node", zap.String("node_id", args.ID), zap.String("node_name", node.Name))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Node `%s` updated successfully\n\n%s", node.ID, formatNodeAsMarkdown(node, s.taskManager)),
+				Text: fmt.Sprintf("✓ Node `%s` updated successfully\n\n%s", node.ID, formatNodeAsMarkdown(node, s.mcpConfig().kindNaming(node.Kind), s.taskManager, nil)),
 			},
 		},
 	}, nil
 }
 
+// replacementNode builds the node replacing an existing node from the given
+// fields, keeping only its creation time. Omitted fields are left empty.
+func (s *Server) replacementNode(id string, name, kind *string, status *types.NodeStatus, supersededBy, condition, summary, description *string, tags *[]string, attributes map[string]any, edges map[string][]string, edgeAttributes map[string]map[string]types.EdgeAttributes) (*types.Node, error) {
+	// Get existing node to preserve CreatedAt timestamp
+	existingNode, err := s.taskManager.GetNode(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing node: %w", err)
+	}
+
+	node := &types.Node{
+		ID:             id,
+		EdgeIDs:        edges,
+		EdgeAttributes: edgeAttributes,
+		CreatedAt:      existingNode.CreatedAt,
+		UpdatedAt:      time.Now(),
+	}
+	if name != nil {
+		node.Name = *name
+	}
+	if kind != nil {
+		node.Kind = *kind
+	}
+	if status != nil {
+		node.Status = *status
+	}
+	if supersededBy != nil {
+		node.SupersededBy = *supersededBy
+	}
+	if condition != nil {
+		node.Condition = *condition
+	}
+	if summary != nil {
+		node.Summary = *summary
+	}
+	if description != nil {
+		node.Description = *description
+	}
+	if tags != nil {
+		node.Tags = *tags
+	}
+	for attributeName, value := range attributes {
+		// Null removes an attribute, which replace leaves out anyway
+		if value == nil {
+			continue
+		}
+		if node.Attributes == nil {
+			node.Attributes = make(map[string]any)
+		}
+		node.Attributes[attributeName] = value
+	}
+
+	return node, nil
+}
+
 // handleDeleteTask handles the delete_task tool
 func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Handling delete_task request")
 
 	var args struct {
-		ID string `json:"id"`
+		ID               string `json:"id"`
+		ExpectedRevision string `json:"expectedRevision"`
+		DryRun           bool   `json:"dryRun"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -615,10 +1183,38 @@ func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest)
 		}, nil
 	}
 
-	s.logger.Info("Deleting node", zap.String("node_id", args.ID))
+	s.logger.Info("Deleting node", zap.String("node_id", args.ID), zap.Bool("dry_run", args.DryRun))
+
+	if args.DryRun {
+		impact, err := s.taskManager.PreviewDelete(args.ID, graph_manager.WithExpectedRevision(args.ExpectedRevision))
+		if err != nil {
+			s.logger.Error("Failed to preview node deletion", zap.String("node_id", args.ID), zap.Error(err))
+			if result := s.conflictResult(err); result != nil {
+				return result, nil
+			}
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("failed to delete node: %v", err),
+					},
+				},
+			}, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: formatChangeImpactAsMarkdown(impact),
+				},
+			},
+		}, nil
+	}
 
-	if err := s.taskManager.DeleteNode(args.ID); err != nil {
+	if err := s.taskManager.DeleteNode(args.ID, graph_manager.WithExpectedRevision(args.ExpectedRevision)); err != nil {
 		s.logger.Error("Failed to delete node", zap.String("node_id", args.ID), zap.Error(err))
+		if result := s.conflictResult(err); result != nil {
+			return result, nil
+		}
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
@@ -630,11 +1226,9 @@ func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest)
 	}
 
 	// Persist changes to disk
-	nodesPath := filepath.Join(s.config.Directory, "nodes")
-	if err := s.taskManager.PersistToDir(nodesPath); err != nil {
+	if err := s.persister.Schedule(); err != nil {
 		s.logger.Error("Failed to persist node deletion to disk",
 			zap.String("node_id", args.ID),
-			zap.String("path", nodesPath),
 			zap.Error(err),
 		)
 		return &mcp.CallToolResult{
@@ -657,3 +1251,247 @@ func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest)
 		},
 	}, nil
 }
+
+// handlePreviewChange handles the preview_change tool by running the update or
+// delete tool as a dry run
+func (s *Server) handlePreviewChange(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Handling preview_change request")
+
+	var args map[string]json.RawMessage
+	var operation string
+	err := json.Unmarshal(req.Params.Arguments, &args)
+	if err == nil {
+		err = json.Unmarshal(args["operation"], &operation)
+	}
+	if err != nil {
+		s.logger.Error("Failed to parse preview_change arguments", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to parse arguments: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	handler := s.handleUpdateTask
+	switch operation {
+	case "update":
+	case "delete":
+		handler = s.handleDeleteTask
+	default:
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("operation must be 'update' or 'delete', got '%s'", operation),
+				},
+			},
+		}, nil
+	}
+
+	delete(args, "operation")
+	args["dryRun"] = json.RawMessage("true")
+	return callWithArguments(ctx, req, handler, args)
+}
+
+// callWithArguments calls a tool handler with the arguments of the request replaced
+func callWithArguments(ctx context.Context, req *mcp.CallToolRequest, handler mcp.ToolHandler, args map[string]json.RawMessage) (*mcp.CallToolResult, error) {
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	return handler(ctx, &mcp.CallToolRequest{
+		Session: req.Session,
+		Params: &mcp.CallToolParamsRaw{
+			Name:      req.Params.Name,
+			Arguments: arguments,
+		},
+		Extra: req.Extra,
+	})
+}
+
+// withDryRun returns a copy of a write tool's properties with the dryRun argument added
+func withDryRun(properties map[string]interface{}, singular string) map[string]interface{} {
+	withDryRun := make(map[string]interface{}, len(properties)+1)
+	for name, property := range properties {
+		withDryRun[name] = property
+	}
+	withDryRun["dryRun"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": fmt.Sprintf("Only report what would change, including the workflows that would lose steps, without changing the %s (default false)", singular),
+	}
+	return withDryRun
+}
+
+// conflictResult reports a concurrent modification together with the node's
+// current state, or returns nil if err is not a conflict
+func (s *Server) conflictResult(err error) *mcp.CallToolResult {
+	var conflict *graph_manager.ConflictError
+	if !errors.As(err, &conflict) {
+		return nil
+	}
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("conflict: %v. Nothing was changed. Current state:\n\n%s", err, formatNodeAsMarkdown(conflict.Current, s.mcpConfig().kindNaming(conflict.Current.Kind), s.taskManager, nil)),
+			},
+		},
+	}
+}
+
+// handleApplyChanges handles the apply_changes tool
+func (s *Server) handleApplyChanges(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Handling apply_changes request")
+
+	var args struct {
+		Operations []struct {
+			Op                     string                        `json:"op"`
+			ID                     string                        `json:"id"`
+			Name                   string                        `json:"name"`
+			Kind                   string                        `json:"kind"`
+			Status                 types.NodeStatus              `json:"status"`
+			SupersededBy           string                        `json:"supersededBy"`
+			Condition              string                        `json:"condition"`
+			Summary                string                        `json:"summary"`
+			Description            string                        `json:"description"`
+			Tags                   []string                      `json:"tags"`
+			Attributes             map[string]any                `json:"attributes"`
+			Edges                  map[string][]types.EdgeTarget `json:"edges"`
+			AllowDeprecatedTargets bool                          `json:"allowDeprecatedTargets"`
+			ExpectedRevision       string                        `json:"expectedRevision"`
+		} `json:"operations"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		s.logger.Error("Failed to parse apply_changes arguments", zap.Error(err))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to parse arguments: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Applying changes", zap.Int("operation_count", len(args.Operations)))
+
+	now := time.Now()
+	tx := s.taskManager.Begin()
+	var summary strings.Builder
+
+	for i, op := range args.Operations {
+		var err error
+		edgeIDs, edgeAttributes := types.SplitEdgeTargets(op.Edges)
+		switch graph_manager.OperationType(op.Op) {
+		case graph_manager.OperationAdd:
+			err = tx.AddNode(&types.Node{
+				ID:             op.ID,
+				Name:           op.Name,
+				Kind:           op.Kind,
+				Status:         op.Status,
+				SupersededBy:   op.SupersededBy,
+				Condition:      op.Condition,
+				Summary:        op.Summary,
+				Description:    op.Description,
+				Tags:           op.Tags,
+				Attributes:     op.Attributes,
+				EdgeIDs:        edgeIDs,
+				EdgeAttributes: edgeAttributes,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}, deprecatedTargetsOption(op.AllowDeprecatedTargets)...)
+		case graph_manager.OperationUpdate:
+			// Preserve the creation time when the node already exists
+			createdAt := now
+			if existingNode, getErr := s.taskManager.GetNode(op.ID); getErr == nil {
+				createdAt = existingNode.CreatedAt
+			}
+			err = tx.UpdateNode(&types.Node{
+				ID:             op.ID,
+				Name:           op.Name,
+				Kind:           op.Kind,
+				Status:         op.Status,
+				SupersededBy:   op.SupersededBy,
+				Condition:      op.Condition,
+				Summary:        op.Summary,
+				Description:    op.Description,
+				Tags:           op.Tags,
+				Attributes:     op.Attributes,
+				EdgeIDs:        edgeIDs,
+				EdgeAttributes: edgeAttributes,
+				CreatedAt:      createdAt,
+				UpdatedAt:      now,
+			}, append(deprecatedTargetsOption(op.AllowDeprecatedTargets), graph_manager.WithExpectedRevision(op.ExpectedRevision))...)
+		case graph_manager.OperationDelete:
+			err = tx.DeleteNode(op.ID, graph_manager.WithExpectedRevision(op.ExpectedRevision))
+		default:
+			err = fmt.Errorf("unknown operation %q", op.Op)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			s.logger.Error("Failed to stage operation",
+				zap.Int("index", i+1),
+				zap.String("operation", op.Op),
+				zap.String("node_id", op.ID),
+				zap.Error(err),
+			)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("operation %d (%s %s) is invalid: %v", i+1, op.Op, op.ID, err),
+					},
+				},
+			}, nil
+		}
+
+		summary.WriteString(fmt.Sprintf("%d. %s `%s`\n", i+1, op.Op, op.ID))
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Failed to apply changes", zap.Error(err))
+		if result := s.conflictResult(err); result != nil {
+			return result, nil
+		}
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("failed to apply changes, nothing was modified: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	// Persist changes to disk
+	if err := s.persister.Schedule(); err != nil {
+		s.logger.Error("Failed to persist changes to disk",
+			zap.Error(err),
+		)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("changes applied but failed to persist to disk: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	s.logger.Info("Successfully applied changes", zap.Int("operation_count", len(args.Operations)))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("✓ Applied %d change(s)\n\n%s", len(args.Operations), strings.TrimSpace(summary.String())),
+			},
+		},
+	}, nil
+}
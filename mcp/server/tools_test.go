@@ -0,0 +1,384 @@
+package server
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addTestNodes adds the nodes to the server's graph in order
+func addTestNodes(t *testing.T, s *Server, nodes ...*types.Node) {
+	t.Helper()
+
+	for _, node := range nodes {
+		if err := s.taskManager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+}
+
+// workflowTestNodes builds a deploy that depends on a build and relates to tests
+func workflowTestNodes() []*types.Node {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	return []*types.Node{
+		{ID: "build", Name: "Build"},
+		{ID: "test", Name: "Test"},
+		{
+			ID:          "deploy",
+			Name:        "Deploy",
+			Summary:     "Ship it",
+			Description: "Deploy to production",
+			Tags:        []string{"release", "production"},
+			EdgeIDs: map[string][]string{
+				"prerequisites": {"build"},
+				"related":       {"test"},
+			},
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}
+}
+
+// getTestNode returns a node of the server's graph, failing the test if it doesn't exist
+func getTestNode(t *testing.T, s *Server, id string) *types.Node {
+	t.Helper()
+
+	node, err := s.taskManager.GetNode(id)
+	if err != nil {
+		t.Fatalf("Failed to get node %s: %v", id, err)
+	}
+	return node
+}
+
+func TestUpdateTaskPatches(t *testing.T) {
+	s := newTestServer(t)
+	addTestNodes(t, s, workflowTestNodes()...)
+
+	// Fields that aren't sent are left alone
+	result := callTestTool(t, s.handleUpdateTask, map[string]any{"id": "deploy", "summary": "Ship the release"})
+	if result.IsError {
+		t.Fatalf("Failed to patch: %s", resultText(result))
+	}
+	node := getTestNode(t, s, "deploy")
+	if node.Summary != "Ship the release" {
+		t.Errorf("Expected the summary to change, got %q", node.Summary)
+	}
+	if node.Name != "Deploy" || node.Description != "Deploy to production" {
+		t.Errorf("Expected the name and description to be kept, got %q and %q", node.Name, node.Description)
+	}
+	if !slices.Equal(node.Tags, []string{"release", "production"}) {
+		t.Errorf("Expected the tags to be kept, got %v", node.Tags)
+	}
+	if !slices.Equal(node.GetEdgeIDs("prerequisites"), []string{"build"}) || !slices.Equal(node.GetEdgeIDs("related"), []string{"test"}) {
+		t.Errorf("Expected the edges to be kept, got %v", node.EdgeIDs)
+	}
+
+	// Empty values clear a field, and an empty array removes a relationship
+	result = callTestTool(t, s.handleUpdateTask, map[string]any{
+		"id":          "deploy",
+		"description": "",
+		"tags":        []string{},
+		"edges":       map[string]any{"related": []string{}},
+	})
+	if result.IsError {
+		t.Fatalf("Failed to patch: %s", resultText(result))
+	}
+	node = getTestNode(t, s, "deploy")
+	if node.Description != "" || len(node.Tags) != 0 {
+		t.Errorf("Expected the description and tags to be cleared, got %q and %v", node.Description, node.Tags)
+	}
+	if _, exists := node.EdgeIDs["related"]; exists {
+		t.Errorf("Expected the related edges to be removed, got %v", node.EdgeIDs)
+	}
+	if node.Summary != "Ship the release" || !slices.Equal(node.GetEdgeIDs("prerequisites"), []string{"build"}) {
+		t.Errorf("Expected the summary and prerequisites to be kept, got %q and %v", node.Summary, node.EdgeIDs)
+	}
+
+	// Individual tags and targets are added and removed
+	result = callTestTool(t, s.handleUpdateTask, map[string]any{
+		"id":          "deploy",
+		"addTags":     []string{"backend"},
+		"addEdges":    map[string]any{"prerequisites": []string{"test"}},
+		"removeEdges": map[string]any{"prerequisites": []string{"build"}},
+	})
+	if result.IsError {
+		t.Fatalf("Failed to patch: %s", resultText(result))
+	}
+	node = getTestNode(t, s, "deploy")
+	if !slices.Equal(node.Tags, []string{"backend"}) || !slices.Equal(node.GetEdgeIDs("prerequisites"), []string{"test"}) {
+		t.Errorf("Expected tags [backend] and prerequisites [test], got %v and %v", node.Tags, node.EdgeIDs)
+	}
+}
+
+func TestUpdateTaskReplaces(t *testing.T) {
+	s := newTestServer(t)
+	addTestNodes(t, s, workflowTestNodes()...)
+	created := getTestNode(t, s, "deploy").CreatedAt
+
+	for name, args := range map[string]map[string]any{
+		"without name":    {"id": "deploy", "replace": true, "summary": "Ship it"},
+		"with addTags":    {"id": "deploy", "replace": true, "name": "Deploy", "addTags": []string{"backend"}},
+		"with addEdges":   {"id": "deploy", "replace": true, "name": "Deploy", "addEdges": map[string]any{"related": []string{"build"}}},
+		"with removeTags": {"id": "deploy", "replace": true, "name": "Deploy", "removeTags": []string{"release"}},
+	} {
+		if result := callTestTool(t, s.handleUpdateTask, args); !result.IsError {
+			t.Errorf("%s: expected the replace to be rejected", name)
+		}
+	}
+	if node := getTestNode(t, s, "deploy"); node.Summary != "Ship it" {
+		t.Fatalf("Expected rejected replaces to leave the node alone, got summary %q", node.Summary)
+	}
+
+	// Omitted fields are left empty
+	result := callTestTool(t, s.handleUpdateTask, map[string]any{
+		"id":      "deploy",
+		"replace": true,
+		"name":    "Deploy v2",
+		"edges":   map[string]any{"related": []string{"build"}},
+	})
+	if result.IsError {
+		t.Fatalf("Failed to replace: %s", resultText(result))
+	}
+	node := getTestNode(t, s, "deploy")
+	if node.Name != "Deploy v2" {
+		t.Errorf("Expected name Deploy v2, got %q", node.Name)
+	}
+	if node.Summary != "" || node.Description != "" || len(node.Tags) != 0 {
+		t.Errorf("Expected the omitted fields to be cleared, got %q, %q and %v", node.Summary, node.Description, node.Tags)
+	}
+	if _, exists := node.EdgeIDs["prerequisites"]; exists || !slices.Equal(node.GetEdgeIDs("related"), []string{"build"}) {
+		t.Errorf("Expected only the related edge to build, got %v", node.EdgeIDs)
+	}
+	if !node.CreatedAt.Equal(created) {
+		t.Errorf("Expected the creation time to be kept, got %v", node.CreatedAt)
+	}
+	if !strings.Contains(resultText(result), "updated successfully") {
+		t.Errorf("Expected the updated node, got %s", resultText(result))
+	}
+}
+
+func TestUpdateTaskExpectedRevision(t *testing.T) {
+	s := newTestServer(t)
+	addTestNodes(t, s, workflowTestNodes()...)
+	stale := getTestNode(t, s, "deploy").Revision()
+
+	// Someone else changes the node after it was read
+	result := callTestTool(t, s.handleUpdateTask, map[string]any{"id": "deploy", "summary": "Ship the release"})
+	if result.IsError {
+		t.Fatalf("Failed to patch: %s", resultText(result))
+	}
+	current := getTestNode(t, s, "deploy").Revision()
+
+	result = callTestTool(t, s.handleUpdateTask, map[string]any{"id": "deploy", "summary": "Ship it now", "expectedRevision": stale})
+	if !result.IsError {
+		t.Fatal("Expected a stale revision to be refused")
+	}
+	text := resultText(result)
+	if !strings.HasPrefix(text, "conflict:") || !strings.Contains(text, "Nothing was changed") {
+		t.Errorf("Expected a conflict, got %s", text)
+	}
+	if !strings.Contains(text, "current revision "+current) || !strings.Contains(text, "Revision: `"+current+"`") {
+		t.Errorf("Expected the conflict to show the current revision %s, got %s", current, text)
+	}
+	if node := getTestNode(t, s, "deploy"); node.Summary != "Ship the release" {
+		t.Errorf("Expected the conflicting update not to be applied, got summary %q", node.Summary)
+	}
+
+	result = callTestTool(t, s.handleDeleteTask, map[string]any{"id": "deploy", "expectedRevision": stale})
+	if !result.IsError || !strings.Contains(resultText(result), "Revision: `"+current+"`") {
+		t.Errorf("Expected the delete to conflict with the current revision, got %s", resultText(result))
+	}
+
+	// The current revision lets the change through
+	result = callTestTool(t, s.handleUpdateTask, map[string]any{"id": "deploy", "summary": "Ship it now", "expectedRevision": current})
+	if result.IsError {
+		t.Fatalf("Expected the matching revision to be accepted, got %s", resultText(result))
+	}
+	if node := getTestNode(t, s, "deploy"); node.Summary != "Ship it now" {
+		t.Errorf("Expected the update to be applied, got summary %q", node.Summary)
+	}
+}
+
+func TestApplyChangesExpectedRevision(t *testing.T) {
+	s := newTestServer(t)
+	addTestNodes(t, s, workflowTestNodes()...)
+	stale := getTestNode(t, s, "deploy").Revision()
+
+	result := callTestTool(t, s.handleUpdateTask, map[string]any{"id": "deploy", "summary": "Ship the release"})
+	if result.IsError {
+		t.Fatalf("Failed to patch: %s", resultText(result))
+	}
+	current := getTestNode(t, s, "deploy").Revision()
+
+	for _, op := range []map[string]any{
+		{"op": "update", "id": "deploy", "name": "Deploy", "summary": "Ship it now", "expectedRevision": stale},
+		{"op": "delete", "id": "deploy", "expectedRevision": stale},
+	} {
+		result = callTestTool(t, s.handleApplyChanges, map[string]any{"operations": []any{
+			map[string]any{"op": "update", "id": "build", "name": "Build", "summary": "Compile"},
+			op,
+		}})
+		if !result.IsError {
+			t.Fatalf("Expected a stale %s to be refused", op["op"])
+		}
+		text := resultText(result)
+		if !strings.HasPrefix(text, "conflict:") || !strings.Contains(text, "Revision: `"+current+"`") {
+			t.Errorf("Expected a conflict showing the current revision %s, got %s", current, text)
+		}
+	}
+	if node := getTestNode(t, s, "build"); node.Summary != "" {
+		t.Errorf("Expected no operation of a conflicting batch to be applied, got summary %q", node.Summary)
+	}
+
+	result = callTestTool(t, s.handleApplyChanges, map[string]any{"operations": []any{
+		map[string]any{"op": "update", "id": "build", "name": "Build", "summary": "Compile"},
+		map[string]any{"op": "delete", "id": "deploy", "expectedRevision": current},
+	}})
+	if result.IsError {
+		t.Fatalf("Expected the matching revision to be accepted, got %s", resultText(result))
+	}
+	if _, err := s.taskManager.GetNode("deploy"); err == nil {
+		t.Error("Expected deploy to be deleted")
+	}
+}
+
+// graphRevisions returns the revision of every node in the graph and in the
+// store, keyed by where the node is held and its ID
+func graphRevisions(t *testing.T, s *Server) map[string]string {
+	t.Helper()
+
+	stored, err := s.taskManager.Store().LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to load stored nodes: %v", err)
+	}
+	revisions := make(map[string]string)
+	for _, node := range stored {
+		revisions["store/"+node.ID] = node.Revision()
+	}
+	for _, node := range s.taskManager.ListAllNodes() {
+		revisions["graph/"+node.ID] = node.Revision()
+	}
+	return revisions
+}
+
+func TestPreviewChange(t *testing.T) {
+	s := newTestServer(t)
+	addTestNodes(t, s, workflowTestNodes()...)
+	if err := s.persister.Flush(); err != nil {
+		t.Fatalf("Failed to write nodes: %v", err)
+	}
+	before := graphRevisions(t, s)
+
+	tests := []struct {
+		name    string
+		handler mcp.ToolHandler
+		args    map[string]any
+		want    []string
+	}{
+		{
+			name:    "preview delete",
+			handler: s.handlePreviewChange,
+			args:    map[string]any{"operation": "delete", "id": "build"},
+			want:    []string{"Deleting `build`", "- `deploy` prerequisites → `build`", "`deploy` would no longer reach through prerequisites: `build`"},
+		},
+		{
+			name:    "preview update",
+			handler: s.handlePreviewChange,
+			args:    map[string]any{"operation": "update", "id": "deploy", "removeEdges": map[string]any{"related": []string{"test"}}},
+			want:    []string{"Updating `deploy`", "**Edges removed** (1)", "- `deploy` related → `test`"},
+		},
+		{
+			name:    "update dry run",
+			handler: s.handleUpdateTask,
+			args:    map[string]any{"id": "deploy", "replace": true, "name": "Deploy", "dryRun": true},
+			want:    []string{"Updating `deploy`", "**Edges removed** (2)"},
+		},
+		{
+			name:    "delete dry run",
+			handler: s.handleDeleteTask,
+			args:    map[string]any{"id": "deploy", "dryRun": true},
+			want:    []string{"Deleting `deploy`", "**Edges removed** (2)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTestTool(t, tt.handler, tt.args)
+			text := resultText(result)
+			if result.IsError {
+				t.Fatalf("Preview failed: %s", text)
+			}
+			if !strings.Contains(text, "Nothing was changed") {
+				t.Errorf("Expected a dry run, got %s", text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("Expected the preview to contain %q, got %s", want, text)
+				}
+			}
+			if after := graphRevisions(t, s); !maps.Equal(before, after) {
+				t.Errorf("Expected the graph and store to be unchanged, had %v, got %v", before, after)
+			}
+		})
+	}
+
+	if result := callTestTool(t, s.handlePreviewChange, map[string]any{"operation": "add", "id": "deploy"}); !result.IsError {
+		t.Error("Expected an unknown operation to be rejected")
+	}
+}
+
+func TestPreviewChangeReportsErrors(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.taskManager.RegisterRelationship(types.Relationship{
+		Name:        "approved_by",
+		Direction:   types.DirectionNone,
+		Constraints: types.RelationshipConstraints{RequiredForTags: []string{"production"}},
+	}); err != nil {
+		t.Fatalf("Failed to register relationship: %v", err)
+	}
+	nodes := append([]*types.Node{{ID: "lead", Name: "Lead"}}, workflowTestNodes()...)
+	nodes[3].EdgeIDs["approved_by"] = []string{"lead"}
+	addTestNodes(t, s, nodes...)
+	if err := s.persister.Flush(); err != nil {
+		t.Fatalf("Failed to write nodes: %v", err)
+	}
+	before := graphRevisions(t, s)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{
+			name: "constraint broken by delete",
+			args: map[string]any{"operation": "delete", "id": "lead"},
+			want: "nodes tagged production must have at least one approved_by target",
+		},
+		{
+			name: "constraint broken by update",
+			args: map[string]any{"operation": "update", "id": "deploy", "edges": map[string]any{"approved_by": []string{}}},
+			want: "nodes tagged production must have at least one approved_by target",
+		},
+		{
+			name: "cycle",
+			args: map[string]any{"operation": "update", "id": "build", "edges": map[string]any{"prerequisites": []string{"deploy"}}},
+			want: "cycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTestTool(t, s.handlePreviewChange, tt.args)
+			if !result.IsError || !strings.Contains(resultText(result), tt.want) {
+				t.Errorf("Expected the preview to report %q, got %s", tt.want, resultText(result))
+			}
+			if after := graphRevisions(t, s); !maps.Equal(before, after) {
+				t.Errorf("Expected the graph and store to be unchanged, had %v, got %v", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// SetAttributeDefinitions replaces the attributes nodes may carry. Writes are
+// rejected when a node lacks a required attribute, has a value the definition
+// doesn't allow or has an attribute that isn't defined. Stored nodes are not
+// checked; Lint reports the ones that break the new definitions.
+func (m *Manager) SetAttributeDefinitions(definitions []types.AttributeDefinition) error {
+	attributes := make(map[string]*types.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		if err := definition.Validate(); err != nil {
+			m.logger.Error("Invalid attribute definition", zap.String("name", definition.Name), zap.Error(err))
+			return fmt.Errorf("invalid attribute %s: %w", definition.Name, err)
+		}
+		if _, exists := attributes[definition.Name]; exists {
+			return fmt.Errorf("attribute %s defined more than once", definition.Name)
+		}
+		definition := definition
+		attributes[definition.Name] = &definition
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.attributes = attributes
+	m.logger.Info("Set attribute definitions", zap.Int("count", len(attributes)))
+
+	return nil
+}
+
+// GetAttributeDefinitions returns the attributes nodes may carry, ordered by name
+func (m *Manager) GetAttributeDefinitions() []types.AttributeDefinition {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	definitions := make([]types.AttributeDefinition, 0, len(m.attributes))
+	for _, definition := range m.attributes {
+		definitions = append(definitions, *definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// withAttributeDefaults returns the node with the defaults of the attributes it
+// lacks set, copying it if any are missing.
+// The caller must hold the lock.
+func (m *Manager) withAttributeDefaults(node *types.Node) *types.Node {
+	var withDefaults *types.Node
+	for _, name := range m.sortedAttributeNames() {
+		definition := m.attributes[name]
+		if definition.Default == nil {
+			continue
+		}
+		if _, exists := node.Attributes[name]; exists {
+			continue
+		}
+		if withDefaults == nil {
+			withDefaults = node.Clone()
+			if withDefaults.Attributes == nil {
+				withDefaults.Attributes = make(map[string]any)
+			}
+		}
+		withDefaults.Attributes[name] = definition.Default
+	}
+	if withDefaults == nil {
+		return node
+	}
+	return withDefaults
+}
+
+// attributeViolations checks a node's attributes against their definitions.
+// The caller must hold the lock.
+func (m *Manager) attributeViolations(node *types.Node) []ConstraintViolation {
+	var violations []ConstraintViolation
+	violate := func(format string, args ...any) {
+		violations = append(violations, ConstraintViolation{
+			NodeID:     node.ID,
+			Constraint: ConstraintAttributes,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, name := range m.sortedAttributeNames() {
+		value, exists := node.Attributes[name]
+		if !exists {
+			if m.attributes[name].Required {
+				violate("attribute %s is required", name)
+			}
+			continue
+		}
+		if err := m.attributes[name].Check(value); err != nil {
+			violate("attribute %s: %v", name, err)
+		}
+	}
+
+	names := make(map[string]bool, len(node.Attributes))
+	for name := range node.Attributes {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		if _, defined := m.attributes[name]; !defined {
+			violate("unknown attribute %s", name)
+		}
+	}
+
+	return violations
+}
+
+// sortedAttributeNames returns the names of the defined attributes in sorted order.
+// The caller must hold the lock.
+func (m *Manager) sortedAttributeNames() []string {
+	names := make(map[string]bool, len(m.attributes))
+	for name := range m.attributes {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
@@ -0,0 +1,192 @@
+package graph_manager
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// recipeAttributes are the attributes of recipes
+var recipeAttributes = []types.AttributeDefinition{
+	{Name: "prep_time", Type: types.AttributeInteger, Required: true},
+	{Name: "difficulty", Type: types.AttributeString, Enum: []any{"easy", "hard"}, Default: "easy"},
+	{Name: "owner", Type: types.AttributeString, Pattern: "^@[a-z-]+$"},
+	{Name: "rating", Type: types.AttributeNumber},
+	{Name: "vegetarian", Type: types.AttributeBoolean},
+}
+
+// attributeMessages returns the messages of the violations in err
+func attributeMessages(err error) []string {
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) {
+		return nil
+	}
+	var messages []string
+	for _, violation := range constraintErr.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return messages
+}
+
+func TestSetAttributeDefinitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition types.AttributeDefinition
+		wantErr    bool
+	}{
+		{"valid", types.AttributeDefinition{Name: "owner", Type: types.AttributeString, Pattern: "^@"}, false},
+		{"missing name", types.AttributeDefinition{Type: types.AttributeString}, true},
+		{"unknown type", types.AttributeDefinition{Name: "owner", Type: "date"}, true},
+		{"pattern on integer", types.AttributeDefinition{Name: "prep_time", Type: types.AttributeInteger, Pattern: "^1"}, true},
+		{"invalid pattern", types.AttributeDefinition{Name: "owner", Type: types.AttributeString, Pattern: "("}, true},
+		{"enum value of wrong type", types.AttributeDefinition{Name: "prep_time", Type: types.AttributeInteger, Enum: []any{"ten"}}, true},
+		{"default outside enum", types.AttributeDefinition{Name: "size", Type: types.AttributeString, Enum: []any{"s"}, Default: "m"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := logger.New(false)
+			manager := NewManager(log)
+			err := manager.SetAttributeDefinitions([]types.AttributeDefinition{tt.definition})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetAttributeDefinitions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	duplicate := types.AttributeDefinition{Name: "owner", Type: types.AttributeString}
+	if err := manager.SetAttributeDefinitions([]types.AttributeDefinition{duplicate, duplicate}); err == nil {
+		t.Error("Expected a duplicate attribute to be rejected")
+	}
+}
+
+func TestAttributeValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]any
+		want       []string
+	}{
+		{
+			name:       "valid",
+			attributes: map[string]any{"prep_time": 10, "owner": "@kitchen", "rating": 4.5, "vegetarian": true},
+		},
+		{
+			name:       "whole number decoded from JSON",
+			attributes: map[string]any{"prep_time": float64(10)},
+		},
+		{
+			name: "missing required attribute",
+			want: []string{"attribute prep_time is required"},
+		},
+		{
+			name:       "wrong type",
+			attributes: map[string]any{"prep_time": 10.5, "vegetarian": "yes"},
+			want:       []string{"attribute prep_time: 10.5 is not of type integer", "attribute vegetarian: yes is not of type boolean"},
+		},
+		{
+			name:       "not in enum",
+			attributes: map[string]any{"prep_time": 10, "difficulty": "medium"},
+			want:       []string{"attribute difficulty: medium is not one of [easy hard]"},
+		},
+		{
+			name:       "pattern mismatch",
+			attributes: map[string]any{"prep_time": 10, "owner": "kitchen"},
+			want:       []string{`attribute owner: "kitchen" does not match ^@[a-z-]+$`},
+		},
+		{
+			name:       "unknown attribute",
+			attributes: map[string]any{"prep_time": 10, "servings": 4},
+			want:       []string{"unknown attribute servings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			if err := manager.SetAttributeDefinitions(recipeAttributes); err != nil {
+				t.Fatalf("Failed to set attribute definitions: %v", err)
+			}
+			err := manager.AddNode(&types.Node{ID: "pasta", Attributes: tt.attributes})
+			if got := attributeMessages(err); !slices.Equal(got, tt.want) {
+				t.Errorf("AddNode() violations = %q, want %q (error: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAttributeDefaultsAndPatches(t *testing.T) {
+	manager := newTestManager(t)
+	if err := manager.SetAttributeDefinitions(recipeAttributes); err != nil {
+		t.Fatalf("Failed to set attribute definitions: %v", err)
+	}
+
+	// Defaults are set on the stored copy of added nodes
+	added := &types.Node{ID: "pasta", Attributes: map[string]any{"prep_time": 10}}
+	if err := manager.AddNode(added); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if _, exists := added.Attributes["difficulty"]; exists {
+		t.Error("AddNode() should not modify the node passed in")
+	}
+	stored, _ := manager.GetNode("pasta")
+	if stored.Attributes["difficulty"] != "easy" {
+		t.Errorf("Expected default difficulty easy, got %v", stored.Attributes["difficulty"])
+	}
+
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "salad", Attributes: map[string]any{"prep_time": 5}}); err != nil {
+		t.Fatalf("Failed to stage node: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if salad, _ := manager.GetNode("salad"); salad.Attributes["difficulty"] != "easy" {
+		t.Errorf("Expected transaction to set default difficulty, got %v", salad.Attributes["difficulty"])
+	}
+
+	// Updates that leave out a defaulted attribute get its default too
+	if err := manager.UpdateNode(&types.Node{ID: "pasta", Attributes: map[string]any{"prep_time": 12, "difficulty": "hard"}}); err != nil {
+		t.Fatalf("UpdateNode() error = %v", err)
+	}
+	if err := manager.UpdateNode(&types.Node{ID: "pasta", Attributes: map[string]any{"prep_time": 10}}); err != nil {
+		t.Fatalf("UpdateNode() error = %v", err)
+	}
+	if pasta, _ := manager.GetNode("pasta"); pasta.Attributes["difficulty"] != "easy" {
+		t.Errorf("Expected update to set default difficulty, got %v", pasta.Attributes["difficulty"])
+	}
+
+	tx = manager.Begin()
+	if err := tx.UpdateNode(&types.Node{ID: "salad", Attributes: map[string]any{"prep_time": 7}}); err != nil {
+		t.Fatalf("Failed to stage update: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if salad, _ := manager.GetNode("salad"); salad.Attributes["difficulty"] != "easy" {
+		t.Errorf("Expected transaction update to set default difficulty, got %v", salad.Attributes["difficulty"])
+	}
+
+	// Patches set attributes, and nil removes them or resets them to their default
+	patched, err := manager.PatchNode("pasta", NodePatch{Attributes: map[string]any{"owner": "@kitchen", "difficulty": nil, "rating": 4.5}})
+	if err != nil {
+		t.Fatalf("PatchNode() error = %v", err)
+	}
+	patched, err = manager.PatchNode("pasta", NodePatch{Attributes: map[string]any{"rating": nil}})
+	if err != nil {
+		t.Fatalf("PatchNode() error = %v", err)
+	}
+	want := map[string]any{"prep_time": 10, "owner": "@kitchen", "difficulty": "easy"}
+	if len(patched.Attributes) != len(want) || patched.Attributes["owner"] != "@kitchen" || patched.Attributes["prep_time"] != 10 || patched.Attributes["difficulty"] != "easy" {
+		t.Errorf("Patched attributes = %v, want %v", patched.Attributes, want)
+	}
+
+	_, err = manager.PatchNode("pasta", NodePatch{Attributes: map[string]any{"prep_time": nil}})
+	if got := attributeMessages(err); !slices.Equal(got, []string{"attribute prep_time is required"}) {
+		t.Errorf("PatchNode() violations = %q (error: %v)", got, err)
+	}
+}
@@ -0,0 +1,172 @@
+package graph_manager
+
+import (
+	"fmt"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// ExcludedEdge is an edge left out of a query because a condition doesn't
+// hold in the request context: the edge's own condition, or that of the node
+// it leads to
+type ExcludedEdge struct {
+	From         string
+	Relationship string
+	To           string
+
+	// Reason explains which condition doesn't hold and the facts it was
+	// evaluated against
+	Reason string
+}
+
+// String formats the excluded edge for display
+func (e ExcludedEdge) String() string {
+	return fmt.Sprintf("%s -%s-> %s: %s", e.From, e.Relationship, e.To, e.Reason)
+}
+
+// QueryOption configures a single traversal, plan or lookup
+type QueryOption func(*queryOptions)
+
+// queryOptions holds the settings applied by QueryOptions
+type queryOptions struct {
+	facts    types.Facts
+	excluded func(ExcludedEdge)
+}
+
+// WithFacts evaluates the conditions of edges and nodes against the facts of
+// the request context, leaving out the edges whose conditions don't hold and
+// the edges leading to nodes whose conditions don't hold. Without facts, or
+// with nil facts, conditions are ignored and every edge is followed.
+func WithFacts(facts types.Facts) QueryOption {
+	return func(o *queryOptions) {
+		o.facts = facts
+	}
+}
+
+// ReportExcluded calls report for each edge a traversal or plan leaves out
+// because of WithFacts
+func ReportExcluded(report func(ExcludedEdge)) QueryOption {
+	return func(o *queryOptions) {
+		o.excluded = report
+	}
+}
+
+// newQueryOptions applies the options to the defaults
+func newQueryOptions(opts []QueryOption) queryOptions {
+	var options queryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// follows reports whether the edge from source to target through the
+// relationship is followed, reaching the node reached, which is the target
+// when following edges and the source when going against them. Edges left out
+// are reported to ReportExcluded.
+func (o queryOptions) follows(source *types.Node, relationshipName string, target, reached *types.Node) bool {
+	excluded, isExcluded := edgeExclusion(source, relationshipName, target, reached, o.facts)
+	if isExcluded && o.excluded != nil {
+		o.excluded(excluded)
+	}
+	return !isExcluded
+}
+
+// edgeExclusion checks the condition of an edge and of the node it reaches
+// against the facts. It returns the exclusion if either doesn't hold, and
+// never excludes an edge when facts is nil.
+func edgeExclusion(source *types.Node, relationshipName string, target, reached *types.Node, facts types.Facts) (ExcludedEdge, bool) {
+	if facts == nil {
+		return ExcludedEdge{}, false
+	}
+
+	excluded := ExcludedEdge{From: source.ID, Relationship: relationshipName, To: target.ID}
+	if condition := source.GetEdgeAttributes(relationshipName, target.ID).Condition; condition != "" {
+		if holds, explanation := types.EvaluateCondition(condition, facts); !holds {
+			excluded.Reason = conditionReason(fmt.Sprintf("the edge applies when `%s`", condition), explanation)
+			return excluded, true
+		}
+	}
+	if reached.Condition != "" {
+		if holds, explanation := types.EvaluateCondition(reached.Condition, facts); !holds {
+			excluded.Reason = conditionReason(fmt.Sprintf("%s applies when `%s`", reached.ID, reached.Condition), explanation)
+			return excluded, true
+		}
+	}
+	return ExcludedEdge{}, false
+}
+
+// conditionReason joins a condition and the explanation of its evaluation
+func conditionReason(condition, explanation string) string {
+	if explanation == "" {
+		return condition + ", which does not hold"
+	}
+	return fmt.Sprintf("%s, but %s", condition, explanation)
+}
+
+// ExcludedEdges returns the edges of a node that WithFacts leaves out, ordered
+// by relationship. Edges to nodes that don't exist are not included.
+func (m *Manager) ExcludedEdges(id string, opts ...QueryOption) ([]ExcludedEdge, error) {
+	options := newQueryOptions(opts)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, exists := m.nodes[id]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	var excluded []ExcludedEdge
+	for _, relationshipName := range sortedEdgeNames(node.EdgeIDs) {
+		for _, targetID := range node.EdgeIDs[relationshipName] {
+			target, exists := m.nodes[targetID]
+			if !exists {
+				continue
+			}
+			if exclusion, isExcluded := edgeExclusion(node, relationshipName, target, target, options.facts); isExcluded {
+				excluded = append(excluded, exclusion)
+			}
+		}
+	}
+
+	m.logger.Debug("Evaluated edge conditions",
+		zap.String("node_id", id),
+		zap.Int("excluded", len(excluded)),
+	)
+
+	return excluded, nil
+}
+
+// conditionViolations checks that the conditions of a node and its edges parse
+func conditionViolations(node *types.Node) []ConstraintViolation {
+	var violations []ConstraintViolation
+	if node.Condition != "" {
+		if _, err := types.ParseCondition(node.Condition); err != nil {
+			violations = append(violations, ConstraintViolation{
+				NodeID:     node.ID,
+				Constraint: ConstraintCondition,
+				Message:    fmt.Sprintf("invalid condition: %v", err),
+			})
+		}
+	}
+	for _, relationshipName := range sortedEdgeNames(node.EdgeIDs) {
+		for _, targetID := range node.EdgeIDs[relationshipName] {
+			condition := node.GetEdgeAttributes(relationshipName, targetID).Condition
+			if condition == "" {
+				continue
+			}
+			if _, err := types.ParseCondition(condition); err != nil {
+				violations = append(violations, ConstraintViolation{
+					NodeID:       node.ID,
+					Relationship: relationshipName,
+					Constraint:   ConstraintCondition,
+					Message:      fmt.Sprintf("invalid condition on %s edge to %s: %v", relationshipName, targetID, err),
+				})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,133 @@
+package graph_manager
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// conditionTestNodes builds a deploy whose migrate prerequisite only applies
+// to database changes and whose page-oncall follow-up only applies to
+// production
+func conditionTestNodes() []*types.Node {
+	return []*types.Node{
+		{ID: "build", Name: "Build"},
+		{ID: "migrate", Name: "Migrate"},
+		{ID: "page-oncall", Name: "Page on-call", Condition: `environment == "prod"`},
+		{
+			ID:   "deploy",
+			Name: "Deploy",
+			EdgeIDs: map[string][]string{
+				"prerequisites":       {"build", "migrate"},
+				"downstream_required": {"page-oncall"},
+			},
+			EdgeAttributes: map[string]map[string]types.EdgeAttributes{
+				"prerequisites": {"migrate": {Condition: `changed_paths contains "db/"`}},
+			},
+		},
+	}
+}
+
+func TestConditionalTraversal(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, conditionTestNodes()...)
+	staging := types.Facts{"environment": "staging", "changed_paths": []string{"api/handler.go"}}
+
+	// Without facts every edge is followed
+	results, err := manager.Descendants("deploy", "prerequisites", 0)
+	if err != nil {
+		t.Fatalf("Failed to traverse: %v", err)
+	}
+	if ids := traversalIDs(results); !slices.Equal(ids, []string{"build@1", "migrate@1"}) {
+		t.Errorf("Expected every prerequisite without facts, got %v", ids)
+	}
+
+	var excluded []ExcludedEdge
+	results, err = manager.Descendants("deploy", "prerequisites", 0, WithFacts(staging), ReportExcluded(func(edge ExcludedEdge) {
+		excluded = append(excluded, edge)
+	}))
+	if err != nil {
+		t.Fatalf("Failed to traverse: %v", err)
+	}
+	if ids := traversalIDs(results); !slices.Equal(ids, []string{"build@1"}) {
+		t.Errorf("Expected only build, got %v", ids)
+	}
+	if len(excluded) != 1 || excluded[0].To != "migrate" || !strings.Contains(excluded[0].Reason, `changed_paths is ["api/handler.go"]`) {
+		t.Errorf("Expected the migrate edge to be reported with its facts, got %v", excluded)
+	}
+
+	// Going against the edges checks the same edge conditions
+	results, err = manager.Ancestors("migrate", "prerequisites", 0, WithFacts(staging))
+	if err != nil {
+		t.Fatalf("Failed to traverse: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no ancestors of migrate for API changes, got %v", traversalIDs(results))
+	}
+	results, err = manager.Ancestors("migrate", "prerequisites", 0, WithFacts(types.Facts{"changed_paths": []string{"db/schema.sql"}}))
+	if err != nil {
+		t.Fatalf("Failed to traverse: %v", err)
+	}
+	if ids := traversalIDs(results); !slices.Equal(ids, []string{"deploy@1"}) {
+		t.Errorf("Expected deploy as the ancestor of migrate for database changes, got %v", ids)
+	}
+}
+
+func TestConditionalExecutionPlan(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, conditionTestNodes()...)
+
+	plan, err := manager.ExecutionPlan("deploy", WithFacts(types.Facts{"environment": "staging"}))
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	if levels := planLevelIDs(plan); len(levels) != 2 || !slices.Equal(levels[0], []string{"build"}) || !slices.Equal(levels[1], []string{"deploy"}) {
+		t.Errorf("Expected build then deploy, got %v", levels)
+	}
+	if len(plan.Excluded) != 2 || plan.Excluded[0].To != "page-oncall" || plan.Excluded[1].To != "migrate" {
+		t.Fatalf("Expected page-oncall and migrate to be excluded, got %v", plan.Excluded)
+	}
+	if reason := plan.Excluded[0].Reason; !strings.Contains(reason, "page-oncall applies when") || !strings.Contains(reason, `environment is "staging"`) {
+		t.Errorf("Expected the node condition in the reason, got %q", reason)
+	}
+
+	plan, err = manager.ExecutionPlan("deploy", WithFacts(types.Facts{"environment": "prod", "changed_paths": []string{"db/schema.sql"}}))
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	if len(plan.Steps()) != 4 || len(plan.Excluded) != 0 {
+		t.Errorf("Expected every step in production with database changes, got %v excluding %v", planLevelIDs(plan), plan.Excluded)
+	}
+
+	excluded, err := manager.ExcludedEdges("deploy", WithFacts(types.Facts{"environment": "prod"}))
+	if err != nil {
+		t.Fatalf("Failed to evaluate edges: %v", err)
+	}
+	if len(excluded) != 1 || excluded[0].Relationship != "prerequisites" || excluded[0].To != "migrate" {
+		t.Errorf("Expected only the migrate edge to be excluded, got %v", excluded)
+	}
+	if excluded, _ := manager.ExcludedEdges("deploy"); len(excluded) != 0 {
+		t.Errorf("Expected no exclusions without facts, got %v", excluded)
+	}
+}
+
+func TestInvalidConditionsRejected(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, conditionTestNodes()...)
+
+	err := manager.AddNode(&types.Node{ID: "broken", Name: "Broken", Condition: `environment ==`})
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) || constraintErr.Violations[0].Constraint != ConstraintCondition {
+		t.Errorf("Expected a condition violation for the node, got %v", err)
+	}
+
+	_, err = manager.PatchNode("deploy", NodePatch{
+		EdgeAttributes: map[string]map[string]types.EdgeAttributes{"prerequisites": {"build": {Condition: `"go" in`}}},
+	})
+	if !errors.As(err, &constraintErr) || constraintErr.Violations[0].Relationship != "prerequisites" {
+		t.Errorf("Expected a condition violation for the edge, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package graph_manager
+
+import (
+	"fmt"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// Names of the constraints, as written in relationships.yaml and in the node
+// kinds and attributes of mcp.yaml
+const (
+	ConstraintMinTargets       = "min_targets"
+	ConstraintMaxTargets       = "max_targets"
+	ConstraintRequiredForTags  = "required_for_tags"
+	ConstraintNoSelfReferences = "no_self_references"
+	ConstraintUniqueTargets    = "unique_targets"
+	ConstraintSourceKinds      = "source_kinds"
+	ConstraintTargetKinds      = "target_kinds"
+
+	// Constraints of node kinds
+	ConstraintKind           = "kind"
+	ConstraintRequiredFields = "required_fields"
+	ConstraintRelationships  = "relationships"
+
+	// Constraint of the attribute definitions
+	ConstraintAttributes = "attributes"
+
+	// Conditions of nodes and edges must parse
+	ConstraintCondition = "condition"
+
+	// Lifecycle states: the status and replacement of a node, and new edges
+	// pointing at deprecated nodes
+	ConstraintStatus            = "status"
+	ConstraintDeprecatedTargets = "deprecated_targets"
+)
+
+// ConstraintViolation is a node breaking a constraint of its kind, its
+// attributes or a relationship. Relationship is empty for constraints on the
+// node as a whole.
+type ConstraintViolation struct {
+	NodeID       string
+	Relationship string
+	Constraint   string
+	Message      string
+}
+
+// String formats the violation for display
+func (v ConstraintViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.NodeID, v.Message)
+}
+
+// ConstraintError reports the constraints a change would violate.
+// Use errors.As to inspect the violations.
+type ConstraintError struct {
+	Violations []ConstraintViolation
+}
+
+// Error implements the error interface
+func (e *ConstraintError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		parts[i] = violation.String()
+	}
+	return fmt.Sprintf("constraints violated: %s", strings.Join(parts, "; "))
+}
+
+// checkConstraints returns the constraint violations of the given changed
+// nodes, or nil if there are none. Besides their own edges, the edges of other
+// nodes pointing at them are checked against their kinds, which may have changed.
+// The caller must hold the write lock.
+func (m *Manager) checkConstraints(nodes ...*types.Node) error {
+	lookup := m.lookupWith(nodes...)
+	checked := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		checked[node.ID] = true
+	}
+
+	var violations []ConstraintViolation
+	for _, node := range nodes {
+		violations = append(violations, m.constraintViolations(node, lookup)...)
+	}
+	incoming := m.incomingIndex()
+	for _, node := range nodes {
+		for _, relationshipName := range sortedEdgeNames(incoming[node.ID]) {
+			rel, exists := m.relationshipTypes[relationshipName]
+			if !exists || rel.Constraints.AllowsTarget(node.Kind) {
+				continue
+			}
+			for _, sourceID := range incoming[node.ID][relationshipName] {
+				if checked[sourceID] {
+					continue // Its own edges were checked above
+				}
+				violations = append(violations, targetKindViolation(sourceID, relationshipName, node))
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ConstraintError{Violations: violations}
+}
+
+// checkReferrerConstraints returns a ConstraintError if nodes cleaned of their
+// edges to a deleted node break constraints they met before, such as
+// min_targets or required_for_tags. Problems they already had don't block the
+// delete, as the nodes aren't otherwise changed.
+// The caller must hold the lock.
+func (m *Manager) checkReferrerConstraints(referrers []nodeReplacement, lookup func(id string) *types.Node) error {
+	var violations []ConstraintViolation
+	for _, referrer := range referrers {
+		had := make(map[string]bool)
+		for _, violation := range m.constraintViolations(referrer.old, lookup) {
+			had[violation.Relationship+"."+violation.Constraint] = true
+		}
+		for _, violation := range m.constraintViolations(referrer.new, lookup) {
+			if !had[violation.Relationship+"."+violation.Constraint] {
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ConstraintError{Violations: violations}
+}
+
+// constraintViolations checks a node against the constraints of its kind and
+// attributes, the syntax of its conditions and its lifecycle status, and its
+// edges against the constraints of every registered relationship, resolving
+// edge targets through lookup. Violations are ordered by relationship.
+// The caller must hold the lock.
+func (m *Manager) constraintViolations(node *types.Node, lookup func(id string) *types.Node) []ConstraintViolation {
+	var violations []ConstraintViolation
+	violate := func(relationshipName, constraint, format string, args ...any) {
+		violations = append(violations, ConstraintViolation{
+			NodeID:       node.ID,
+			Relationship: relationshipName,
+			Constraint:   constraint,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	if node.Kind != "" {
+		if kind, exists := m.nodeKinds[node.Kind]; !exists {
+			violate("", ConstraintKind, "unknown kind %s", node.Kind)
+		} else {
+			if missing := kind.MissingFields(node); len(missing) > 0 {
+				violate("", ConstraintRequiredFields, "%s nodes require %s", node.Kind, strings.Join(missing, ", "))
+			}
+			for _, relationshipName := range sortedEdgeNames(node.EdgeIDs) {
+				if len(node.EdgeIDs[relationshipName]) > 0 && !kind.AllowsRelationship(relationshipName) {
+					violate(relationshipName, ConstraintRelationships, "%s nodes cannot have %s edges", node.Kind, relationshipName)
+				}
+			}
+		}
+	}
+
+	violations = append(violations, m.attributeViolations(node)...)
+	violations = append(violations, conditionViolations(node)...)
+	violations = append(violations, statusViolations(node, lookup)...)
+
+	for _, relationshipName := range m.sortedRelationshipNames() {
+		constraints := m.relationshipTypes[relationshipName].Constraints
+		targetIDs := node.EdgeIDs[relationshipName]
+
+		var requiredBy string
+		for _, tag := range constraints.RequiredForTags {
+			if containsString(node.Tags, tag) {
+				requiredBy = tag
+				break
+			}
+		}
+		if len(targetIDs) == 0 {
+			if requiredBy != "" {
+				violate(relationshipName, ConstraintRequiredForTags,
+					"nodes tagged %s must have at least one %s target", requiredBy, relationshipName)
+			}
+			continue
+		}
+
+		if len(targetIDs) < constraints.MinTargets {
+			violate(relationshipName, ConstraintMinTargets,
+				"%s has %d target(s), at least %d required", relationshipName, len(targetIDs), constraints.MinTargets)
+		}
+		if constraints.MaxTargets > 0 && len(targetIDs) > constraints.MaxTargets {
+			violate(relationshipName, ConstraintMaxTargets,
+				"%s has %d targets, at most %d allowed", relationshipName, len(targetIDs), constraints.MaxTargets)
+		}
+		if constraints.NoSelfReferences && containsString(targetIDs, node.ID) {
+			violate(relationshipName, ConstraintNoSelfReferences,
+				"%s cannot reference the node itself", relationshipName)
+		}
+		if !constraints.AllowsSource(node.Kind) {
+			violate(relationshipName, ConstraintSourceKinds,
+				"%s nodes cannot have %s edges", types.KindName(node.Kind), relationshipName)
+		}
+		for _, targetID := range targetIDs {
+			if target := lookup(targetID); target != nil && !constraints.AllowsTarget(target.Kind) {
+				violations = append(violations, targetKindViolation(node.ID, relationshipName, target))
+			}
+		}
+		if constraints.UniqueTargets {
+			seen := make(map[string]bool, len(targetIDs))
+			duplicates := make(map[string]bool)
+			for _, targetID := range targetIDs {
+				if seen[targetID] {
+					duplicates[targetID] = true
+				}
+				seen[targetID] = true
+			}
+			if len(duplicates) > 0 {
+				violate(relationshipName, ConstraintUniqueTargets,
+					"%s lists %s more than once", relationshipName, strings.Join(sortedKeys(duplicates), ", "))
+			}
+		}
+	}
+
+	return violations
+}
+
+// targetKindViolation reports an edge pointing at a node of a kind the relationship doesn't allow
+func targetKindViolation(sourceID, relationshipName string, target *types.Node) ConstraintViolation {
+	return ConstraintViolation{
+		NodeID:       sourceID,
+		Relationship: relationshipName,
+		Constraint:   ConstraintTargetKinds,
+		Message:      fmt.Sprintf("%s cannot point at %s, which is %s", relationshipName, target.ID, types.KindName(target.Kind)),
+	}
+}
+
+// sortedRelationshipNames returns the names of all registered relationships in sorted order.
+// The caller must hold the lock.
+func (m *Manager) sortedRelationshipNames() []string {
+	names := make(map[string]bool, len(m.relationshipTypes))
+	for name := range m.relationshipTypes {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
@@ -0,0 +1,274 @@
+package graph_manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// constraintRelationships are relationships with constraints
+var constraintRelationships = []types.Relationship{
+	{Name: "prerequisites", Direction: types.DirectionBackward, Constraints: types.RelationshipConstraints{
+		MaxTargets:      2,
+		RequiredForTags: []string{"deploy"},
+		UniqueTargets:   true,
+	}},
+	{Name: "reviewers", Direction: types.DirectionNone, Kind: types.KindGraph, Constraints: types.RelationshipConstraints{
+		MinTargets:       2,
+		NoSelfReferences: true,
+	}},
+}
+
+// namedTestNodes returns unconnected nodes named after their IDs
+func namedTestNodes(ids ...string) []*types.Node {
+	nodes := make([]*types.Node, len(ids))
+	for i, id := range ids {
+		nodes[i] = &types.Node{ID: id, Name: id}
+	}
+	return nodes
+}
+
+// violatedConstraints returns the constraints named by a *ConstraintError
+func violatedConstraints(err error) []string {
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) {
+		return nil
+	}
+	var constraints []string
+	for _, violation := range constraintErr.Violations {
+		constraints = append(constraints, violation.Relationship+"."+violation.Constraint)
+	}
+	return constraints
+}
+
+func TestRegisterRelationshipConstraints(t *testing.T) {
+	log, _ := logger.New(false)
+
+	tests := []struct {
+		name        string
+		constraints types.RelationshipConstraints
+		wantErr     bool
+	}{
+		{"none", types.RelationshipConstraints{}, false},
+		{"min and max", types.RelationshipConstraints{MinTargets: 1, MaxTargets: 3}, false},
+		{"min without max", types.RelationshipConstraints{MinTargets: 5}, false},
+		{"min above max", types.RelationshipConstraints{MinTargets: 4, MaxTargets: 3}, true},
+		{"negative max", types.RelationshipConstraints{MaxTargets: -1}, true},
+		{"empty required tag", types.RelationshipConstraints{RequiredForTags: []string{""}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(log)
+			err := manager.RegisterRelationship(types.Relationship{Name: "rel", Direction: types.DirectionNone, Constraints: tt.constraints})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterRelationship() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConstraintsOnWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		node *types.Node
+		want []string
+	}{
+		{
+			name: "valid",
+			node: &types.Node{ID: "d", Tags: []string{"deploy"}, EdgeIDs: map[string][]string{
+				"prerequisites": {"a"},
+				"reviewers":     {"b", "c"},
+			}},
+		},
+		{
+			name: "too many targets",
+			node: &types.Node{ID: "d", EdgeIDs: map[string][]string{"prerequisites": {"a", "b", "c"}}},
+			want: []string{"prerequisites.max_targets"},
+		},
+		{
+			name: "too few targets",
+			node: &types.Node{ID: "d", EdgeIDs: map[string][]string{"reviewers": {"a"}}},
+			want: []string{"reviewers.min_targets"},
+		},
+		{
+			name: "required by tag",
+			node: &types.Node{ID: "d", Tags: []string{"deploy"}},
+			want: []string{"prerequisites.required_for_tags"},
+		},
+		{
+			name: "self reference",
+			node: &types.Node{ID: "d", EdgeIDs: map[string][]string{"reviewers": {"a", "d"}}},
+			want: []string{"reviewers.no_self_references"},
+		},
+		{
+			name: "duplicate targets and too few",
+			node: &types.Node{ID: "d", EdgeIDs: map[string][]string{
+				"prerequisites": {"a", "a"},
+				"reviewers":     {"b"},
+			}},
+			want: []string{"prerequisites.unique_targets", "reviewers.min_targets"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, constraintRelationships...)
+			addTestNodes(t, manager, namedTestNodes("a", "b", "c")...)
+
+			err := manager.AddNode(tt.node)
+			if got := violatedConstraints(err); !slices.Equal(got, tt.want) {
+				t.Errorf("AddNode() violations = %v, want %v (error: %v)", got, tt.want, err)
+			}
+
+			// Updating an existing node is checked the same way
+			node := tt.node.Clone()
+			node.ID = "a"
+			for _, targetIDs := range node.EdgeIDs {
+				for i, targetID := range targetIDs {
+					if targetID == "d" {
+						targetIDs[i] = "a"
+					}
+				}
+			}
+			err = manager.UpdateNode(node)
+			if len(tt.want) > 0 && violatedConstraints(err) == nil {
+				t.Errorf("UpdateNode() error = %v, want constraint violations", err)
+			}
+		})
+	}
+}
+
+func TestConstraintsInTransaction(t *testing.T) {
+	manager := newTestManager(t, constraintRelationships...)
+	addTestNodes(t, manager, namedTestNodes("a", "b", "c")...)
+
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "d", Tags: []string{"deploy"}}); err != nil {
+		t.Fatalf("Failed to stage node: %v", err)
+	}
+	err := tx.Commit()
+	if got := violatedConstraints(err); !slices.Equal(got, []string{"prerequisites.required_for_tags"}) {
+		t.Errorf("Commit() violations = %v (error: %v)", got, err)
+	}
+}
+
+func TestConstraintsOnDelete(t *testing.T) {
+	manager := newTestManager(t, constraintRelationships...)
+	addTestNodes(t, manager, namedTestNodes("a", "b", "c")...)
+	addTestNodes(t, manager,
+		&types.Node{ID: "d", Tags: []string{"deploy"}, EdgeIDs: map[string][]string{"prerequisites": {"a"}}},
+		&types.Node{ID: "r", EdgeIDs: map[string][]string{"reviewers": {"b", "c"}}},
+		&types.Node{ID: "e"},
+	)
+
+	// Deleting a target a node needs is refused
+	err := manager.DeleteNode("a")
+	if got := violatedConstraints(err); !slices.Equal(got, []string{"prerequisites.required_for_tags"}) {
+		t.Errorf("DeleteNode() violations = %v (error: %v)", got, err)
+	}
+	if _, err := manager.GetNode("a"); err != nil {
+		t.Errorf("Expected the refused delete to keep the node: %v", err)
+	}
+	err = manager.DeleteNode("b")
+	if got := violatedConstraints(err); !slices.Equal(got, []string{"reviewers.min_targets"}) {
+		t.Errorf("DeleteNode() violations = %v (error: %v)", got, err)
+	}
+	if _, err := manager.PreviewDelete("b"); violatedConstraints(err) == nil {
+		t.Errorf("PreviewDelete() error = %v, want constraint violations", err)
+	}
+
+	tx := manager.Begin()
+	if err := tx.DeleteNode("a"); err != nil {
+		t.Fatalf("Failed to stage delete: %v", err)
+	}
+	err = tx.Commit()
+	if got := violatedConstraints(err); !slices.Equal(got, []string{"prerequisites.required_for_tags"}) {
+		t.Errorf("Commit() violations = %v (error: %v)", got, err)
+	}
+
+	// Replacing the edge in the same transaction makes the delete valid
+	tx = manager.Begin()
+	if err := tx.DeleteNode("a"); err != nil {
+		t.Fatalf("Failed to stage delete: %v", err)
+	}
+	if err := tx.UpdateNode(&types.Node{ID: "d", Tags: []string{"deploy"}, EdgeIDs: map[string][]string{"prerequisites": {"b", "e"}}}); err != nil {
+		t.Fatalf("Failed to stage update: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit() error = %v", err)
+	}
+
+	// Problems a node already had don't block deleting its targets
+	if err := manager.RegisterRelationship(types.Relationship{Name: "owners", Direction: types.DirectionNone, Constraints: types.RelationshipConstraints{
+		RequiredForTags: []string{"deploy"},
+	}}); err != nil {
+		t.Fatalf("Failed to register relationship: %v", err)
+	}
+	if err := manager.DeleteNode("e"); err != nil {
+		t.Errorf("DeleteNode() error = %v", err)
+	}
+}
+
+func TestLintReportsConstraintViolations(t *testing.T) {
+	manager := newTestManager(t, constraintRelationships...)
+	addTestNodes(t, manager, namedTestNodes("a", "b", "c")...)
+	if err := manager.AddNode(&types.Node{ID: "d", Tags: []string{"deploy"}, EdgeIDs: map[string][]string{"prerequisites": {"a"}}}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	// Constraints registered after the data was written only show up in lint
+	if err := manager.RegisterRelationship(types.Relationship{Name: "owners", Direction: types.DirectionNone, Constraints: types.RelationshipConstraints{
+		RequiredForTags: []string{"deploy"},
+	}}); err != nil {
+		t.Fatalf("Failed to register relationship: %v", err)
+	}
+
+	issues := manager.Lint()
+	if len(issues) != 1 || issues[0].NodeID != "d" || issues[0].Relationship != "owners" {
+		t.Errorf("Expected a single owners issue on d, got %v", issues)
+	}
+}
+
+func TestLoadRelationshipConstraintsFromFile(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+
+	filePath := filepath.Join(t.TempDir(), "relationships.yaml")
+	content := `relationships:
+  - name: prerequisites
+    description: Tasks that must be completed before this node
+    direction: backward
+    constraints:
+      min_targets: 1
+      max_targets: 3
+      required_for_tags: [release]
+      no_self_references: true
+      unique_targets: true
+`
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := manager.LoadRelationshipsFromFile(filePath); err != nil {
+		t.Fatalf("LoadRelationshipsFromFile() error = %v", err)
+	}
+
+	want := types.RelationshipConstraints{
+		MinTargets:       1,
+		MaxTargets:       3,
+		RequiredForTags:  []string{"release"},
+		NoSelfReferences: true,
+		UniqueTargets:    true,
+	}
+	got := manager.GetRelationship("prerequisites").Constraints
+	if got.MinTargets != want.MinTargets || got.MaxTargets != want.MaxTargets ||
+		!slices.Equal(got.RequiredForTags, want.RequiredForTags) ||
+		got.NoSelfReferences != want.NoSelfReferences || got.UniqueTargets != want.UniqueTargets {
+		t.Errorf("Constraints = %+v, want %+v", got, want)
+	}
+}
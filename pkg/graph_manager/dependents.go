@@ -0,0 +1,47 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// GetDependents returns the nodes whose edges point directly at the given node,
+// grouped by relationship name and sorted by ID. It answers questions like
+// "which nodes list this one as a prerequisite?" from the incoming edge index
+// without scanning the graph.
+func (m *Manager) GetDependents(id string) (map[string][]*types.Node, error) {
+	m.ensureIncomingIndex()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.nodes[id]; !exists {
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	dependents := make(map[string][]*types.Node, len(m.incoming[id]))
+	for relationshipName, sourceIDs := range m.incoming[id] {
+		sources := make([]*types.Node, 0, len(sourceIDs))
+		for _, sourceID := range sourceIDs {
+			if source, exists := m.nodes[sourceID]; exists {
+				sources = append(sources, source)
+			}
+		}
+		if len(sources) == 0 {
+			continue
+		}
+		sort.Slice(sources, func(i, j int) bool { return sources[i].ID < sources[j].ID })
+		dependents[relationshipName] = sources
+	}
+
+	m.logger.Debug("Found dependents",
+		zap.String("node_id", id),
+		zap.Int("relationship_count", len(dependents)),
+	)
+
+	return dependents, nil
+}
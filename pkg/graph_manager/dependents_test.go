@@ -0,0 +1,56 @@
+package graph_manager
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// dependentIDs flattens dependents to relationship name -> node IDs
+func dependentIDs(dependents map[string][]*types.Node) map[string][]string {
+	ids := make(map[string][]string, len(dependents))
+	for relationshipName, nodes := range dependents {
+		for _, node := range nodes {
+			ids[relationshipName] = append(ids[relationshipName], node.ID)
+		}
+	}
+	return ids
+}
+
+func TestGetDependents(t *testing.T) {
+	manager := newTestManager(t)
+	addTestNodes(t, manager, traversalTestNodes()...)
+
+	assertDependents := func(id string, expected map[string][]string) {
+		t.Helper()
+		dependents, err := manager.GetDependents(id)
+		if err != nil {
+			t.Fatalf("GetDependents failed: %v", err)
+		}
+		if got := dependentIDs(dependents); !maps.EqualFunc(got, expected, slices.Equal[[]string]) {
+			t.Errorf("Expected dependents of %s to be %v, got %v", id, expected, got)
+		}
+	}
+
+	assertDependents("checkout", map[string][]string{"prerequisites": {"build", "test"}})
+	assertDependents("build", map[string][]string{"prerequisites": {"deploy"}, "related": {"test"}})
+	assertDependents("deploy", map[string][]string{})
+
+	// The index follows updates and deletes
+	if err := manager.UpdateNode(&types.Node{ID: "test", Name: "Test"}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	assertDependents("checkout", map[string][]string{"prerequisites": {"build"}})
+	assertDependents("build", map[string][]string{"prerequisites": {"deploy"}})
+
+	if err := manager.DeleteNode("deploy"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	assertDependents("build", map[string][]string{})
+
+	if _, err := manager.GetDependents("missing"); err == nil {
+		t.Error("Expected error for missing node")
+	}
+}
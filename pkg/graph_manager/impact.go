@@ -0,0 +1,246 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// ImpactedEdge is an edge a change would add or remove
+type ImpactedEdge struct {
+	From         string
+	Relationship string
+	To           string
+}
+
+// LostSteps lists the nodes a workflow would no longer reach through a
+// relationship, directly or transitively, after a change
+type LostSteps struct {
+	NodeID       string
+	Relationship string
+	Lost         []string
+}
+
+// ChangeImpact describes what a delete or update would change, without applying it
+type ChangeImpact struct {
+	Operation OperationType
+	ID        string
+
+	RemovedEdges []ImpactedEdge
+	AddedEdges   []ImpactedEdge
+
+	// AffectedNodes are the other nodes at either end of an added or removed edge
+	AffectedNodes []string
+
+	// LostSteps are the workflows that would lose steps, ordered by node ID
+	// and relationship
+	LostSteps []LostSteps
+}
+
+// PreviewDelete reports what DeleteNode would change without changing anything.
+// It fails in the same cases DeleteNode would.
+func (m *Manager) PreviewDelete(id string, opts ...WriteOption) (*ChangeImpact, error) {
+	if id == "" {
+		return nil, fmt.Errorf("node ID cannot be empty")
+	}
+	return m.previewChange(Operation{Type: OperationDelete, ID: id}, opts, nil)
+}
+
+// PreviewUpdate reports what UpdateNode would change without changing anything.
+// It fails in the same cases UpdateNode would.
+func (m *Manager) PreviewUpdate(node *types.Node, opts ...WriteOption) (*ChangeImpact, error) {
+	if node == nil {
+		return nil, fmt.Errorf("node cannot be nil")
+	}
+	if node.ID == "" {
+		return nil, fmt.Errorf("node ID cannot be empty")
+	}
+	return m.previewChange(Operation{Type: OperationUpdate, ID: node.ID, Node: node}, opts, nil)
+}
+
+// PreviewPatch reports what PatchNode would change without changing anything.
+// It fails in the same cases PatchNode would.
+func (m *Manager) PreviewPatch(id string, patch NodePatch, opts ...WriteOption) (*ChangeImpact, error) {
+	if id == "" {
+		return nil, fmt.Errorf("node ID cannot be empty")
+	}
+	return m.previewChange(Operation{Type: OperationUpdate, ID: id}, opts, &patch)
+}
+
+// previewChange applies a single operation to a staging copy of the graph and
+// compares the result with the current graph. If patch is set, the updated node
+// is built by applying it to the current node.
+func (m *Manager) previewChange(op Operation, opts []WriteOption, patch *NodePatch) (*ChangeImpact, error) {
+	m.logger.Debug("Previewing change", zap.String("operation", string(op.Type)), zap.String("node_id", op.ID))
+
+	m.ensureIncomingIndex()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	existing, exists := m.nodes[op.ID]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", op.ID)
+	}
+	options := newWriteOptions(opts)
+	if err := options.checkRevision(existing); err != nil {
+		return nil, err
+	}
+	op.AllowDeprecatedTargets = options.allowDeprecatedTargets
+	if patch != nil {
+		op.Node = patch.applyTo(existing)
+	}
+
+	staged, err := m.applyOperations([]Operation{op})
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &ChangeImpact{Operation: op.Type, ID: op.ID}
+
+	// Nodes are replaced rather than modified, so only the nodes the staged
+	// graph doesn't share with the current one can have different edges: the
+	// changed node, the nodes pointing at a deleted node and the targets of
+	// mirrored inverse edges
+	changed := map[string]bool{op.ID: true}
+	for id, node := range staged.nodes {
+		if m.nodes[id] != node {
+			changed[id] = true
+		}
+	}
+	for _, id := range sortedKeys(changed) {
+		var before, after map[string][]string
+		if node := m.nodes[id]; node != nil {
+			before = node.EdgeIDs
+		}
+		if node := staged.nodes[id]; node != nil {
+			after = node.EdgeIDs
+		}
+		impact.RemovedEdges = append(impact.RemovedEdges, edgeDifference(id, before, after)...)
+		impact.AddedEdges = append(impact.AddedEdges, edgeDifference(id, after, before)...)
+	}
+
+	affected := make(map[string]bool)
+	for _, edges := range [][]ImpactedEdge{impact.RemovedEdges, impact.AddedEdges} {
+		for _, edge := range edges {
+			affected[edge.From] = true
+			affected[edge.To] = true
+		}
+	}
+	delete(affected, op.ID)
+	impact.AffectedNodes = sortedKeys(affected)
+
+	impact.LostSteps = m.lostSteps(staged, impact.RemovedEdges)
+
+	m.logger.Debug("Previewed change",
+		zap.String("operation", string(op.Type)),
+		zap.String("node_id", op.ID),
+		zap.Int("edges_removed", len(impact.RemovedEdges)),
+		zap.Int("edges_added", len(impact.AddedEdges)),
+		zap.Int("workflows_losing_steps", len(impact.LostSteps)),
+	)
+
+	return impact, nil
+}
+
+// lostSteps finds the workflows that reach fewer nodes in the staged graph
+// than in the current one because of the removed edges. Only the sources of
+// removed edges and the nodes leading to them through the same relationship
+// can lose steps.
+// The caller must hold the lock.
+func (m *Manager) lostSteps(staged *Manager, removed []ImpactedEdge) []LostSteps {
+	candidates := make(map[string]map[string]bool) // relationship -> node IDs
+	for _, edge := range removed {
+		if candidates[edge.Relationship] == nil {
+			candidates[edge.Relationship] = make(map[string]bool)
+		}
+		// Everything leading to the source through the relationship
+		queue := []string{edge.From}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			if candidates[edge.Relationship][id] {
+				continue
+			}
+			candidates[edge.Relationship][id] = true
+			queue = append(queue, m.incoming[id][edge.Relationship]...)
+		}
+	}
+
+	var lost []LostSteps
+	for relationshipName, nodeIDs := range candidates {
+		for id := range nodeIDs {
+			if _, exists := staged.nodes[id]; !exists {
+				continue // The deleted node itself
+			}
+			before := reachableIDs(id, relationshipName, m.nodes)
+			after := reachableIDs(id, relationshipName, staged.nodes)
+			var missing []string
+			for reached := range before {
+				if !after[reached] {
+					missing = append(missing, reached)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			sort.Strings(missing)
+			lost = append(lost, LostSteps{NodeID: id, Relationship: relationshipName, Lost: missing})
+		}
+	}
+
+	sort.Slice(lost, func(i, j int) bool {
+		if lost[i].NodeID != lost[j].NodeID {
+			return lost[i].NodeID < lost[j].NodeID
+		}
+		return lost[i].Relationship < lost[j].Relationship
+	})
+	return lost
+}
+
+// reachableIDs returns the IDs of all nodes reachable from the start node by
+// following the EdgeIDs of a relationship, excluding the start node
+func reachableIDs(startID, relationshipName string, nodes map[string]*types.Node) map[string]bool {
+	reached := make(map[string]bool)
+	queue := []string{startID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		node, exists := nodes[id]
+		if !exists {
+			continue
+		}
+		for _, targetID := range node.EdgeIDs[relationshipName] {
+			if targetID != startID && !reached[targetID] {
+				reached[targetID] = true
+				queue = append(queue, targetID)
+			}
+		}
+	}
+	return reached
+}
+
+// edgeDifference returns the edges of a node present in from but not in to,
+// ordered by relationship and target
+func edgeDifference(id string, from, to map[string][]string) []ImpactedEdge {
+	relationshipNames := make([]string, 0, len(from))
+	for relationshipName := range from {
+		relationshipNames = append(relationshipNames, relationshipName)
+	}
+	sort.Strings(relationshipNames)
+
+	var edges []ImpactedEdge
+	for _, relationshipName := range relationshipNames {
+		targetIDs := append([]string{}, from[relationshipName]...)
+		sort.Strings(targetIDs)
+		for _, targetID := range targetIDs {
+			if !containsString(to[relationshipName], targetID) {
+				edges = append(edges, ImpactedEdge{From: id, Relationship: relationshipName, To: targetID})
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,127 @@
+package graph_manager
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+func TestPreviewDelete(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "checkout", Name: "Checkout"},
+		{ID: "build", Name: "Build", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}}},
+		{ID: "test", Name: "Test", EdgeIDs: map[string][]string{"prerequisites": {"build"}, "related": {"checkout"}}},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"test"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+	original := make(map[string]*types.Node)
+	for _, node := range manager.ListAllNodes() {
+		original[node.ID] = node
+	}
+
+	impact, err := manager.PreviewDelete("build")
+	if err != nil {
+		t.Fatalf("PreviewDelete failed: %v", err)
+	}
+
+	expectedRemoved := []ImpactedEdge{
+		{From: "build", Relationship: "prerequisites", To: "checkout"},
+		{From: "test", Relationship: "prerequisites", To: "build"},
+	}
+	if !reflect.DeepEqual(impact.RemovedEdges, expectedRemoved) {
+		t.Errorf("Expected removed edges %v, got %v", expectedRemoved, impact.RemovedEdges)
+	}
+	if len(impact.AddedEdges) != 0 {
+		t.Errorf("Expected no added edges, got %v", impact.AddedEdges)
+	}
+	if expected := []string{"checkout", "test"}; !slices.Equal(impact.AffectedNodes, expected) {
+		t.Errorf("Expected affected nodes %v, got %v", expected, impact.AffectedNodes)
+	}
+
+	// test loses build and, through it, checkout; so does deploy transitively
+	expectedLost := []LostSteps{
+		{NodeID: "deploy", Relationship: "prerequisites", Lost: []string{"build", "checkout"}},
+		{NodeID: "test", Relationship: "prerequisites", Lost: []string{"build", "checkout"}},
+	}
+	if !reflect.DeepEqual(impact.LostSteps, expectedLost) {
+		t.Errorf("Expected lost steps %v, got %v", expectedLost, impact.LostSteps)
+	}
+
+	// Nothing was changed
+	for id, node := range original {
+		if current, err := manager.GetNode(id); err != nil || current != node {
+			t.Errorf("Preview should not change node %s", id)
+		}
+	}
+
+	if _, err := manager.PreviewDelete("missing"); err == nil {
+		t.Error("Expected error for missing node")
+	}
+	var conflict *ConflictError
+	if _, err := manager.PreviewDelete("build", WithExpectedRevision("stale")); !errors.As(err, &conflict) {
+		t.Errorf("Expected ConflictError, got %v", err)
+	}
+}
+
+func TestPreviewUpdate(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "lint", Name: "Lint"},
+		{ID: "checkout", Name: "Checkout"},
+		{ID: "build", Name: "Build", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}}},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"build"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	impact, err := manager.PreviewUpdate(&types.Node{
+		ID:      "build",
+		Name:    "Build",
+		EdgeIDs: map[string][]string{"prerequisites": {"lint"}},
+	})
+	if err != nil {
+		t.Fatalf("PreviewUpdate failed: %v", err)
+	}
+	if expected := []ImpactedEdge{{From: "build", Relationship: "prerequisites", To: "checkout"}}; !reflect.DeepEqual(impact.RemovedEdges, expected) {
+		t.Errorf("Expected removed edges %v, got %v", expected, impact.RemovedEdges)
+	}
+	if expected := []ImpactedEdge{{From: "build", Relationship: "prerequisites", To: "lint"}}; !reflect.DeepEqual(impact.AddedEdges, expected) {
+		t.Errorf("Expected added edges %v, got %v", expected, impact.AddedEdges)
+	}
+	expectedLost := []LostSteps{
+		{NodeID: "build", Relationship: "prerequisites", Lost: []string{"checkout"}},
+		{NodeID: "deploy", Relationship: "prerequisites", Lost: []string{"checkout"}},
+	}
+	if !reflect.DeepEqual(impact.LostSteps, expectedLost) {
+		t.Errorf("Expected lost steps %v, got %v", expectedLost, impact.LostSteps)
+	}
+	if build, _ := manager.GetNode("build"); !slices.Equal(build.GetEdgeIDs("prerequisites"), []string{"checkout"}) {
+		t.Error("Preview should not update the node")
+	}
+
+	// Patches are previewed the same way
+	impact, err = manager.PreviewPatch("build", NodePatch{AddEdges: map[string][]string{"prerequisites": {"lint"}}})
+	if err != nil {
+		t.Fatalf("PreviewPatch failed: %v", err)
+	}
+	if len(impact.RemovedEdges) != 0 || len(impact.AddedEdges) != 1 || len(impact.LostSteps) != 0 {
+		t.Errorf("Expected a single added edge, got %+v", impact)
+	}
+
+	// Invalid changes fail as they would when applied
+	if _, err := manager.PreviewPatch("checkout", NodePatch{AddEdges: map[string][]string{"prerequisites": {"deploy"}}}); err == nil {
+		t.Error("Expected preview of a cycle to fail")
+	}
+	if _, err := manager.PreviewUpdate(&types.Node{ID: "build", EdgeIDs: map[string][]string{"prerequisites": {"missing"}}}); err == nil {
+		t.Error("Expected preview of a dangling reference to fail")
+	}
+}
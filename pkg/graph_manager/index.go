@@ -0,0 +1,202 @@
+package graph_manager
+
+import (
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// insertNode stores a new node and updates the incoming edge index and the tag
+// cache. Call refreshEdges with its ID once every node of the change is stored.
+// The caller must hold the write lock.
+func (m *Manager) insertNode(node *types.Node) {
+	m.nodes[node.ID] = node
+	m.markDirty(node.ID)
+	if m.incoming != nil {
+		m.indexEdges(node)
+	}
+	m.addToTagCache(node)
+}
+
+// replaceNode swaps a stored node for a new version with the same ID and updates
+// the incoming edge index and the tag cache. Call refreshEdges with its ID once
+// every node of the change is stored.
+// The caller must hold the write lock.
+func (m *Manager) replaceNode(old, node *types.Node) {
+	m.nodes[node.ID] = node
+	m.markDirty(node.ID)
+	if m.incoming != nil {
+		m.unindexEdges(old)
+		m.indexEdges(node)
+	}
+	m.replaceInTagCache(old, node)
+}
+
+// removeNode removes a stored node whose referrers have already been cleaned
+// up, and updates the incoming edge index and the tag cache.
+// The caller must hold the write lock.
+func (m *Manager) removeNode(node *types.Node) {
+	delete(m.nodes, node.ID)
+	m.markDirty(node.ID)
+	if m.incoming != nil {
+		m.unindexEdges(node)
+		delete(m.incoming, node.ID)
+	}
+	m.removeFromTagCache(node)
+}
+
+// refreshEdges resolves the edges of the nodes just stored under the given IDs,
+// which must not have been published yet. Published nodes pointing at them
+// directly are replaced with copies resolved against the current nodes rather
+// than modified, so readers holding published nodes never see their edges
+// change. Nodes further away keep their resolved edges: a node's edges always
+// reach the current version of its targets, but the edges of those targets
+// may be older, so traversals look every hop up by ID.
+// The caller must hold the write lock.
+func (m *Manager) refreshEdges(ids ...string) {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	// Copy every referrer before resolving anything, so the resolved pointers
+	// refer to the copies where referrers point at each other. Only the resolved
+	// edges of a copy are replaced, so it shares its persisted fields with the
+	// published node, which are never modified.
+	var referrers []string
+	for _, id := range ids {
+		for _, sourceID := range m.referencingNodeIDs(id) {
+			source, exists := m.nodes[sourceID]
+			if seen[sourceID] || !exists {
+				continue
+			}
+			seen[sourceID] = true
+			replacement := new(types.Node)
+			*replacement = *source
+			m.nodes[sourceID] = replacement
+			m.replaceInTagCache(source, replacement)
+			referrers = append(referrers, sourceID)
+		}
+	}
+
+	for _, id := range ids {
+		node, exists := m.nodes[id]
+		if !exists {
+			continue
+		}
+		if err := m.resolveNodeEdges(node); err != nil {
+			m.logger.Warn("Node has unresolved edges", zap.String("node_id", id), zap.Error(err))
+		}
+	}
+	for _, id := range referrers {
+		if err := m.resolveNodeEdges(m.nodes[id]); err != nil {
+			m.logger.Debug("Referencing node has unresolved edges",
+				zap.String("node_id", id),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// referencingNodeIDs returns the IDs of all nodes with an edge of any relationship
+// pointing at the given ID. The incoming edge index is built if needed.
+// The caller must hold the write lock.
+func (m *Manager) referencingNodeIDs(id string) []string {
+	byRelationship := m.incomingIndex()[id]
+	if len(byRelationship) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var sourceIDs []string
+	for _, sources := range byRelationship {
+		for _, sourceID := range sources {
+			if !seen[sourceID] {
+				seen[sourceID] = true
+				sourceIDs = append(sourceIDs, sourceID)
+			}
+		}
+	}
+	return sourceIDs
+}
+
+// incomingIndex returns the incoming edge index, building it from the current
+// nodes on first use.
+// The caller must hold the write lock.
+func (m *Manager) incomingIndex() map[string]map[string][]string {
+	if m.incoming == nil {
+		m.buildIncomingIndex()
+	}
+	return m.incoming
+}
+
+// ensureIncomingIndex builds the incoming edge index if it hasn't been built
+// yet, so it can be read under the shared lock
+func (m *Manager) ensureIncomingIndex() {
+	m.mu.RLock()
+	built := m.incoming != nil
+	m.mu.RUnlock()
+	if built {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.incomingIndex()
+}
+
+// buildIncomingIndex rebuilds the incoming edge index from scratch.
+// The caller must hold the write lock.
+func (m *Manager) buildIncomingIndex() {
+	m.incoming = make(map[string]map[string][]string)
+	for _, node := range m.nodes {
+		m.indexEdges(node)
+	}
+}
+
+// indexEdges records the node's outgoing edges in the incoming edge index
+func (m *Manager) indexEdges(node *types.Node) {
+	for relationshipName, targetIDs := range node.EdgeIDs {
+		for _, targetID := range targetIDs {
+			byRelationship, exists := m.incoming[targetID]
+			if !exists {
+				byRelationship = make(map[string][]string)
+				m.incoming[targetID] = byRelationship
+			}
+			if !containsString(byRelationship[relationshipName], node.ID) {
+				byRelationship[relationshipName] = append(byRelationship[relationshipName], node.ID)
+			}
+		}
+	}
+}
+
+// unindexEdges removes the node's outgoing edges from the incoming edge index
+func (m *Manager) unindexEdges(node *types.Node) {
+	for relationshipName, targetIDs := range node.EdgeIDs {
+		for _, targetID := range targetIDs {
+			byRelationship, exists := m.incoming[targetID]
+			if !exists {
+				continue
+			}
+			remaining := removeStringFromSlice(byRelationship[relationshipName], node.ID)
+			if len(remaining) == 0 {
+				delete(byRelationship, relationshipName)
+			} else {
+				byRelationship[relationshipName] = remaining
+			}
+			if len(byRelationship) == 0 {
+				delete(m.incoming, targetID)
+			}
+		}
+	}
+}
+
+// containsString reports whether the slice contains the value
+func containsString(slice []string, value string) bool {
+	for _, v := range slice {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// DanglingReferenceError reports edges that point at node IDs which don't exist.
+// Use errors.As to inspect the missing references.
+type DanglingReferenceError struct {
+	// Missing maps source node IDs to relationship names to the missing target IDs
+	Missing map[string]map[string][]string
+}
+
+// Error implements the error interface
+func (e *DanglingReferenceError) Error() string {
+	var parts []string
+	for _, sourceID := range sortedMapKeys(e.Missing) {
+		byRelationship := e.Missing[sourceID]
+		for _, relationshipName := range sortedMapKeys(byRelationship) {
+			parts = append(parts, fmt.Sprintf("%s -> %s: %s",
+				sourceID, relationshipName, strings.Join(byRelationship[relationshipName], ", ")))
+		}
+	}
+	return fmt.Sprintf("references to missing nodes: %s", strings.Join(parts, "; "))
+}
+
+// MissingIDs returns the sorted, de-duplicated list of missing node IDs
+func (e *DanglingReferenceError) MissingIDs() []string {
+	seen := make(map[string]bool)
+	for _, byRelationship := range e.Missing {
+		for _, targetIDs := range byRelationship {
+			for _, targetID := range targetIDs {
+				seen[targetID] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// add records a missing target for a source node and relationship
+func (e *DanglingReferenceError) add(sourceID, relationshipName, targetID string) {
+	if e.Missing == nil {
+		e.Missing = make(map[string]map[string][]string)
+	}
+	if e.Missing[sourceID] == nil {
+		e.Missing[sourceID] = make(map[string][]string)
+	}
+	if !containsString(e.Missing[sourceID][relationshipName], targetID) {
+		e.Missing[sourceID][relationshipName] = append(e.Missing[sourceID][relationshipName], targetID)
+	}
+}
+
+// merge folds the missing references of another error into this one
+func (e *DanglingReferenceError) merge(other *DanglingReferenceError) {
+	if other == nil {
+		return
+	}
+	for sourceID, byRelationship := range other.Missing {
+		for relationshipName, targetIDs := range byRelationship {
+			for _, targetID := range targetIDs {
+				e.add(sourceID, relationshipName, targetID)
+			}
+		}
+	}
+}
+
+// findDanglingReferences checks that every edge target of the node exists
+// according to the lookup. Returns nil if all references resolve.
+func findDanglingReferences(node *types.Node, exists func(id string) bool) *DanglingReferenceError {
+	var dangling *DanglingReferenceError
+
+	for relationshipName, targetIDs := range node.EdgeIDs {
+		for _, targetID := range targetIDs {
+			if targetID == node.ID || exists(targetID) {
+				continue
+			}
+			if dangling == nil {
+				dangling = &DanglingReferenceError{}
+			}
+			dangling.add(node.ID, relationshipName, targetID)
+		}
+	}
+
+	return dangling
+}
+
+// SetAllowForwardReferences controls whether operations staged in a transaction
+// may reference nodes that don't exist yet. When enabled, such forward references
+// are accepted while staging but must be resolved (by a node added later in the
+// same transaction) before Commit succeeds. When disabled (the default), every
+// staged operation must only reference nodes that exist at the time it is staged.
+// Single-operation mutations (AddNode, UpdateNode) always reject dangling references.
+func (m *Manager) SetAllowForwardReferences(allow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.allowForwardReferences = allow
+}
+
+// AllowsForwardReferences reports whether transactions accept forward references
+func (m *Manager) AllowsForwardReferences() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.allowForwardReferences
+}
+
+// sortedMapKeys returns the keys of a string-keyed map in sorted order
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
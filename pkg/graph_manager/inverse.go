@@ -0,0 +1,182 @@
+package graph_manager
+
+import (
+	"fmt"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// inverseOf returns the registered relationship paired with the given one as
+// its inverse, declared on either side. A symmetric relationship is its own inverse.
+// The caller must hold the lock.
+func (m *Manager) inverseOf(relationshipName string) (string, bool) {
+	rel, exists := m.relationshipTypes[relationshipName]
+	if !exists {
+		return "", false
+	}
+	if rel.Kind == types.KindSymmetric {
+		return relationshipName, true
+	}
+	if rel.Inverse != "" {
+		_, registered := m.relationshipTypes[rel.Inverse]
+		return rel.Inverse, registered
+	}
+	for name, other := range m.relationshipTypes {
+		if other.Inverse == relationshipName {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// checkInversePairing checks that a relationship about to be registered agrees
+// with the registered relationships on which relationship is its inverse, and
+// that paired relationships have opposite directions and the same kind.
+// The caller must hold the lock.
+func (m *Manager) checkInversePairing(rel types.Relationship) error {
+	for name, other := range m.relationshipTypes {
+		paired := rel.Inverse == name || other.Inverse == rel.Name
+		if !paired {
+			continue
+		}
+		if rel.Inverse != "" && rel.Inverse != name {
+			return fmt.Errorf("inverse is %s, but %s declares itself the inverse of %s", rel.Inverse, name, rel.Name)
+		}
+		if other.Inverse != "" && other.Inverse != rel.Name {
+			return fmt.Errorf("inverse %s is already paired with %s", name, other.Inverse)
+		}
+		if !oppositeDirections(rel.Direction, other.Direction) {
+			return fmt.Errorf("direction %s does not mirror direction %s of its inverse %s", rel.Direction, other.Direction, name)
+		}
+		if kindOf(rel) != kindOf(*other) {
+			return fmt.Errorf("kind %s does not match kind %s of its inverse %s", kindOf(rel), kindOf(*other), name)
+		}
+	}
+	return nil
+}
+
+// kindOf returns the kind of a relationship, defaulting to a DAG
+func kindOf(rel types.Relationship) types.RelationshipKind {
+	if rel.Kind == "" {
+		return types.KindDAG
+	}
+	return rel.Kind
+}
+
+// oppositeDirections reports whether two directions describe the same ordering
+// from opposite ends
+func oppositeDirections(a, b types.RelationshipDirection) bool {
+	switch a {
+	case types.DirectionBackward:
+		return b == types.DirectionForward
+	case types.DirectionForward:
+		return b == types.DirectionBackward
+	default:
+		return b == types.DirectionNone
+	}
+}
+
+// mirrorInverseEdges returns new versions of the nodes whose inverse edges must
+// change so they mirror the change of a node from before to after. Before is
+// nil for an added node. Targets that don't exist are skipped.
+// The caller must hold the lock.
+func (m *Manager) mirrorInverseEdges(before, after *types.Node) []nodeReplacement {
+	updated := make(map[string]*types.Node)
+	var replacements []nodeReplacement
+	target := func(id string) *types.Node {
+		if node, exists := updated[id]; exists {
+			return node
+		}
+		existing, exists := m.nodes[id]
+		if !exists || id == after.ID {
+			return nil
+		}
+		node := copyNodeForWrite(existing)
+		updated[id] = node
+		replacements = append(replacements, nodeReplacement{old: existing, new: node})
+		return node
+	}
+
+	var beforeEdges map[string][]string
+	if before != nil {
+		beforeEdges = before.EdgeIDs
+	}
+
+	// Relationships used before or after the change
+	relationshipNames := make(map[string]bool)
+	for relationshipName := range beforeEdges {
+		relationshipNames[relationshipName] = true
+	}
+	for relationshipName := range after.EdgeIDs {
+		relationshipNames[relationshipName] = true
+	}
+
+	for _, relationshipName := range sortedKeys(relationshipNames) {
+		inverse, paired := m.inverseOf(relationshipName)
+		if !paired {
+			continue
+		}
+
+		for _, targetID := range after.EdgeIDs[relationshipName] {
+			if containsString(beforeEdges[relationshipName], targetID) {
+				continue
+			}
+			node := target(targetID)
+			if node == nil || containsString(node.EdgeIDs[inverse], after.ID) {
+				continue
+			}
+			node.EdgeIDs[inverse] = append(node.EdgeIDs[inverse], after.ID)
+		}
+
+		for _, targetID := range beforeEdges[relationshipName] {
+			if containsString(after.EdgeIDs[relationshipName], targetID) {
+				continue
+			}
+			node := target(targetID)
+			if node == nil || !containsString(node.EdgeIDs[inverse], after.ID) {
+				continue
+			}
+			node.EdgeIDs[inverse] = removeStringFromSlice(node.EdgeIDs[inverse], after.ID)
+			if len(node.EdgeIDs[inverse]) == 0 {
+				delete(node.EdgeIDs, inverse)
+			}
+			if node.Edges != nil {
+				if edges := removeEdgeByNodeID(node.Edges[inverse], after.ID); edges != nil {
+					node.Edges[inverse] = edges
+				} else {
+					delete(node.Edges, inverse)
+				}
+			}
+		}
+	}
+
+	// Targets that ended up unchanged don't need to be replaced
+	changed := replacements[:0]
+	for _, replacement := range replacements {
+		if !edgeIDsEqual(replacement.old.EdgeIDs, replacement.new.EdgeIDs) {
+			changed = append(changed, replacement)
+		}
+	}
+	return changed
+}
+
+// edgeIDsEqual reports whether two edge maps have the same targets per
+// relationship, ignoring relationships without targets
+func edgeIDsEqual(a, b map[string][]string) bool {
+	for relationshipName, targetIDs := range a {
+		if len(targetIDs) != len(b[relationshipName]) {
+			return false
+		}
+		for i, targetID := range targetIDs {
+			if b[relationshipName][i] != targetID {
+				return false
+			}
+		}
+	}
+	for relationshipName, targetIDs := range b {
+		if len(targetIDs) != len(a[relationshipName]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package graph_manager
+
+import (
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// inverseRelationships pair prerequisites and downstream_required as inverses
+var inverseRelationships = []types.Relationship{
+	{Name: "prerequisites", Direction: types.DirectionBackward, Inverse: "downstream_required"},
+	{Name: "downstream_required", Direction: types.DirectionForward},
+	{Name: "related", Direction: types.DirectionNone},
+}
+
+func TestRegisterRelationshipInverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		rel     types.Relationship
+		wantErr bool
+	}{
+		{"own inverse", types.Relationship{Name: "pairs_with", Direction: types.DirectionNone, Inverse: "pairs_with"}, true},
+		{"same direction", types.Relationship{Name: "enables", Direction: types.DirectionBackward, Inverse: "prerequisites"}, true},
+		{"already paired", types.Relationship{Name: "enables", Direction: types.DirectionForward, Inverse: "prerequisites"}, true},
+		{"conflicting declaration", types.Relationship{Name: "downstream", Direction: types.DirectionForward, Inverse: "related"}, true},
+		{"not yet registered", types.Relationship{Name: "blocks", Direction: types.DirectionForward, Inverse: "blocked_by"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, inverseRelationships...)
+			err := manager.RegisterRelationship(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterRelationship() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInverseEdgesAreMaintained(t *testing.T) {
+	manager := newTestManager(t, inverseRelationships...)
+	assertEdges := func(id, relationshipName string, expected ...string) {
+		t.Helper()
+		node, err := manager.GetNode(id)
+		if err != nil {
+			t.Fatalf("Failed to get node %s: %v", id, err)
+		}
+		if got := node.GetEdgeIDs(relationshipName); !slices.Equal(got, expected) {
+			t.Errorf("Expected %s %s to be %v, got %v", id, relationshipName, expected, got)
+		}
+		if len(node.GetEdges(relationshipName)) != len(expected) {
+			t.Errorf("Expected resolved %s %s to match edge IDs", id, relationshipName)
+		}
+	}
+
+	for _, node := range []*types.Node{
+		{ID: "build", Name: "Build"},
+		{ID: "lint", Name: "Lint"},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"build"}, "related": {"lint"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+	assertEdges("build", "downstream_required", "deploy")
+	assertEdges("lint", "downstream_required")
+
+	// Declaring the pair on either side works in both directions
+	if _, err := manager.PatchNode("lint", NodePatch{AddEdges: map[string][]string{"downstream_required": {"deploy"}}}); err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	assertEdges("deploy", "prerequisites", "build", "lint")
+
+	// Removing an edge removes its mirror
+	if err := manager.UpdateNode(&types.Node{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"lint"}}}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	assertEdges("build", "downstream_required")
+	assertEdges("lint", "downstream_required", "deploy")
+
+	// Transactions mirror edges too
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "test", Name: "Test", EdgeIDs: map[string][]string{"downstream_required": {"deploy"}}}); err != nil {
+		t.Fatalf("Failed to stage addition: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	assertEdges("deploy", "prerequisites", "lint", "test")
+
+	// Deleting a node removes the edges pointing at it, mirrors included
+	if err := manager.DeleteNode("deploy"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	assertEdges("lint", "downstream_required")
+	assertEdges("test", "downstream_required")
+
+	if issues := manager.Lint(); len(issues) != 0 {
+		t.Errorf("Expected maintained edges to lint clean, got %v", issues)
+	}
+}
+
+func TestMirroredEdgesAreCheckedForCycles(t *testing.T) {
+	manager := newTestManager(t, inverseRelationships...)
+
+	// a has a downstream edge to b that isn't mirrored by a prerequisite on b
+	manager.nodes["b"] = &types.Node{ID: "b", Name: "B"}
+	manager.nodes["a"] = &types.Node{ID: "a", Name: "A", EdgeIDs: map[string][]string{"downstream_required": {"b"}}}
+	if err := manager.ResolveNodePointers(); err != nil {
+		t.Fatalf("Failed to resolve pointers: %v", err)
+	}
+
+	// Making b a prerequisite of a is fine for prerequisites alone, but its
+	// mirror on b would close a downstream cycle a -> b -> a
+	if _, err := manager.PatchNode("a", NodePatch{AddEdges: map[string][]string{"prerequisites": {"b"}}}); err == nil {
+		t.Error("Expected a cycle through a mirrored edge to be rejected")
+	}
+	if b, _ := manager.GetNode("b"); len(b.GetEdgeIDs("downstream_required")) != 0 {
+		t.Error("Rejected change should not mirror edges")
+	}
+
+	// The consistent direction is accepted and completes the pair
+	if _, err := manager.PatchNode("b", NodePatch{AddEdges: map[string][]string{"prerequisites": {"a"}}}); err != nil {
+		t.Fatalf("Expected consistent inverse edge to be accepted: %v", err)
+	}
+	if issues := manager.Lint(); len(issues) != 0 {
+		t.Errorf("Expected completed pair to lint clean, got %v", issues)
+	}
+}
+
+func TestLintReportsAsymmetricInverses(t *testing.T) {
+	manager := newTestManager(t, inverseRelationships...)
+	manager.nodes["build"] = &types.Node{ID: "build", Name: "Build"}
+	manager.nodes["deploy"] = &types.Node{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"build"}}}
+	manager.nodes["notify"] = &types.Node{ID: "notify", Name: "Notify", EdgeIDs: map[string][]string{"related": {"build"}}}
+
+	issues := manager.Lint()
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issue := issues[0]; issue.NodeID != "deploy" || issue.Relationship != "prerequisites" || issue.TargetID != "build" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+}
@@ -0,0 +1,120 @@
+package graph_manager
+
+import (
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// kindRelationships are one relationship of each kind
+var kindRelationships = []types.Relationship{
+	{Name: "requires", Direction: types.DirectionBackward},
+	{Name: "pairs_with", Direction: types.DirectionNone, Kind: types.KindSymmetric},
+	{Name: "links_to", Direction: types.DirectionNone, Kind: types.KindGraph},
+}
+
+func TestRegisterRelationshipKind(t *testing.T) {
+	log, _ := logger.New(false)
+
+	tests := []struct {
+		name    string
+		rel     types.Relationship
+		wantErr bool
+	}{
+		{"default kind", types.Relationship{Name: "requires", Direction: types.DirectionBackward}, false},
+		{"graph with direction", types.Relationship{Name: "follows", Direction: types.DirectionForward, Kind: types.KindGraph}, false},
+		{"symmetric with direction", types.Relationship{Name: "pairs_with", Direction: types.DirectionForward, Kind: types.KindSymmetric}, true},
+		{"symmetric with inverse", types.Relationship{Name: "pairs_with", Direction: types.DirectionNone, Kind: types.KindSymmetric, Inverse: "other"}, true},
+		{"unknown kind", types.Relationship{Name: "pairs_with", Direction: types.DirectionNone, Kind: "tree"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewManager(log).RegisterRelationship(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterRelationship() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Paired relationships must be of the same kind
+	manager := newTestManager(t, kindRelationships...)
+	if err := manager.RegisterRelationship(types.Relationship{Name: "linked_from", Direction: types.DirectionNone, Inverse: "links_to"}); err == nil {
+		t.Error("Expected inverse of a different kind to be rejected")
+	}
+}
+
+func TestSymmetricEdgesAreKeptOnBothNodes(t *testing.T) {
+	manager := newTestManager(t, kindRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "chicken-parmesan", Name: "Chicken Parmesan"},
+		{ID: "caesar-salad", Name: "Caesar Salad"},
+		{ID: "garlic-bread", Name: "Garlic Bread", EdgeIDs: map[string][]string{"pairs_with": {"chicken-parmesan"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	parmesan, _ := manager.GetNode("chicken-parmesan")
+	if got := parmesan.GetEdgeIDs("pairs_with"); !slices.Equal(got, []string{"garlic-bread"}) {
+		t.Errorf("Expected symmetric edge on the target, got %v", got)
+	}
+
+	// Recording the pair from the other side as well is not a cycle
+	if _, err := manager.PatchNode("chicken-parmesan", NodePatch{AddEdges: map[string][]string{"pairs_with": {"caesar-salad"}}}); err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	salad, _ := manager.GetNode("caesar-salad")
+	if got := salad.GetEdgeIDs("pairs_with"); !slices.Equal(got, []string{"chicken-parmesan"}) {
+		t.Errorf("Expected symmetric edge on caesar-salad, got %v", got)
+	}
+
+	if _, err := manager.PatchNode("garlic-bread", NodePatch{RemoveEdges: map[string][]string{"pairs_with": {"chicken-parmesan"}}}); err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	parmesan, _ = manager.GetNode("chicken-parmesan")
+	if got := parmesan.GetEdgeIDs("pairs_with"); !slices.Equal(got, []string{"caesar-salad"}) {
+		t.Errorf("Expected removal to apply to both sides, got %v", got)
+	}
+
+	if issues := manager.Lint(); len(issues) != 0 {
+		t.Errorf("Expected symmetric edges to lint clean, got %v", issues)
+	}
+}
+
+func TestCyclesAllowedByKind(t *testing.T) {
+	manager := newTestManager(t, kindRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "a", Name: "A"},
+		{ID: "b", Name: "B", EdgeIDs: map[string][]string{"links_to": {"a"}, "requires": {"a"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	if _, err := manager.PatchNode("a", NodePatch{AddEdges: map[string][]string{"links_to": {"b"}}}); err != nil {
+		t.Errorf("Expected a cycle in a graph relationship to be allowed: %v", err)
+	}
+	if _, err := manager.PatchNode("a", NodePatch{AddEdges: map[string][]string{"requires": {"b"}}}); err == nil {
+		t.Error("Expected a cycle in a DAG relationship to be rejected")
+	}
+	if err := manager.DetectCycles(); err != nil {
+		t.Errorf("DetectCycles should skip relationships that allow cycles: %v", err)
+	}
+
+	// Stored graphs with such cycles load
+	memoryStore := store.NewMemoryStore()
+	for _, node := range manager.ListAllNodes() {
+		if err := memoryStore.Put(node); err != nil {
+			t.Fatalf("Failed to put node: %v", err)
+		}
+	}
+	loaded := newTestManager(t, kindRelationships...)
+	if err := loaded.LoadFromStore(memoryStore); err != nil {
+		t.Errorf("Failed to load graph with allowed cycles: %v", err)
+	}
+}
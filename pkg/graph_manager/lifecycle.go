@@ -0,0 +1,135 @@
+package graph_manager
+
+import (
+	"fmt"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// ResolveSuperseded returns the node that replaces the given node, following
+// superseded_by through chains of replacements, or the node itself if it isn't
+// superseded. Use it to redirect callers from a retired node to the current one.
+func (m *Manager) ResolveSuperseded(id string) (*types.Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, exists := m.nodes[id]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	seen := map[string]bool{node.ID: true}
+	for node.Status == types.StatusSuperseded && node.SupersededBy != "" {
+		replacement, exists := m.nodes[node.SupersededBy]
+		if !exists {
+			return nil, fmt.Errorf("node %s is superseded by %s, which does not exist", node.ID, node.SupersededBy)
+		}
+		if seen[replacement.ID] {
+			return nil, fmt.Errorf("nodes superseding %s form a cycle through %s", id, replacement.ID)
+		}
+		seen[replacement.ID] = true
+		node = replacement
+	}
+
+	m.logger.Debug("Resolved superseded node",
+		zap.String("node_id", id),
+		zap.String("replacement_id", node.ID),
+	)
+
+	return node, nil
+}
+
+// statusViolations checks a node's lifecycle status and its replacement,
+// resolving the replacement through lookup
+func statusViolations(node *types.Node, lookup func(id string) *types.Node) []ConstraintViolation {
+	var violations []ConstraintViolation
+	violate := func(format string, args ...any) {
+		violations = append(violations, ConstraintViolation{
+			NodeID:     node.ID,
+			Constraint: ConstraintStatus,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if err := node.Status.Validate(); err != nil {
+		violate("%v", err)
+	}
+	switch {
+	case node.Status == types.StatusSuperseded && node.SupersededBy == "":
+		violate("superseded nodes require superseded_by")
+	case node.SupersededBy == "":
+		// Nothing to check
+	case node.Status != types.StatusSuperseded:
+		violate("superseded_by requires status %s", types.StatusSuperseded)
+	case node.SupersededBy == node.ID:
+		violate("a node cannot supersede itself")
+	case lookup(node.SupersededBy) == nil:
+		violate("superseded_by %s does not exist", node.SupersededBy)
+	}
+	return violations
+}
+
+// replacementViolations reports the nodes superseded by one of the deleted
+// nodes, which would be left without their replacement. Nodes that are
+// themselves deleted or are in skip, because they are checked with the rest of
+// their changes, aren't reported.
+// The caller must hold the lock.
+func (m *Manager) replacementViolations(deleted, skip map[string]bool) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for _, id := range m.sortedNodeIDs() {
+		node := m.nodes[id]
+		if node.SupersededBy == "" || !deleted[node.SupersededBy] || deleted[id] || skip[id] {
+			continue
+		}
+		violations = append(violations, ConstraintViolation{
+			NodeID:     id,
+			Constraint: ConstraintStatus,
+			Message:    fmt.Sprintf("superseded_by %s would be deleted", node.SupersededBy),
+		})
+	}
+	return violations
+}
+
+// deprecatedTargetViolations reports the edges of after that before doesn't
+// have and that point at deprecated or superseded nodes, resolving targets
+// through lookup. before is nil for new nodes.
+func deprecatedTargetViolations(before, after *types.Node, lookup func(id string) *types.Node) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for _, relationshipName := range sortedEdgeNames(after.EdgeIDs) {
+		for _, targetID := range after.EdgeIDs[relationshipName] {
+			if before != nil && containsString(before.EdgeIDs[relationshipName], targetID) {
+				continue
+			}
+			target := lookup(targetID)
+			if target == nil || !target.Status.IsDeprecated() {
+				continue
+			}
+			message := fmt.Sprintf("%s cannot point at %s, which is %s", relationshipName, targetID, target.Status)
+			if target.SupersededBy != "" {
+				message += fmt.Sprintf(" by %s", target.SupersededBy)
+			}
+			violations = append(violations, ConstraintViolation{
+				NodeID:       after.ID,
+				Relationship: relationshipName,
+				Constraint:   ConstraintDeprecatedTargets,
+				Message:      message,
+			})
+		}
+	}
+	return violations
+}
+
+// checkDeprecatedTargets returns a ConstraintError if the write adds edges
+// pointing at deprecated nodes without AllowDeprecatedTargets
+// The caller must hold the lock.
+func (m *Manager) checkDeprecatedTargets(before, after *types.Node, options writeOptions) error {
+	if options.allowDeprecatedTargets {
+		return nil
+	}
+	if violations := deprecatedTargetViolations(before, after, m.lookupWith(after)); len(violations) > 0 {
+		return &ConstraintError{Violations: violations}
+	}
+	return nil
+}
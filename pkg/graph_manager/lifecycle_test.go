@@ -0,0 +1,149 @@
+package graph_manager
+
+import (
+	"errors"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// lifecycleTestNodes builds a deploy that depends on a build and a newer
+// build to supersede it with
+func lifecycleTestNodes() []*types.Node {
+	return []*types.Node{
+		{ID: "build-v2", Name: "Build v2"},
+		{ID: "build", Name: "Build"},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"build"}}},
+	}
+}
+
+// supersede marks a node as superseded by its replacement
+func supersede(t *testing.T, manager *Manager, id, replacement string) {
+	t.Helper()
+
+	status := types.StatusSuperseded
+	if _, err := manager.PatchNode(id, NodePatch{Status: &status, SupersededBy: &replacement}); err != nil {
+		t.Fatalf("Failed to supersede %s: %v", id, err)
+	}
+}
+
+// isConstraint reports whether err is a ConstraintError whose first violation
+// is of the given constraint
+func isConstraint(err error, constraint string) bool {
+	var constraintErr *ConstraintError
+	return errors.As(err, &constraintErr) && constraintErr.Violations[0].Constraint == constraint
+}
+
+func TestStatusViolations(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, lifecycleTestNodes()...)
+	supersede(t, manager, "build", "build-v2")
+
+	for name, node := range map[string]*types.Node{
+		"unknown status":        {ID: "a", Name: "A", Status: "retired"},
+		"missing replacement":   {ID: "a", Name: "A", Status: types.StatusSuperseded},
+		"replacement of active": {ID: "a", Name: "A", SupersededBy: "build-v2"},
+		"self replacement":      {ID: "a", Name: "A", Status: types.StatusSuperseded, SupersededBy: "a"},
+		"missing node":          {ID: "a", Name: "A", Status: types.StatusSuperseded, SupersededBy: "build-v3"},
+	} {
+		if err := manager.AddNode(node); !isConstraint(err, ConstraintStatus) {
+			t.Errorf("%s: expected a status violation, got %v", name, err)
+		}
+	}
+
+	if err := manager.AddNode(&types.Node{ID: "a", Name: "A", Status: types.StatusDraft}); err != nil {
+		t.Errorf("Failed to add a draft: %v", err)
+	}
+}
+
+func TestDeprecatedTargetsRefused(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, lifecycleTestNodes()...)
+	supersede(t, manager, "build", "build-v2")
+	release := &types.Node{ID: "release", Name: "Release", EdgeIDs: map[string][]string{"prerequisites": {"build"}}}
+
+	err := manager.AddNode(release)
+	if !isConstraint(err, ConstraintDeprecatedTargets) {
+		t.Fatalf("Expected a deprecated target violation, got %v", err)
+	}
+	if err := manager.AddNode(release, AllowDeprecatedTargets()); err != nil {
+		t.Fatalf("Failed to add with deprecated targets allowed: %v", err)
+	}
+
+	// Edges a node already has are kept when it is updated
+	summary := "Ship it"
+	if _, err := manager.PatchNode("deploy", NodePatch{Summary: &summary}); err != nil {
+		t.Errorf("Expected existing edges to deprecated nodes to be kept, got %v", err)
+	}
+
+	// Adding an edge to a deprecated node is refused in updates and transactions
+	_, err = manager.PatchNode("build-v2", NodePatch{AddEdges: map[string][]string{"related": {"build"}}})
+	if !isConstraint(err, ConstraintDeprecatedTargets) {
+		t.Errorf("Expected a deprecated target violation from the patch, got %v", err)
+	}
+
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "rollback", Name: "Rollback", EdgeIDs: map[string][]string{"related": {"build"}}}); err != nil {
+		t.Fatalf("Failed to stage node: %v", err)
+	}
+	if err := tx.Commit(); !isConstraint(err, ConstraintDeprecatedTargets) {
+		t.Errorf("Expected a deprecated target violation from the transaction, got %v", err)
+	}
+}
+
+func TestResolveSuperseded(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, lifecycleTestNodes()...)
+	supersede(t, manager, "build", "build-v2")
+
+	if err := manager.AddNode(&types.Node{ID: "build-v3", Name: "Build v3"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	supersede(t, manager, "build-v2", "build-v3")
+
+	node, err := manager.ResolveSuperseded("build")
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if node.ID != "build-v3" {
+		t.Errorf("Expected build to resolve to build-v3, got %s", node.ID)
+	}
+	if node, _ := manager.ResolveSuperseded("deploy"); node == nil || node.ID != "deploy" {
+		t.Errorf("Expected an active node to resolve to itself, got %v", node)
+	}
+	if _, err := manager.ResolveSuperseded("missing"); err == nil {
+		t.Error("Expected an error for a missing node")
+	}
+}
+
+func TestDeleteReplacementRefused(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, lifecycleTestNodes()...)
+	supersede(t, manager, "build", "build-v2")
+
+	if err := manager.DeleteNode("build-v2"); !isConstraint(err, ConstraintStatus) {
+		t.Fatalf("Expected a status violation deleting the replacement, got %v", err)
+	}
+	if node, err := manager.ResolveSuperseded("build"); err != nil || node.ID != "build-v2" {
+		t.Errorf("Expected build to still resolve to build-v2, got %v, %v", node, err)
+	}
+
+	tx := manager.Begin()
+	if err := tx.DeleteNode("build-v2"); err != nil {
+		t.Fatalf("Failed to stage delete: %v", err)
+	}
+	if err := tx.Commit(); !isConstraint(err, ConstraintStatus) {
+		t.Errorf("Expected a status violation from the transaction, got %v", err)
+	}
+
+	// Deleting the superseded node along with its replacement is allowed
+	tx = manager.Begin()
+	for _, id := range []string{"deploy", "build", "build-v2"} {
+		if err := tx.DeleteNode(id); err != nil {
+			t.Fatalf("Failed to stage delete of %s: %v", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Failed to delete the superseded node with its replacement: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// LintIssue is a problem in the graph that doesn't stop it from loading but
+// should be fixed in the data
+type LintIssue struct {
+	NodeID       string
+	Relationship string
+	TargetID     string
+	Message      string
+}
+
+// String formats the issue for display
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.NodeID, i.Message)
+}
+
+// Lint checks the graph for problems that validation on load doesn't reject.
+// It reports every edge of a relationship with an inverse whose target lacks
+// the matching edge back, and every violation of the constraints of a node kind
+// or relationship.
+// Issues are ordered by node, relationship and target.
+func (m *Manager) Lint() []LintIssue {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var issues []LintIssue
+	for _, id := range m.sortedNodeIDs() {
+		node := m.nodes[id]
+		for relationshipName, targetIDs := range node.EdgeIDs {
+			inverse, paired := m.inverseOf(relationshipName)
+			if !paired {
+				continue
+			}
+			for _, targetID := range targetIDs {
+				target, exists := m.nodes[targetID]
+				if !exists || containsString(target.EdgeIDs[inverse], id) {
+					continue
+				}
+				issues = append(issues, LintIssue{
+					NodeID:       id,
+					Relationship: relationshipName,
+					TargetID:     targetID,
+					Message: fmt.Sprintf("%s %s, but %s has no %s edge back to %s",
+						relationshipName, targetID, targetID, inverse, id),
+				})
+			}
+		}
+		for _, violation := range m.constraintViolations(node, m.lookupWith()) {
+			issues = append(issues, LintIssue{
+				NodeID:       id,
+				Relationship: violation.Relationship,
+				Message:      violation.Message,
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].NodeID != issues[j].NodeID {
+			return issues[i].NodeID < issues[j].NodeID
+		}
+		if issues[i].Relationship != issues[j].Relationship {
+			return issues[i].Relationship < issues[j].Relationship
+		}
+		return issues[i].TargetID < issues[j].TargetID
+	})
+
+	m.logger.Debug("Linted graph", zap.Int("issues", len(issues)))
+
+	return issues
+}
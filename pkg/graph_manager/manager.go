@@ -2,21 +2,58 @@ package graph_manager
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
+	"common-tasks-mcp/pkg/graph_manager/store"
 	"common-tasks-mcp/pkg/graph_manager/types"
 
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
-// Manager handles node graph operations
+// Manager handles node graph operations.
+// It is safe for concurrent use: reads take a shared lock and see a consistent
+// snapshot of the graph, while mutations are serialized behind an exclusive lock.
+// Nodes handed out by the Manager must be treated as read-only; mutations never
+// modify a published node, its resolved edges included, but replace it with a
+// copy. Nodes with edges pointing directly at a changed node are replaced as
+// well, so every node read after a mutation points at the current versions of
+// its targets; look further hops up by ID, or use Descendants, rather than
+// following the resolved edges of those targets.
 type Manager struct {
+	mu sync.RWMutex
+
 	nodes             map[string]*types.Node
 	relationshipTypes map[string]*types.Relationship
 	tagCache          map[string][]*types.Node
 	logger            *zap.Logger
+
+	// nodeKinds holds the kinds of node the graph may hold, keyed by name.
+	// Replaced, never modified, by SetNodeKinds.
+	nodeKinds map[string]*types.NodeKind
+
+	// attributes holds the attributes nodes may carry, keyed by name.
+	// Replaced, never modified, by SetAttributeDefinitions.
+	attributes map[string]*types.AttributeDefinition
+
+	// incoming maps a target node ID to relationship names to the IDs of the nodes
+	// whose edges point at it. It is built lazily (nil until first needed) and
+	// kept up to date by every mutation.
+	incoming map[string]map[string][]string
+
+	// allowForwardReferences lets transactions stage edges to nodes that are
+	// only added later in the same transaction
+	allowForwardReferences bool
+
+	// dirty holds the IDs of nodes added, changed or removed since the last
+	// persist, so only their files need to be written or removed
+	dirty map[string]bool
+
+	// persistMu serializes persistence and guards store, the backend changes
+	// are flushed to. It is acquired before mu when both are needed.
+	persistMu sync.Mutex
+	store     store.Store
 }
 
 // NewManager creates a new node manager instance with empty relationship registry
@@ -31,8 +68,13 @@ func NewManager(logger *zap.Logger) *Manager {
 }
 
 // AddNode adds a node to the manager.
-// It uses a clone-validate-commit pattern to ensure the addition doesn't introduce cycles.
-func (m *Manager) AddNode(node *types.Node) error {
+// Only the new node's edges are checked for cycles, so the cost of an addition
+// depends on the size of the neighbourhood it touches rather than the whole graph.
+// Attributes the node lacks are set to their defaults on a copy, which is the
+// node stored; use GetNode to read it back.
+// Edges pointing at deprecated or superseded nodes are refused unless
+// AllowDeprecatedTargets is passed; WithExpectedRevision doesn't apply to additions.
+func (m *Manager) AddNode(node *types.Node, opts ...WriteOption) error {
 	m.logger.Debug("Adding node")
 
 	if node == nil {
@@ -43,21 +85,47 @@ func (m *Manager) AddNode(node *types.Node) error {
 		m.logger.Error("Attempted to add node with empty ID")
 		return fmt.Errorf("node ID cannot be empty")
 	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if _, exists := m.nodes[node.ID]; exists {
 		m.logger.Warn("Node already exists", zap.String("node_id", node.ID))
 		return fmt.Errorf("node with ID %s already exists", node.ID)
 	}
+	node = m.withAttributeDefaults(node)
 
-	m.logger.Debug("Validating node addition for cycles", zap.String("node_id", node.ID))
+	if dangling := findDanglingReferences(node, m.nodeExists); dangling != nil {
+		m.logger.Warn("Node addition references missing nodes",
+			zap.String("node_id", node.ID),
+			zap.Strings("missing_ids", dangling.MissingIDs()),
+		)
+		return dangling
+	}
 
-	// Clone the manager to test the addition
-	testManager := m.Clone()
+	if err := m.checkDeprecatedTargets(nil, node, newWriteOptions(opts)); err != nil {
+		m.logger.Warn("Node addition points at deprecated nodes",
+			zap.String("node_id", node.ID),
+			zap.Error(err),
+		)
+		return err
+	}
 
-	// Perform the addition in the test manager
-	testManager.nodes[node.ID] = node
+	// Edges of paired relationships are mirrored on their targets
+	mirrored := m.mirrorInverseEdges(nil, node)
+
+	if err := m.checkConstraints(withMirrored(node, mirrored)...); err != nil {
+		m.logger.Warn("Node addition violates relationship constraints",
+			zap.String("node_id", node.ID),
+			zap.Error(err),
+		)
+		return err
+	}
 
-	// Check for cycles in the test manager
-	if err := testManager.DetectCycles(); err != nil {
+	m.logger.Debug("Validating node addition for cycles", zap.String("node_id", node.ID))
+
+	// Check whether any of the new edges can reach back to where they start
+	if err := m.detectCyclesWith(node, mirrored); err != nil {
 		m.logger.Error("Node addition would introduce cycle",
 			zap.String("node_id", node.ID),
 			zap.Error(err),
@@ -65,12 +133,12 @@ func (m *Manager) AddNode(node *types.Node) error {
 		return fmt.Errorf("addition would introduce cycle: %w", err)
 	}
 
-	// If no cycles detected, commit the addition to the original manager
-	m.nodes[node.ID] = node
-	m.logger.Debug("Node added to internal storage", zap.String("node_id", node.ID))
-
-	// Update tag cache with the new node
-	m.PopulateTagCache()
+	// If no cycles detected, commit the addition
+	m.insertNode(node)
+	for _, replacement := range mirrored {
+		m.replaceNode(replacement.old, replacement.new)
+	}
+	m.refreshEdges(nodeIDs(withMirrored(node, mirrored))...)
 	m.logger.Info("Node added successfully",
 		zap.String("node_id", node.ID),
 		zap.String("node_name", node.Name),
@@ -81,9 +149,12 @@ func (m *Manager) AddNode(node *types.Node) error {
 }
 
 // UpdateNode updates an existing node in the manager.
-// It uses a clone-validate-commit pattern to ensure the update doesn't introduce cycles,
-// and automatically refreshes all node pointers to prevent stale references.
-func (m *Manager) UpdateNode(node *types.Node) error {
+// Only the updated node's edges are checked for cycles, and only the nodes that
+// point at the updated node have their resolved pointers refreshed.
+// Pass WithExpectedRevision to fail with a ConflictError if the node changed meanwhile.
+// New edges pointing at deprecated or superseded nodes are refused unless
+// AllowDeprecatedTargets is passed; edges the node already had are kept.
+func (m *Manager) UpdateNode(node *types.Node, opts ...WriteOption) error {
 	m.logger.Debug("Updating node")
 
 	if node == nil {
@@ -94,69 +165,142 @@ func (m *Manager) UpdateNode(node *types.Node) error {
 		m.logger.Error("Attempted to update node with empty ID")
 		return fmt.Errorf("node ID cannot be empty")
 	}
-	if _, exists := m.nodes[node.ID]; !exists {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.nodes[node.ID]
+	if !exists {
 		m.logger.Warn("Node not found for update", zap.String("node_id", node.ID))
 		return fmt.Errorf("node with ID %s not found", node.ID)
 	}
 
-	m.logger.Debug("Validating node update for cycles", zap.String("node_id", node.ID))
+	options := newWriteOptions(opts)
+	if err := options.checkRevision(existing); err != nil {
+		m.logger.Warn("Node update conflicts with a concurrent change", zap.String("node_id", node.ID), zap.Error(err))
+		return err
+	}
 
-	// Clone the manager to test the update
-	testManager := m.Clone()
+	_, err := m.updateNode(existing, node, options)
+	return err
+}
 
-	// Perform the update in the test manager
-	testManager.nodes[node.ID] = node
+// updateNode validates and stores a new version of an existing node, with the
+// defaults of the attributes it lacks set, and returns the stored node.
+// The caller must hold the write lock.
+func (m *Manager) updateNode(existing, node *types.Node, options writeOptions) (*types.Node, error) {
+	node = m.withAttributeDefaults(node)
 
-	// Check for cycles in the test manager
-	if err := testManager.DetectCycles(); err != nil {
-		m.logger.Error("Node update would introduce cycle",
+	if dangling := findDanglingReferences(node, m.nodeExists); dangling != nil {
+		m.logger.Warn("Node update references missing nodes",
+			zap.String("node_id", node.ID),
+			zap.Strings("missing_ids", dangling.MissingIDs()),
+		)
+		return nil, dangling
+	}
+
+	if err := m.checkDeprecatedTargets(existing, node, options); err != nil {
+		m.logger.Warn("Node update points at deprecated nodes",
 			zap.String("node_id", node.ID),
 			zap.Error(err),
 		)
-		return fmt.Errorf("update would introduce cycle: %w", err)
+		return nil, err
 	}
 
-	// If no cycles detected, commit the update to the original manager
-	m.nodes[node.ID] = node
-	m.logger.Debug("Node updated in internal storage", zap.String("node_id", node.ID))
+	// Edges of paired relationships are mirrored on their targets
+	mirrored := m.mirrorInverseEdges(existing, node)
 
-	// Resolve all node pointers to fix stale references
-	// This ensures that any nodes pointing to the updated node get fresh pointers
-	m.logger.Debug("Resolving node pointers after update")
-	if err := m.ResolveNodePointers(); err != nil {
-		m.logger.Error("Failed to resolve node pointers", zap.Error(err))
-		return err
+	if err := m.checkConstraints(withMirrored(node, mirrored)...); err != nil {
+		m.logger.Warn("Node update violates relationship constraints",
+			zap.String("node_id", node.ID),
+			zap.Error(err),
+		)
+		return nil, err
 	}
 
-	// Update tag cache since tags may have changed
-	m.PopulateTagCache()
+	m.logger.Debug("Validating node update for cycles", zap.String("node_id", node.ID))
+
+	// Check whether any of the changed edges can reach back to where they start
+	if err := m.detectCyclesWith(node, mirrored); err != nil {
+		m.logger.Error("Node update would introduce cycle",
+			zap.String("node_id", node.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("update would introduce cycle: %w", err)
+	}
+
+	// If no cycles detected, commit the update and refresh pointers to the node
+	m.replaceNode(existing, node)
+	for _, replacement := range mirrored {
+		m.replaceNode(replacement.old, replacement.new)
+	}
+	m.refreshEdges(nodeIDs(withMirrored(node, mirrored))...)
 	m.logger.Info("Node updated successfully",
 		zap.String("node_id", node.ID),
 		zap.String("node_name", node.Name),
 	)
 
-	return nil
+	return node, nil
 }
 
 // DeleteNode removes a node from the manager and cleans up all references to it
-// from other nodes' edge lists.
-func (m *Manager) DeleteNode(id string) error {
+// from other nodes' edge lists. Fails with a ConstraintError if a node loses
+// an edge it needs, such as the only target of a min_targets relationship.
+// Pass WithExpectedRevision to fail with a ConflictError if the node changed meanwhile.
+func (m *Manager) DeleteNode(id string, opts ...WriteOption) error {
 	m.logger.Debug("Deleting node", zap.String("node_id", id))
 
 	if id == "" {
 		m.logger.Error("Attempted to delete node with empty ID")
 		return fmt.Errorf("node ID cannot be empty")
 	}
-	if _, exists := m.nodes[id]; !exists {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, exists := m.nodes[id]
+	if !exists {
 		m.logger.Warn("Node not found for deletion", zap.String("node_id", id))
 		return fmt.Errorf("node with ID %s not found", id)
 	}
 
+	if err := newWriteOptions(opts).checkRevision(node); err != nil {
+		m.logger.Warn("Node deletion conflicts with a concurrent change", zap.String("node_id", id), zap.Error(err))
+		return err
+	}
+
+	// Removing the edges to the node must not leave its referrers invalid
+	cleaned := m.cleanReferrers(id)
+	if err := m.checkReferrerConstraints(cleaned, m.lookupWith()); err != nil {
+		m.logger.Warn("Node deletion violates relationship constraints",
+			zap.String("node_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// Nodes it supersedes must not be left without their replacement
+	if violations := m.replacementViolations(map[string]bool{id: true}, nil); len(violations) > 0 {
+		err := &ConstraintError{Violations: violations}
+		m.logger.Warn("Node deletion leaves superseded nodes without their replacement",
+			zap.String("node_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+
 	// Purge the node from the graph (removes all edges and the node itself)
-	m.purgeNode(id)
+	m.purgeNode(id, cleaned)
+
+	// Keep the tag cache and resolved pointers in step with the replaced nodes
+	m.removeFromTagCache(node)
+	cleanedIDs := make([]string, len(cleaned))
+	for i, replacement := range cleaned {
+		m.replaceInTagCache(replacement.old, replacement.new)
+		cleanedIDs[i] = replacement.new.ID
+	}
+	m.refreshEdges(cleanedIDs...)
 
-	// Update tag cache since a node was removed
-	m.PopulateTagCache()
 	m.logger.Info("Node deleted successfully",
 		zap.String("node_id", id),
 		zap.Int("remaining_nodes", len(m.nodes)),
@@ -165,39 +309,52 @@ func (m *Manager) DeleteNode(id string) error {
 	return nil
 }
 
-// purgeNode removes a node from the graph and cleans up all edges pointing to it.
-// This is an internal method used by DeleteNode and other operations.
-// It does NOT validate that the node exists - caller must check.
-func (m *Manager) purgeNode(id string) {
-	m.logger.Debug("Purging node from graph", zap.String("node_id", id))
+// nodeReplacement records a published node that was replaced by a modified copy
+type nodeReplacement struct {
+	old *types.Node
+	new *types.Node
+}
 
+// cleanReferrers returns copies of the nodes referencing a node with every
+// edge to it removed. The copies aren't stored, so the caller can validate them
+// before purging the node.
+// The caller must hold the lock.
+func (m *Manager) cleanReferrers(id string) []nodeReplacement {
 	// Track how many edges were cleaned up
 	edgesRemoved := 0
+	var replacements []nodeReplacement
 
-	// Remove all edges pointing to this node from other nodes
-	for _, node := range m.nodes {
-		if node.ID == id {
-			continue // Skip the node being deleted
+	// Remove all edges pointing to this node from the nodes that reference it
+	for _, sourceID := range m.referencingNodeIDs(id) {
+		node, exists := m.nodes[sourceID]
+		if !exists || sourceID == id {
+			continue // Skip missing sources and the node being deleted
 		}
 
-		// Iterate through all edge types in this node
-		if node.EdgeIDs != nil {
-			for relationshipName, targetIDs := range node.EdgeIDs {
-				// Remove the deleted node's ID from this edge list
-				cleaned := removeStringFromSlice(targetIDs, id)
-
-				// Update if we removed anything
-				if len(cleaned) != len(targetIDs) {
-					node.EdgeIDs[relationshipName] = cleaned
-					edgesRemoved++
-
-					// Also clean up the resolved Edges map if it exists
-					if node.Edges != nil {
-						node.Edges[relationshipName] = removeEdgeByNodeID(node.Edges[relationshipName], id)
-					}
+		// Iterate through all edge types in this node, cleaning a copy on first match
+		var cleanedNode *types.Node
+		for relationshipName, targetIDs := range node.EdgeIDs {
+			// Remove the deleted node's ID from this edge list
+			cleaned := removeStringFromSlice(targetIDs, id)
+
+			// Update if we removed anything
+			if len(cleaned) != len(targetIDs) {
+				if cleanedNode == nil {
+					cleanedNode = copyNodeForWrite(node)
+				}
+				cleanedNode.EdgeIDs[relationshipName] = cleaned
+				edgesRemoved++
+
+				// Also clean up the resolved Edges map if it exists
+				if cleanedNode.Edges != nil {
+					cleanedNode.Edges[relationshipName] = removeEdgeByNodeID(cleanedNode.Edges[relationshipName], id)
 				}
 			}
 		}
+
+		if cleanedNode != nil {
+			replacements = append(replacements, nodeReplacement{old: node, new: cleanedNode})
+		}
 	}
 
 	m.logger.Debug("Removed edges pointing to node",
@@ -205,12 +362,55 @@ func (m *Manager) purgeNode(id string) {
 		zap.Int("edges_removed", edgesRemoved),
 	)
 
-	// Delete the node itself from the graph
+	return replacements
+}
+
+// purgeNode removes a node from the graph and stores the referrers cleaned of
+// their edges to it by cleanReferrers.
+// This is an internal method used by DeleteNode and other operations.
+// It does NOT validate that the node exists - caller must check.
+// The referrers replace the published nodes rather than modifying them, so
+// concurrent readers holding the old nodes are unaffected. The caller
+// refreshes caches and pointers.
+// The caller must hold the write lock.
+func (m *Manager) purgeNode(id string, referrers []nodeReplacement) {
+	m.logger.Debug("Purging node from graph", zap.String("node_id", id))
+
+	for _, referrer := range referrers {
+		m.nodes[referrer.new.ID] = referrer.new
+		m.markDirty(referrer.new.ID)
+	}
+
+	// Delete the node itself from the graph and the incoming edge index
+	if node, exists := m.nodes[id]; exists {
+		m.unindexEdges(node)
+	}
+	if m.incoming != nil {
+		delete(m.incoming, id)
+	}
 	delete(m.nodes, id)
+	m.markDirty(id)
 
 	m.logger.Debug("Node purged from graph", zap.String("node_id", id))
 }
 
+// copyNodeForWrite returns a copy of the node that can be modified without
+// affecting readers of the original. Persisted fields are deep copied and the
+// resolved Edges map is copied shallowly so existing pointers are preserved.
+func copyNodeForWrite(node *types.Node) *types.Node {
+	clone := node.Clone()
+	if clone.EdgeIDs == nil {
+		clone.EdgeIDs = make(map[string][]string)
+	}
+	if node.Edges != nil {
+		clone.Edges = make(map[string][]types.Edge, len(node.Edges))
+		for relationshipName, edges := range node.Edges {
+			clone.Edges[relationshipName] = edges
+		}
+	}
+	return clone
+}
+
 // removeStringFromSlice removes all occurrences of a string from a slice
 func removeStringFromSlice(slice []string, value string) []string {
 	if slice == nil {
@@ -253,6 +453,9 @@ func removeEdgeByNodeID(edges []types.Edge, nodeID string) []types.Edge {
 
 // ListAllNodes returns all nodes in the manager
 func (m *Manager) ListAllNodes() []*types.Node {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	nodes := make([]*types.Node, 0, len(m.nodes))
 	for _, task := range m.nodes {
 		nodes = append(nodes, task)
@@ -266,6 +469,9 @@ func (m *Manager) GetNode(id string) (*types.Node, error) {
 		return nil, fmt.Errorf("node ID cannot be empty")
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	node, exists := m.nodes[id]
 	if !exists {
 		return nil, fmt.Errorf("node with ID %s not found", id)
@@ -274,7 +480,8 @@ func (m *Manager) GetNode(id string) (*types.Node, error) {
 	return node, nil
 }
 
-// getNodes retrieves multiple nodes by their IDs
+// getNodes retrieves multiple nodes by their IDs.
+// The caller must hold the lock.
 func (m *Manager) getNodes(ids []string) ([]*types.Node, error) {
 	if len(ids) == 0 {
 		return []*types.Node{}, nil
@@ -307,46 +514,25 @@ func (m *Manager) getNodes(ids []string) ([]*types.Node, error) {
 // ResolveNodePointers populates the Edges map for all nodes by looking up the
 // corresponding IDs in EdgeIDs and creating Edge objects with resolved pointers.
 // Should be called after loading nodes from disk to restore the pointer relationships.
+// The nodes are resolved in place, so it must not be called once they have been
+// handed out to readers; mutations keep the pointers up to date themselves.
 // Returns an error if any referenced node IDs cannot be found.
 func (m *Manager) ResolveNodePointers() error {
-	m.logger.Debug("Resolving node pointers for all nodes", zap.Int("node_count", len(m.nodes)))
-
-	for _, node := range m.nodes {
-		if node.EdgeIDs == nil {
-			continue
-		}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-		// Initialize Edges map if needed
-		if node.Edges == nil {
-			node.Edges = make(map[string][]types.Edge)
-		}
-
-		// Iterate through all relationship types in this node
-		for relationshipName, targetIDs := range node.EdgeIDs {
-			if len(targetIDs) == 0 {
-				continue
-			}
-
-			// Look up the target nodes
-			targetNodes, err := m.getNodes(targetIDs)
-			if err != nil {
-				return fmt.Errorf("failed to resolve %s for node %s: %w", relationshipName, node.ID, err)
-			}
-
-			// Look up the relationship type (may be nil if not registered)
-			relationship := m.GetRelationship(relationshipName)
+	return m.resolveNodePointers()
+}
 
-			// Create Edge objects for each target
-			edges := make([]types.Edge, len(targetNodes))
-			for i, targetNode := range targetNodes {
-				edges[i] = types.Edge{
-					To:   targetNode,
-					Type: relationship,
-				}
-			}
+// resolveNodePointers resolves the edges of every node in place, so the nodes
+// must not have been published.
+// The caller must hold the write lock.
+func (m *Manager) resolveNodePointers() error {
+	m.logger.Debug("Resolving node pointers for all nodes", zap.Int("node_count", len(m.nodes)))
 
-			// Store the resolved edges
-			node.Edges[relationshipName] = edges
+	for _, node := range m.nodes {
+		if err := m.resolveNodeEdges(node); err != nil {
+			return err
 		}
 	}
 
@@ -362,13 +548,76 @@ func (m *Manager) Clone() *Manager {
 		return nil
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.clone()
+}
+
+// resolveNodeEdges replaces the Edges map of a single node with one resolved
+// from its EdgeIDs. The node must not have been published, since its Edges
+// field is written. Targets that cannot be found are skipped and reported in
+// the returned error.
+// The caller must hold the write lock.
+func (m *Manager) resolveNodeEdges(node *types.Node) error {
+	if node.EdgeIDs == nil {
+		return nil
+	}
+
+	resolved := make(map[string][]types.Edge, len(node.EdgeIDs))
+	var resolveErr error
+
+	// Iterate through all relationship types in this node
+	for relationshipName, targetIDs := range node.EdgeIDs {
+		if len(targetIDs) == 0 {
+			continue
+		}
+
+		// Look up the target nodes
+		targetNodes, err := m.getNodes(targetIDs)
+		if err != nil && resolveErr == nil {
+			resolveErr = fmt.Errorf("failed to resolve %s for node %s: %w", relationshipName, node.ID, err)
+		}
+
+		// Look up the relationship type (may be nil if not registered)
+		relationship := m.getRelationship(relationshipName)
+
+		// Create Edge objects for each target
+		edges := make([]types.Edge, len(targetNodes))
+		for i, targetNode := range targetNodes {
+			edges[i] = types.Edge{
+				To:         targetNode,
+				Type:       relationship,
+				Attributes: node.GetEdgeAttributes(relationshipName, targetNode.ID),
+			}
+		}
+
+		// Store the resolved edges
+		resolved[relationshipName] = edges
+	}
+
+	node.Edges = resolved
+	return resolveErr
+}
+
+// clone is the lock-free implementation of Clone.
+// The caller must hold the lock.
+func (m *Manager) clone() *Manager {
 	m.logger.Debug("Cloning manager", zap.Int("node_count", len(m.nodes)))
 
 	// Create new manager with same logger
 	clone := &Manager{
-		nodes:    make(map[string]*types.Node),
-		tagCache: make(map[string][]*types.Node),
-		logger:   m.logger,
+		nodes:             make(map[string]*types.Node),
+		relationshipTypes: make(map[string]*types.Relationship, len(m.relationshipTypes)),
+		tagCache:          make(map[string][]*types.Node),
+		logger:            m.logger,
+		nodeKinds:         m.nodeKinds,
+		attributes:        m.attributes,
+	}
+
+	// Share the registered relationship definitions so cloned edges keep their types
+	for name, rel := range m.relationshipTypes {
+		clone.relationshipTypes[name] = rel
 	}
 
 	// Clone all nodes
@@ -381,20 +630,32 @@ func (m *Manager) Clone() *Manager {
 	// Note: We ignore errors here because if the original manager was valid,
 	// the clone should also be valid. If there are resolution errors, they
 	// would have existed in the original manager too.
-	_ = clone.ResolveNodePointers()
+	_ = clone.resolveNodePointers()
 
 	// Clone tag cache (we'll just rebuild it)
-	clone.PopulateTagCache()
+	clone.populateTagCache()
 
 	m.logger.Debug("Manager cloned successfully")
 
 	return clone
 }
 
-// DetectCycles checks all relationship types for cycles. Since all relationships form DAGs,
-// any cycle in any relationship type is invalid. Returns an error if any cycles are detected,
-// with detailed information about all cycles found.
+// DetectCycles checks all relationship types for cycles. Relationships form DAGs unless
+// their kind allows cycles, so any cycle in any other relationship type is invalid.
+// The backward and forward relationships must also agree with each other: together
+// they form one execution order, which is reported as an *OrderingConflictError
+// if it orders a node before itself.
+// Returns an error if any cycles are detected, with detailed information about all cycles found.
 func (m *Manager) DetectCycles() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.detectCycles()
+}
+
+// detectCycles is the lock-free implementation of DetectCycles.
+// The caller must hold the lock.
+func (m *Manager) detectCycles() error {
 	m.logger.Debug("Detecting cycles in graph")
 
 	var allCycles []string
@@ -404,7 +665,9 @@ func (m *Manager) DetectCycles() error {
 	for _, node := range m.nodes {
 		if node.EdgeIDs != nil {
 			for relationshipName := range node.EdgeIDs {
-				relationshipTypes[relationshipName] = true
+				if !m.allowsCycles(relationshipName) {
+					relationshipTypes[relationshipName] = true
+				}
 			}
 		}
 	}
@@ -438,9 +701,151 @@ func (m *Manager) DetectCycles() error {
 		return fmt.Errorf("%s", msg)
 	}
 
+	// Each relationship is acyclic, but together they may still contradict each other
+	if err := m.detectOrderingCycles(); err != nil {
+		m.logger.Error("Contradictory execution order in graph", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// detectCyclesFrom checks whether the given node's edges introduce a cycle.
+// For every edge node -> target it searches the same relationship from the target
+// for a path back to the node. Any new cycle must pass through one of the node's
+// edges, so the search only explores the part of the graph reachable from them.
+// The lookup resolves node IDs to their (possibly staged) state.
+// The caller must hold the lock.
+func (m *Manager) detectCyclesFrom(node *types.Node, lookup func(id string) *types.Node) error {
+	var cycles []string
+
+	relationshipNames := make([]string, 0, len(node.EdgeIDs))
+	for relationshipName := range node.EdgeIDs {
+		if !m.allowsCycles(relationshipName) {
+			relationshipNames = append(relationshipNames, relationshipName)
+		}
+	}
+	sort.Strings(relationshipNames)
+
+	for _, relationshipName := range relationshipNames {
+		for _, targetID := range node.EdgeIDs[relationshipName] {
+			if path := findPath(targetID, node.ID, relationshipName, lookup); path != nil {
+				cycle := append([]string{node.ID}, path...)
+				cycles = append(cycles, fmt.Sprintf("%s: %s", relationshipName, strings.Join(cycle, " -> ")))
+				break // One cycle per relationship is enough to reject the change
+			}
+		}
+	}
+
+	if len(cycles) > 0 {
+		msg := fmt.Sprintf("detected %d cycle(s):\n", len(cycles))
+		for i, cycle := range cycles {
+			msg += fmt.Sprintf("  %d. %s\n", i+1, cycle)
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
 	return nil
 }
 
+// allowsCycles reports whether a relationship is registered with a kind that
+// permits cycles. Unregistered relationships are treated as DAGs.
+// The caller must hold the lock.
+func (m *Manager) allowsCycles(relationshipName string) bool {
+	rel, exists := m.relationshipTypes[relationshipName]
+	return exists && rel.AllowsCycles()
+}
+
+// findPath performs a breadth-first search from one node to another following a
+// single relationship. Returns the path including both endpoints, or nil if the
+// destination is unreachable.
+func findPath(fromID, toID, relationshipName string, lookup func(id string) *types.Node) []string {
+	parents := map[string]string{fromID: ""}
+	queue := []string{fromID}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current == toID {
+			// Walk the parent chain back to the start
+			var path []string
+			for id := current; id != ""; id = parents[id] {
+				path = append([]string{id}, path...)
+			}
+			return path
+		}
+
+		node := lookup(current)
+		if node == nil {
+			continue
+		}
+		for _, nextID := range node.EdgeIDs[relationshipName] {
+			if _, seen := parents[nextID]; !seen {
+				parents[nextID] = current
+				queue = append(queue, nextID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupWith returns a node lookup over the current graph in which the given
+// nodes replace any stored nodes with the same IDs.
+// The caller must hold the lock.
+func (m *Manager) lookupWith(nodes ...*types.Node) func(id string) *types.Node {
+	return func(id string) *types.Node {
+		for _, node := range nodes {
+			if id == node.ID {
+				return node
+			}
+		}
+		return m.nodes[id]
+	}
+}
+
+// detectCyclesWith checks the edges of a changed node, and of the nodes whose
+// inverse edges change with it, for cycles through the changed nodes, both
+// within each relationship and in the execution order they combine into.
+// The caller must hold the write lock.
+func (m *Manager) detectCyclesWith(node *types.Node, mirrored []nodeReplacement) error {
+	changed := withMirrored(node, mirrored)
+	lookup := m.lookupWith(changed...)
+	for _, n := range changed {
+		if err := m.detectCyclesFrom(n, lookup); err != nil {
+			return err
+		}
+	}
+	return m.detectOrderingConflicts(changed, lookup)
+}
+
+// nodeIDs returns the IDs of the nodes
+func nodeIDs(nodes []*types.Node) []string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
+
+// withMirrored returns a changed node followed by the new versions of the nodes
+// whose inverse edges change with it
+func withMirrored(node *types.Node, mirrored []nodeReplacement) []*types.Node {
+	nodes := []*types.Node{node}
+	for _, replacement := range mirrored {
+		nodes = append(nodes, replacement.new)
+	}
+	return nodes
+}
+
+// nodeExists reports whether a node with the given ID is stored.
+// The caller must hold the lock.
+func (m *Manager) nodeExists(id string) bool {
+	_, exists := m.nodes[id]
+	return exists
+}
+
 // detectCyclesInDAG performs cycle detection on a specific DAG using DFS
 // Returns a slice of cycle descriptions (e.g., "node-a -> node-b -> node-c -> node-a")
 func (m *Manager) detectCyclesInDAG(dagName string, getEdges func(*types.Node) []string) []string {
@@ -509,131 +914,8 @@ func (m *Manager) findCyclesDFS(nodeID string, visited, recStack map[string]bool
 	*path = (*path)[:len(*path)-1]
 }
 
-// LoadNodesFromDir reads all YAML files from the specified directory and loads nodes
+// LoadNodesFromDir reads all YAML files from the specified directory and loads nodes.
+// The directory becomes the manager's store, so Flush writes changes back to it.
 func (m *Manager) LoadNodesFromDir(dirPath string) error {
-	m.logger.Info("Loading nodes from directory", zap.String("path", dirPath))
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		m.logger.Error("Failed to create directory", zap.String("path", dirPath), zap.Error(err))
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Read all .yaml files in the directory
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		m.logger.Error("Failed to read directory", zap.String("path", dirPath), zap.Error(err))
-		return fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	m.logger.Debug("Found directory entries", zap.Int("count", len(entries)))
-
-	nodesLoaded := 0
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
-			m.logger.Debug("Skipping non-YAML file", zap.String("filename", entry.Name()))
-			continue
-		}
-
-		filename := filepath.Join(dirPath, entry.Name())
-		m.logger.Debug("Reading node file", zap.String("filename", filename))
-
-		data, err := os.ReadFile(filename)
-		if err != nil {
-			m.logger.Error("Failed to read file", zap.String("filename", entry.Name()), zap.Error(err))
-			return fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
-		}
-
-		var node types.Node
-		if err := yaml.Unmarshal(data, &node); err != nil {
-			m.logger.Error("Failed to unmarshal node",
-				zap.String("filename", entry.Name()),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to unmarshal node from %s: %w", entry.Name(), err)
-		}
-
-		m.nodes[node.ID] = &node
-		nodesLoaded++
-		m.logger.Debug("Loaded node from file",
-			zap.String("node_id", node.ID),
-			zap.String("node_name", node.Name),
-			zap.String("filename", entry.Name()),
-		)
-	}
-
-	m.logger.Info("Finished loading node files", zap.Int("nodes_loaded", nodesLoaded))
-
-	// Detect cycles before resolving pointers
-	m.logger.Debug("Detecting cycles in node graph")
-	if err := m.DetectCycles(); err != nil {
-		m.logger.Error("Cycle detected in node graph", zap.Error(err))
-		return fmt.Errorf("cycle detected in node graph: %w", err)
-	}
-	m.logger.Debug("No cycles detected")
-
-	// Resolve node pointers after loading all nodes and validating no cycles
-	m.logger.Debug("Resolving node pointers")
-	if err := m.ResolveNodePointers(); err != nil {
-		m.logger.Error("Failed to resolve node pointers", zap.Error(err))
-		return err
-	}
-	m.logger.Debug("Node pointers resolved")
-
-	// Populate tag cache for efficient tag-based lookups
-	m.logger.Debug("Populating tag cache")
-	m.PopulateTagCache()
-
-	m.logger.Info("Successfully loaded nodes from directory",
-		zap.String("path", dirPath),
-		zap.Int("total_nodes", len(m.nodes)),
-	)
-
-	return nil
-}
-
-// PersistToDir writes all nodes to the specified directory as YAML files
-func (m *Manager) PersistToDir(dirPath string) error {
-	m.logger.Info("Persisting nodes to directory",
-		zap.String("path", dirPath),
-		zap.Int("node_count", len(m.nodes)),
-	)
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		m.logger.Error("Failed to create directory", zap.String("path", dirPath), zap.Error(err))
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Write each node as a separate YAML file
-	nodesPersisted := 0
-	for id, node := range m.nodes {
-		filename := filepath.Join(dirPath, fmt.Sprintf("%s.yaml", id))
-
-		m.logger.Debug("Marshaling node", zap.String("node_id", id))
-		data, err := yaml.Marshal(node)
-		if err != nil {
-			m.logger.Error("Failed to marshal node", zap.String("node_id", id), zap.Error(err))
-			return fmt.Errorf("failed to marshal node %s: %w", id, err)
-		}
-
-		m.logger.Debug("Writing node file", zap.String("filename", filename))
-		if err := os.WriteFile(filename, data, 0644); err != nil {
-			m.logger.Error("Failed to write node file",
-				zap.String("node_id", id),
-				zap.String("filename", filename),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to write node %s: %w", id, err)
-		}
-
-		nodesPersisted++
-	}
-
-	m.logger.Info("Successfully persisted nodes to directory",
-		zap.String("path", dirPath),
-		zap.Int("nodes_persisted", nodesPersisted),
-	)
-
-	return nil
+	return m.LoadFromStore(store.NewDirStore(dirPath, m.logger))
 }
@@ -0,0 +1,181 @@
+package graph_manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// Mutation benchmarks run against graphs of increasing size. Validation only
+// visits the neighbourhood of a change and an update only replaces the nodes
+// pointing directly at it, so the cost of a mutation grows with its number of
+// direct dependents rather than with the size of the graph:
+//
+//	go test -run '^$' -bench 'AddNode|UpdateNode' ./pkg/graph_manager
+
+var benchmarkGraphSizes = []int{100, 1000, 10000}
+
+// buildBenchmarkManager creates a graph made of independent ten-step workflows,
+// where every step has the previous step of its workflow as a prerequisite.
+func buildBenchmarkManager(b *testing.B, size int) *Manager {
+	b.Helper()
+
+	manager := NewManager(logger.NewNop())
+	if err := manager.RegisterRelationship(types.Relationship{
+		Name:        "prerequisites",
+		Description: "Tasks that must complete before this one",
+		Direction:   types.DirectionBackward,
+	}); err != nil {
+		b.Fatalf("Failed to register relationship: %v", err)
+	}
+
+	now := time.Now()
+	for i := 0; i < size; i++ {
+		node := &types.Node{
+			ID:        fmt.Sprintf("node-%d", i),
+			Name:      fmt.Sprintf("Node %d", i),
+			Tags:      []string{fmt.Sprintf("workflow-%d", i/10)},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if i%10 != 0 {
+			node.EdgeIDs = map[string][]string{"prerequisites": {fmt.Sprintf("node-%d", i-1)}}
+		}
+		if err := manager.AddNode(node); err != nil {
+			b.Fatalf("Failed to add node: %v", err)
+		}
+	}
+
+	return manager
+}
+
+func BenchmarkAddNode(b *testing.B) {
+	for _, size := range benchmarkGraphSizes {
+		b.Run(fmt.Sprintf("nodes=%d", size), func(b *testing.B) {
+			manager := buildBenchmarkManager(b, size)
+			now := time.Now()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				node := &types.Node{
+					ID:        "new-node",
+					Name:      "New Node",
+					Tags:      []string{"new"},
+					EdgeIDs:   map[string][]string{"prerequisites": {fmt.Sprintf("node-%d", size-1)}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				}
+				if err := manager.AddNode(node); err != nil {
+					b.Fatalf("Failed to add node: %v", err)
+				}
+
+				// Restore the original graph outside of the measured section
+				b.StopTimer()
+				if err := manager.DeleteNode("new-node"); err != nil {
+					b.Fatalf("Failed to delete node: %v", err)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func BenchmarkUpdateNode(b *testing.B) {
+	for _, size := range benchmarkGraphSizes {
+		b.Run(fmt.Sprintf("nodes=%d", size), func(b *testing.B) {
+			manager := buildBenchmarkManager(b, size)
+
+			// Update a step in the middle of the last workflow so it has both
+			// outgoing edges to validate and incoming pointers to refresh
+			id := fmt.Sprintf("node-%d", size-5)
+			original, err := manager.GetNode(id)
+			if err != nil {
+				b.Fatalf("Failed to get node: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				updated := original.Clone()
+				updated.Summary = fmt.Sprintf("revision %d", i)
+				updated.UpdatedAt = time.Now()
+				if err := manager.UpdateNode(updated); err != nil {
+					b.Fatalf("Failed to update node: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// buildDependentsBenchmarkManager creates a graph where every node depends on
+// node-0, either directly (fan-in) or through a single chain where every node
+// has the previous one as a prerequisite.
+func buildDependentsBenchmarkManager(b *testing.B, size int, chain bool) *Manager {
+	b.Helper()
+
+	manager := NewManager(logger.NewNop())
+	if err := manager.RegisterRelationship(types.Relationship{
+		Name:        "prerequisites",
+		Description: "Tasks that must complete before this one",
+		Direction:   types.DirectionBackward,
+	}); err != nil {
+		b.Fatalf("Failed to register relationship: %v", err)
+	}
+
+	now := time.Now()
+	for i := 0; i < size; i++ {
+		node := &types.Node{
+			ID:        fmt.Sprintf("node-%d", i),
+			Name:      fmt.Sprintf("Node %d", i),
+			Tags:      []string{fmt.Sprintf("workflow-%d", i/10)},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		switch {
+		case i == 0:
+		case chain:
+			node.EdgeIDs = map[string][]string{"prerequisites": {fmt.Sprintf("node-%d", i-1)}}
+		default:
+			node.EdgeIDs = map[string][]string{"prerequisites": {"node-0"}}
+		}
+		if err := manager.AddNode(node); err != nil {
+			b.Fatalf("Failed to add node: %v", err)
+		}
+	}
+
+	return manager
+}
+
+func BenchmarkUpdateNodeWithDependents(b *testing.B) {
+	for _, shape := range []struct {
+		name  string
+		chain bool
+	}{
+		// Every other node has to be replaced to point at the update
+		{name: "direct", chain: false},
+		// Only the next node of the chain has to be replaced
+		{name: "transitive", chain: true},
+	} {
+		for _, size := range benchmarkGraphSizes {
+			b.Run(fmt.Sprintf("dependents=%s/nodes=%d", shape.name, size), func(b *testing.B) {
+				manager := buildDependentsBenchmarkManager(b, size, shape.chain)
+				original, err := manager.GetNode("node-0")
+				if err != nil {
+					b.Fatalf("Failed to get node: %v", err)
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					updated := original.Clone()
+					updated.Summary = fmt.Sprintf("revision %d", i)
+					updated.UpdatedAt = time.Now()
+					if err := manager.UpdateNode(updated); err != nil {
+						b.Fatalf("Failed to update node: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
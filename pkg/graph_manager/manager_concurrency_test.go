@@ -0,0 +1,310 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// These tests are most useful when run with the race detector:
+//
+//	go test -race ./pkg/graph_manager/...
+
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, &types.Node{ID: "root", Name: "Root", Tags: []string{"shared"}})
+
+	const (
+		writers    = 8
+		readers    = 8
+		iterations = 50
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, (writers+1)*iterations)
+
+	// Another writer keeps updating the root, so every node pointing at it has
+	// its resolved edges refreshed while readers walk them
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			summary := fmt.Sprintf("revision %d", i)
+			if _, err := manager.PatchNode("root", NodePatch{Summary: &summary}); err != nil {
+				errs <- fmt.Errorf("patch root: %w", err)
+			}
+		}
+	}()
+
+	// Writers add, update and delete their own nodes, all pointing at the shared root
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := fmt.Sprintf("writer-%d-node-%d", w, i)
+				now := time.Now()
+
+				node := &types.Node{
+					ID:        id,
+					Name:      "Node " + id,
+					Tags:      []string{"shared", fmt.Sprintf("writer-%d", w)},
+					EdgeIDs:   map[string][]string{"prerequisites": {"root"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				}
+				if err := manager.AddNode(node); err != nil {
+					errs <- fmt.Errorf("add %s: %w", id, err)
+					continue
+				}
+
+				updated := node.Clone()
+				updated.Summary = "updated"
+				updated.UpdatedAt = time.Now()
+				if err := manager.UpdateNode(updated); err != nil {
+					errs <- fmt.Errorf("update %s: %w", id, err)
+					continue
+				}
+
+				// Delete every other node so deletions race with reads of neighbours
+				if i%2 == 0 {
+					if err := manager.DeleteNode(id); err != nil {
+						errs <- fmt.Errorf("delete %s: %w", id, err)
+					}
+				}
+			}
+		}(w)
+	}
+
+	// Readers walk every node they are handed, touching all persisted fields and
+	// following the resolved edges
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				for _, node := range manager.ListAllNodes() {
+					_ = node.Name + node.Summary
+					for relationshipName, ids := range node.EdgeIDs {
+						_ = len(ids)
+						for _, edge := range node.GetEdges(relationshipName) {
+							_ = edge.To.Name + edge.To.Summary
+						}
+					}
+				}
+
+				tagged, err := manager.GetNodesByTag("shared")
+				if err != nil {
+					errs <- err
+					continue
+				}
+				for _, node := range tagged {
+					_ = len(node.Tags)
+				}
+
+				if _, err := manager.GetNode("root"); err != nil {
+					errs <- err
+				}
+
+				_ = manager.GetAllTags()
+				_ = manager.GetAllRelationships()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Unexpected error during concurrent access: %v", err)
+	}
+
+	// Every writer deleted half of its nodes; the root plus the survivors remain
+	expected := 1 + writers*iterations/2
+	if got := len(manager.ListAllNodes()); got != expected {
+		t.Errorf("Expected %d nodes after concurrent mutations, got %d", expected, got)
+	}
+}
+
+func TestConcurrentTagReadsAreSnapshotConsistent(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, &types.Node{ID: "root", Name: "Root", Tags: []string{"shared"}})
+
+	now := time.Now()
+	node := &types.Node{
+		ID:        "toggler",
+		Name:      "Toggler",
+		Tags:      []string{"on"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := manager.AddNode(node); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Writer keeps toggling the node's tag between "on" and "off"
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			updated := node.Clone()
+			if i%2 == 0 {
+				updated.Tags = []string{"off"}
+			} else {
+				updated.Tags = []string{"on"}
+			}
+			if err := manager.UpdateNode(updated); err != nil {
+				t.Errorf("Failed to update node: %v", err)
+				break
+			}
+		}
+		close(stop)
+	}()
+
+	// Readers must never see a node under a tag it doesn't carry
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				for _, tag := range []string{"on", "off"} {
+					nodes, err := manager.GetNodesByTag(tag)
+					if err != nil {
+						t.Errorf("Failed to get nodes by tag: %v", err)
+						return
+					}
+					for _, n := range nodes {
+						if len(n.Tags) != 1 || n.Tags[0] != tag {
+							t.Errorf("Node %s returned for tag %s but has tags %v", n.ID, tag, n.Tags)
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestDeleteDoesNotMutatePublishedNodes(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, &types.Node{ID: "root", Name: "Root", Tags: []string{"shared"}})
+
+	now := time.Now()
+	child := &types.Node{
+		ID:        "child",
+		Name:      "Child",
+		EdgeIDs:   map[string][]string{"prerequisites": {"root"}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := manager.AddNode(child); err != nil {
+		t.Fatalf("Failed to add child: %v", err)
+	}
+
+	// A reader holding the published node should keep its original view
+	held, err := manager.GetNode("child")
+	if err != nil {
+		t.Fatalf("Failed to get child: %v", err)
+	}
+
+	if err := manager.DeleteNode("root"); err != nil {
+		t.Fatalf("Failed to delete root: %v", err)
+	}
+
+	if ids := held.GetEdgeIDs("prerequisites"); len(ids) != 1 || ids[0] != "root" {
+		t.Errorf("Held node was mutated by delete: prerequisites = %v", ids)
+	}
+
+	current, err := manager.GetNode("child")
+	if err != nil {
+		t.Fatalf("Failed to get child after delete: %v", err)
+	}
+	if ids := current.GetEdgeIDs("prerequisites"); len(ids) != 0 {
+		t.Errorf("Expected current child to have no prerequisites, got %v", ids)
+	}
+}
+
+func TestUpdateDoesNotMutatePublishedReferrers(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	addTestNodes(t, manager, &types.Node{ID: "root", Name: "Root", Tags: []string{"shared"}})
+
+	now := time.Now()
+	for _, node := range []*types.Node{
+		{ID: "child", Name: "Child", EdgeIDs: map[string][]string{"prerequisites": {"root"}}, CreatedAt: now, UpdatedAt: now},
+		{ID: "grandchild", Name: "Grandchild", EdgeIDs: map[string][]string{"prerequisites": {"child"}}, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add %s: %v", node.ID, err)
+		}
+	}
+
+	// A reader keeps following the edges of the nodes it was handed, without
+	// going back to the manager, while the root is updated
+	held, err := manager.GetNode("grandchild")
+	if err != nil {
+		t.Fatalf("Failed to get grandchild: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, edge := range held.GetEdges("prerequisites") {
+				for _, next := range edge.To.GetEdges("prerequisites") {
+					_ = next.To.Summary
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < 2000; i++ {
+		summary := fmt.Sprintf("revision %d", i)
+		if _, err := manager.PatchNode("root", NodePatch{Summary: &summary}); err != nil {
+			t.Fatalf("Failed to patch root: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if edges := held.GetEdges("prerequisites"); len(edges) != 1 || edges[0].To.GetEdges("prerequisites")[0].To.Summary != "" {
+		t.Error("Held nodes should keep pointing at the root they were published with")
+	}
+
+	// Nodes read after the update point at the current version of their
+	// direct targets, and traversals reach the current version further away
+	child, err := manager.GetNode("child")
+	if err != nil {
+		t.Fatalf("Failed to get child: %v", err)
+	}
+	if root := child.GetEdges("prerequisites")[0].To; root.Summary != "revision 1999" {
+		t.Errorf("Expected the current child to reach the current root, got summary %q", root.Summary)
+	}
+	descendants, err := manager.Descendants("grandchild", "prerequisites", 0)
+	if err != nil {
+		t.Fatalf("Failed to get descendants: %v", err)
+	}
+	if len(descendants) != 2 || descendants[1].Node.Summary != "revision 1999" {
+		t.Errorf("Expected the grandchild's descendants to end at the current root, got %v", descendants)
+	}
+}
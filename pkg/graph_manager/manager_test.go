@@ -1,8 +1,10 @@
 package graph_manager
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +12,37 @@ import (
 	"common-tasks-mcp/pkg/logger"
 )
 
+// workflowRelationships are the relationships of a task workflow
+var workflowRelationships = []types.Relationship{
+	{Name: "prerequisites", Direction: types.DirectionBackward},
+	{Name: "downstream_required", Direction: types.DirectionForward},
+	{Name: "related", Direction: types.DirectionNone},
+}
+
+// newTestManager creates a manager with the given relationships registered
+func newTestManager(t *testing.T, relationships ...types.Relationship) *Manager {
+	t.Helper()
+
+	manager := NewManager(logger.NewNop())
+	for _, rel := range relationships {
+		if err := manager.RegisterRelationship(rel); err != nil {
+			t.Fatalf("Failed to register relationship %s: %v", rel.Name, err)
+		}
+	}
+	return manager
+}
+
+// addTestNodes adds the nodes in order
+func addTestNodes(t *testing.T, manager *Manager, nodes ...*types.Node) {
+	t.Helper()
+
+	for _, node := range nodes {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+}
+
 func TestPersistAndLoad(t *testing.T) {
 	// Create a temporary directory for test files
 	testDir := filepath.Join(t.TempDir(), "tasks")
@@ -224,6 +257,27 @@ func TestAddNode(t *testing.T) {
 			expectedError: "addition would introduce cycle",
 			expectedCount: 1,
 		},
+		{
+			name: "add node with dangling reference",
+			setupNodes: []*types.Node{
+				{
+					ID:        "prereq-1",
+					Name:      "Prerequisite",
+					CreatedAt: now,
+					UpdatedAt: now,
+				},
+			},
+			nodeToAdd: &types.Node{
+				ID:        "task-2",
+				Name:      "Task with Missing Prereq",
+				EdgeIDs:   map[string][]string{"prerequisites": {"prereq-1", "missing-1"}},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			wantError:     true,
+			expectedError: "references to missing nodes: task-2 -> prerequisites: missing-1",
+			expectedCount: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -926,3 +980,163 @@ func TestResolveNodePointersWithUnregisteredRelationship(t *testing.T) {
 		t.Error("Edge.Type should be nil for unregistered relationship")
 	}
 }
+
+func TestUpdateNodeCycleDetection(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name          string
+		update        *types.Node
+		wantError     bool
+		expectedError string
+	}{
+		{
+			name: "update closing a three-node cycle",
+			update: &types.Node{
+				ID:        "task-c",
+				Name:      "Task C",
+				EdgeIDs:   map[string][]string{"prerequisites": {"task-a"}},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			wantError:     true,
+			expectedError: "prerequisites: task-c -> task-a -> task-b -> task-c",
+		},
+		{
+			name: "self reference",
+			update: &types.Node{
+				ID:        "task-b",
+				Name:      "Task B",
+				EdgeIDs:   map[string][]string{"prerequisites": {"task-b"}},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			wantError:     true,
+			expectedError: "prerequisites: task-b -> task-b",
+		},
+		{
+			name: "same edges in another relationship are not a cycle",
+			update: &types.Node{
+				ID:        "task-c",
+				Name:      "Task C",
+				EdgeIDs:   map[string][]string{"related_to": {"task-a"}},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			wantError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := logger.New(false)
+			manager := NewManager(log)
+
+			// task-a -> task-b -> task-c via prerequisites
+			for _, node := range []*types.Node{
+				{ID: "task-c", Name: "Task C", CreatedAt: now, UpdatedAt: now},
+				{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-c"}}, CreatedAt: now, UpdatedAt: now},
+				{ID: "task-a", Name: "Task A", EdgeIDs: map[string][]string{"prerequisites": {"task-b"}}, CreatedAt: now, UpdatedAt: now},
+			} {
+				if err := manager.AddNode(node); err != nil {
+					t.Fatalf("Failed to add node %s: %v", node.ID, err)
+				}
+			}
+
+			err := manager.UpdateNode(tt.update)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("Expected error but got nil")
+				}
+				if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				if manager.nodes[tt.update.ID] == tt.update {
+					t.Error("Rejected update should not be stored")
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMutationsRefreshReferringPointers(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A", Tags: []string{"base"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}, CreatedAt: now, UpdatedAt: now},
+		{ID: "task-c", Name: "Task C", EdgeIDs: map[string][]string{"prerequisites": {"task-a", "task-b"}}, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	// Updating task-a must re-point task-b and task-c at the new version
+	updatedA := &types.Node{ID: "task-a", Name: "Task A v2", Tags: []string{"updated"}, CreatedAt: now, UpdatedAt: now}
+	if err := manager.UpdateNode(updatedA); err != nil {
+		t.Fatalf("Failed to update task-a: %v", err)
+	}
+	if edges := manager.nodes["task-b"].GetEdges("prerequisites"); len(edges) != 1 || edges[0].To != updatedA {
+		t.Error("task-b should point at the updated task-a")
+	}
+	if nodes, _ := manager.GetNodesByTag("base"); len(nodes) != 0 {
+		t.Errorf("Old tag should be removed from cache, got %d nodes", len(nodes))
+	}
+	if nodes, _ := manager.GetNodesByTag("updated"); len(nodes) != 1 || nodes[0] != updatedA {
+		t.Error("Updated node should be cached under its new tag")
+	}
+
+	// Deleting task-a replaces task-b with a cleaned copy, which task-c must point at
+	if err := manager.DeleteNode("task-a"); err != nil {
+		t.Fatalf("Failed to delete task-a: %v", err)
+	}
+	currentB := manager.nodes["task-b"]
+	edges := manager.nodes["task-c"].GetEdges("prerequisites")
+	if len(edges) != 1 || edges[0].To != currentB {
+		t.Error("task-c should point at the cleaned copy of task-b")
+	}
+	if len(currentB.GetEdges("prerequisites")) != 0 {
+		t.Error("task-b should have no resolved prerequisites after task-a is deleted")
+	}
+}
+
+func TestUpdateNodeRejectsDanglingReferences(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	original := &types.Node{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now}
+	if err := manager.AddNode(original); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	err := manager.UpdateNode(&types.Node{
+		ID:   "task-a",
+		Name: "Task A",
+		EdgeIDs: map[string][]string{
+			"prerequisites": {"missing-1"},
+			"related_to":    {"missing-2", "missing-1"},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+
+	var dangling *DanglingReferenceError
+	if !errors.As(err, &dangling) {
+		t.Fatalf("Expected DanglingReferenceError, got %v", err)
+	}
+	if ids := dangling.MissingIDs(); len(ids) != 2 || ids[0] != "missing-1" || ids[1] != "missing-2" {
+		t.Errorf("Expected missing IDs [missing-1 missing-2], got %v", ids)
+	}
+	if missing := dangling.Missing["task-a"]["related_to"]; len(missing) != 2 {
+		t.Errorf("Expected two missing related_to targets, got %v", missing)
+	}
+	if manager.nodes["task-a"] != original {
+		t.Error("Rejected update should not be stored")
+	}
+}
@@ -0,0 +1,51 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// SetNodeKinds replaces the kinds of node the graph may hold. Nodes of a kind
+// must have its required fields and may only use its relationships, and nodes
+// with a kind that isn't set are rejected. Nodes without a kind are not
+// restricted. Stored nodes are not checked; Lint reports the ones that break
+// the new kinds.
+func (m *Manager) SetNodeKinds(kinds []types.NodeKind) error {
+	nodeKinds := make(map[string]*types.NodeKind, len(kinds))
+	for _, kind := range kinds {
+		if err := kind.Validate(); err != nil {
+			m.logger.Error("Invalid node kind", zap.String("name", kind.Name), zap.Error(err))
+			return fmt.Errorf("invalid node kind %s: %w", kind.Name, err)
+		}
+		if _, exists := nodeKinds[kind.Name]; exists {
+			return fmt.Errorf("node kind %s declared more than once", kind.Name)
+		}
+		kind := kind
+		nodeKinds[kind.Name] = &kind
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nodeKinds = nodeKinds
+	m.logger.Info("Set node kinds", zap.Int("count", len(nodeKinds)))
+
+	return nil
+}
+
+// GetNodeKinds returns the kinds of node the graph may hold, ordered by name
+func (m *Manager) GetNodeKinds() []types.NodeKind {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	kinds := make([]types.NodeKind, 0, len(m.nodeKinds))
+	for _, kind := range m.nodeKinds {
+		kinds = append(kinds, *kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Name < kinds[j].Name })
+	return kinds
+}
@@ -0,0 +1,154 @@
+package graph_manager
+
+import (
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// Services and runbooks, where only services depend on services and runbooks
+// are attached to services
+var (
+	nodeKindRelationships = []types.Relationship{
+		{Name: "depends_on", Direction: types.DirectionBackward, Constraints: types.RelationshipConstraints{
+			SourceKinds: []string{"service"},
+			TargetKinds: []string{"service"},
+		}},
+		{Name: "runbooks", Direction: types.DirectionNone, Constraints: types.RelationshipConstraints{
+			TargetKinds: []string{"runbook"},
+		}},
+		{Name: "related", Direction: types.DirectionNone},
+	}
+	testNodeKinds = []types.NodeKind{
+		{Name: "service", RequiredFields: []string{"summary"}},
+		{Name: "runbook", Relationships: []string{"related"}},
+	}
+)
+
+// nodeKindTestNodes returns a service, a runbook and a node without a kind
+func nodeKindTestNodes() []*types.Node {
+	return []*types.Node{
+		{ID: "db", Kind: "service", Summary: "Database"},
+		{ID: "restart-db", Kind: "runbook"},
+		{ID: "notes"},
+	}
+}
+
+func TestSetNodeKinds(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+
+	if err := manager.SetNodeKinds([]types.NodeKind{{Name: "service", RequiredFields: []string{"owner"}}}); err == nil {
+		t.Error("Expected an unknown required field to be rejected")
+	}
+	if err := manager.SetNodeKinds([]types.NodeKind{{Name: "service"}, {Name: "service"}}); err == nil {
+		t.Error("Expected a duplicate kind to be rejected")
+	}
+	if err := manager.SetNodeKinds([]types.NodeKind{{Name: "service"}, {Name: "runbook"}}); err != nil {
+		t.Fatalf("SetNodeKinds() error = %v", err)
+	}
+
+	var names []string
+	for _, kind := range manager.GetNodeKinds() {
+		names = append(names, kind.Name)
+	}
+	if !slices.Equal(names, []string{"runbook", "service"}) {
+		t.Errorf("GetNodeKinds() = %v, want [runbook service]", names)
+	}
+}
+
+func TestNodeKindConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		node *types.Node
+		want []string
+	}{
+		{
+			name: "valid service",
+			node: &types.Node{ID: "api", Kind: "service", Summary: "API", EdgeIDs: map[string][]string{
+				"depends_on": {"db"},
+				"runbooks":   {"restart-db"},
+			}},
+		},
+		{
+			name: "untyped node",
+			node: &types.Node{ID: "api", EdgeIDs: map[string][]string{"related": {"db"}}},
+		},
+		{
+			name: "unknown kind",
+			node: &types.Node{ID: "api", Kind: "team"},
+			want: []string{".kind"},
+		},
+		{
+			name: "missing required field",
+			node: &types.Node{ID: "api", Kind: "service"},
+			want: []string{".required_fields"},
+		},
+		{
+			name: "relationship not allowed for kind",
+			node: &types.Node{ID: "api", Kind: "runbook", EdgeIDs: map[string][]string{"runbooks": {"restart-db"}}},
+			want: []string{"runbooks.relationships"},
+		},
+		{
+			name: "source kind not allowed",
+			node: &types.Node{ID: "api", EdgeIDs: map[string][]string{"depends_on": {"db"}}},
+			want: []string{"depends_on.source_kinds"},
+		},
+		{
+			name: "target kind not allowed",
+			node: &types.Node{ID: "api", Kind: "service", Summary: "API", EdgeIDs: map[string][]string{
+				"depends_on": {"restart-db"},
+				"runbooks":   {"notes"},
+			}},
+			want: []string{"depends_on.target_kinds", "runbooks.target_kinds"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, nodeKindRelationships...)
+			if err := manager.SetNodeKinds(testNodeKinds); err != nil {
+				t.Fatalf("Failed to set node kinds: %v", err)
+			}
+			addTestNodes(t, manager, nodeKindTestNodes()...)
+			err := manager.AddNode(tt.node)
+			if got := violatedConstraints(err); !slices.Equal(got, tt.want) {
+				t.Errorf("AddNode() violations = %v, want %v (error: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestChangingKindChecksIncomingEdges(t *testing.T) {
+	manager := newTestManager(t, nodeKindRelationships...)
+	if err := manager.SetNodeKinds(testNodeKinds); err != nil {
+		t.Fatalf("Failed to set node kinds: %v", err)
+	}
+	addTestNodes(t, manager, nodeKindTestNodes()...)
+	if err := manager.AddNode(&types.Node{ID: "api", Kind: "service", Summary: "API", EdgeIDs: map[string][]string{
+		"depends_on": {"db"},
+	}}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	// api depends on db, so db has to stay a service
+	kind := "runbook"
+	_, err := manager.PatchNode("db", NodePatch{Kind: &kind})
+	if got := violatedConstraints(err); !slices.Equal(got, []string{"depends_on.target_kinds"}) {
+		t.Errorf("PatchNode() violations = %v (error: %v)", got, err)
+	}
+
+	// Lint reports stored nodes that break kinds set later
+	if err := manager.SetNodeKinds([]types.NodeKind{{Name: "service", RequiredFields: []string{"description"}}}); err != nil {
+		t.Fatalf("Failed to set node kinds: %v", err)
+	}
+	var linted []string
+	for _, issue := range manager.Lint() {
+		linted = append(linted, issue.NodeID)
+	}
+	if !slices.Equal(linted, []string{"api", "db", "restart-db"}) {
+		t.Errorf("Lint() reported %v, want [api db restart-db]", linted)
+	}
+}
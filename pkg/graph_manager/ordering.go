@@ -0,0 +1,241 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// OrderingConflictError is returned when the backward and forward relationships
+// together would require a node to come both before and after another
+type OrderingConflictError struct {
+	// Chain is the sequence of edges that orders a node before itself. Each
+	// edge orders one node before the next and the last returns to the first.
+	Chain []OrderingStep
+}
+
+// OrderingStep is an edge of the combined execution order: Before comes before
+// After because of the edge From -Relationship-> To
+type OrderingStep struct {
+	Before string
+	After  string
+	Edge   ImpactedEdge
+}
+
+// Error implements the error interface
+func (e *OrderingConflictError) Error() string {
+	steps := make([]string, len(e.Chain))
+	for i, step := range e.Chain {
+		steps[i] = fmt.Sprintf("%s before %s (%s %s %s)", step.Before, step.After, step.Edge.From, step.Edge.Relationship, step.Edge.To)
+	}
+	return fmt.Sprintf("contradictory execution order: %s", strings.Join(steps, ", "))
+}
+
+// orderingDirection returns the direction in which a relationship orders the
+// nodes it links, or DirectionNone if it doesn't take part in the execution
+// order. Unregistered relationships and relationships whose kind allows cycles
+// don't.
+// The caller must hold the lock.
+func (m *Manager) orderingDirection(relationshipName string) types.RelationshipDirection {
+	rel, exists := m.relationshipTypes[relationshipName]
+	if !exists || rel.AllowsCycles() {
+		return types.DirectionNone
+	}
+	return rel.Direction
+}
+
+// orderingSteps returns the steps of the execution order formed by a node's own edges
+// The caller must hold the lock.
+func (m *Manager) orderingSteps(node *types.Node) []OrderingStep {
+	var steps []OrderingStep
+	for _, relationshipName := range sortedEdgeNames(node.EdgeIDs) {
+		direction := m.orderingDirection(relationshipName)
+		for _, targetID := range node.EdgeIDs[relationshipName] {
+			edge := ImpactedEdge{From: node.ID, Relationship: relationshipName, To: targetID}
+			switch direction {
+			case types.DirectionBackward:
+				steps = append(steps, OrderingStep{Before: targetID, After: node.ID, Edge: edge})
+			case types.DirectionForward:
+				steps = append(steps, OrderingStep{Before: node.ID, After: targetID, Edge: edge})
+			}
+		}
+	}
+	return steps
+}
+
+// orderingSuccessors returns a function listing the steps that order other
+// nodes after a given node: its own forward edges and the backward edges of
+// other nodes pointing at it. The graph is seen through lookup, and changed
+// lists the nodes that differ from the stored ones, whose edges the incoming
+// edge index doesn't reflect yet.
+// The caller must hold the write lock.
+func (m *Manager) orderingSuccessors(lookup func(id string) *types.Node, changed []*types.Node) func(id string) []OrderingStep {
+	incoming := m.incomingIndex()
+	changedIDs := make(map[string]bool, len(changed))
+	for _, node := range changed {
+		changedIDs[node.ID] = true
+	}
+
+	return func(id string) []OrderingStep {
+		var steps []OrderingStep
+		if node := lookup(id); node != nil {
+			for _, step := range m.orderingSteps(node) {
+				if step.Before == id {
+					steps = append(steps, step)
+				}
+			}
+		}
+
+		for _, relationshipName := range sortedEdgeNames(incoming[id]) {
+			if m.orderingDirection(relationshipName) != types.DirectionBackward {
+				continue
+			}
+			for _, sourceID := range incoming[id][relationshipName] {
+				if changedIDs[sourceID] {
+					continue
+				}
+				steps = append(steps, OrderingStep{
+					Before: id,
+					After:  sourceID,
+					Edge:   ImpactedEdge{From: sourceID, Relationship: relationshipName, To: id},
+				})
+			}
+		}
+		for _, node := range changed {
+			if lookup(node.ID) != node {
+				continue // Deleted or superseded
+			}
+			for _, step := range m.orderingSteps(node) {
+				if step.Before == id && step.Edge.From != id {
+					steps = append(steps, step)
+				}
+			}
+		}
+		return steps
+	}
+}
+
+// detectOrderingConflicts checks whether the edges of the changed nodes make
+// the combined execution order contradictory. Any new contradiction must pass
+// through one of their edges, so for each step they form it searches for a way
+// back from the later node to the earlier one.
+// The caller must hold the write lock.
+func (m *Manager) detectOrderingConflicts(changed []*types.Node, lookup func(id string) *types.Node) error {
+	successors := m.orderingSuccessors(lookup, changed)
+	for _, node := range changed {
+		for _, step := range m.orderingSteps(node) {
+			if path := findOrderingPath(step.After, step.Before, successors); path != nil {
+				return &OrderingConflictError{Chain: append([]OrderingStep{step}, path...)}
+			}
+		}
+	}
+	return nil
+}
+
+// findOrderingPath searches the execution order breadth first for a chain of
+// steps from one node to another. Returns nil if there is none.
+func findOrderingPath(fromID, toID string, successors func(id string) []OrderingStep) []OrderingStep {
+	if fromID == toID {
+		return []OrderingStep{}
+	}
+
+	reachedBy := map[string]*OrderingStep{fromID: nil}
+	queue := []string{fromID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, step := range successors(id) {
+			if _, seen := reachedBy[step.After]; seen {
+				continue
+			}
+			step := step
+			reachedBy[step.After] = &step
+			if step.After == toID {
+				var path []OrderingStep
+				for at := reachedBy[toID]; at != nil; at = reachedBy[at.Before] {
+					path = append([]OrderingStep{*at}, path...)
+				}
+				return path
+			}
+			queue = append(queue, step.After)
+		}
+	}
+	return nil
+}
+
+// detectOrderingCycles checks the whole graph for a contradictory execution
+// order and reports the first contradiction found.
+// The caller must hold the lock.
+func (m *Manager) detectOrderingCycles() error {
+	after := make(map[string][]OrderingStep)
+	for _, id := range m.sortedNodeIDs() {
+		for _, step := range m.orderingSteps(m.nodes[id]) {
+			after[step.Before] = append(after[step.Before], step)
+		}
+	}
+	for id := range after {
+		sort.SliceStable(after[id], func(i, j int) bool { return after[id][i].After < after[id][j].After })
+	}
+	successors := func(id string) []OrderingStep { return after[id] }
+
+	// Depth-first search for a step back to a node on the current path
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int)
+	var path []OrderingStep
+	var visit func(id string) *OrderingConflictError
+	visit = func(id string) *OrderingConflictError {
+		state[id] = onPath
+		for _, step := range successors(id) {
+			switch state[step.After] {
+			case onPath:
+				// The chain starts where the path left the repeated node
+				chain := append(append([]OrderingStep{}, path[cycleStart(path, step.After):]...), step)
+				return &OrderingConflictError{Chain: chain}
+			case unvisited:
+				path = append(path, step)
+				if err := visit(step.After); err != nil {
+					return err
+				}
+				path = path[:len(path)-1]
+			}
+		}
+		state[id] = done
+		return nil
+	}
+
+	for _, id := range m.sortedNodeIDs() {
+		if state[id] == unvisited {
+			if err := visit(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cycleStart returns the index of the first step on the path leaving the given node
+func cycleStart(path []OrderingStep, id string) int {
+	for i, step := range path {
+		if step.Before == id {
+			return i
+		}
+	}
+	return len(path)
+}
+
+// sortedEdgeNames returns the relationship names of an edge map in sorted order
+func sortedEdgeNames(edges map[string][]string) []string {
+	names := make([]string, 0, len(edges))
+	for name := range edges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,140 @@
+package graph_manager
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// orderingChain describes the steps of an ordering conflict as "before<after"
+func orderingChain(err error) []string {
+	var conflict *OrderingConflictError
+	if !errors.As(err, &conflict) {
+		return nil
+	}
+	chain := make([]string, len(conflict.Chain))
+	for i, step := range conflict.Chain {
+		chain[i] = step.Before + "<" + step.After
+	}
+	return chain
+}
+
+func TestOrderingConflictOnAdd(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	if err := manager.AddNode(&types.Node{ID: "b", Name: "B"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	// prerequisites orders b before a, downstream_required orders a before b
+	err := manager.AddNode(&types.Node{ID: "a", Name: "A", EdgeIDs: map[string][]string{
+		"prerequisites":       {"b"},
+		"downstream_required": {"b"},
+	}})
+	if err == nil {
+		t.Fatal("Expected contradictory orders to be rejected")
+	}
+	if chain := orderingChain(err); !slices.Equal(chain, []string{"a<b", "b<a"}) {
+		t.Errorf("Expected chain [a<b b<a], got %v (%v)", chain, err)
+	}
+	if !strings.Contains(err.Error(), "a before b (a downstream_required b)") ||
+		!strings.Contains(err.Error(), "b before a (a prerequisites b)") {
+		t.Errorf("Expected error to report the edges, got: %v", err)
+	}
+	if _, err := manager.GetNode("a"); err == nil {
+		t.Error("Rejected node should not be stored")
+	}
+}
+
+func TestOrderingConflictThroughOtherNodes(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+
+	// a before b before c, through edges stored on b and c
+	for _, node := range []*types.Node{
+		{ID: "a", Name: "A"},
+		{ID: "b", Name: "B", EdgeIDs: map[string][]string{"prerequisites": {"a"}}},
+		{ID: "c", Name: "C", EdgeIDs: map[string][]string{"prerequisites": {"b"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	// Ordering c before a is consistent within downstream_required, but not
+	// with the prerequisites
+	_, err := manager.PatchNode("c", NodePatch{AddEdges: map[string][]string{"downstream_required": {"a"}}})
+	if err == nil {
+		t.Fatal("Expected the contradictory update to be rejected")
+	}
+	if chain := orderingChain(err); !slices.Equal(chain, []string{"c<a", "a<b", "b<c"}) {
+		t.Errorf("Expected chain [c<a a<b b<c], got %v (%v)", chain, err)
+	}
+
+	// Removing the conflicting edge in the same update is fine
+	_, err = manager.PatchNode("c", NodePatch{
+		AddEdges:    map[string][]string{"downstream_required": {"a"}},
+		RemoveEdges: map[string][]string{"prerequisites": {"b"}},
+	})
+	if err != nil {
+		t.Errorf("Expected the update removing the conflict to be accepted: %v", err)
+	}
+
+	// Consistent orders and relationships without a direction are accepted
+	if _, err := manager.PatchNode("c", NodePatch{AddEdges: map[string][]string{"downstream_required": {"b"}}}); err != nil {
+		t.Errorf("Expected a consistent order to be accepted: %v", err)
+	}
+	if _, err := manager.PatchNode("c", NodePatch{AddEdges: map[string][]string{"related": {"a"}}}); err != nil {
+		t.Errorf("Expected an unordered relationship to be accepted: %v", err)
+	}
+}
+
+func TestOrderingConflictInTransaction(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	if err := manager.AddNode(&types.Node{ID: "a", Name: "A"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "b", Name: "B", EdgeIDs: map[string][]string{"prerequisites": {"a"}}}); err != nil {
+		t.Fatalf("Failed to stage node: %v", err)
+	}
+	if err := tx.AddNode(&types.Node{ID: "c", Name: "C", EdgeIDs: map[string][]string{"downstream_required": {"a"}, "prerequisites": {"b"}}}); err != nil {
+		t.Fatalf("Failed to stage node: %v", err)
+	}
+
+	err := tx.Commit()
+	if err == nil {
+		t.Fatal("Expected the contradictory transaction to be rejected")
+	}
+	if orderingChain(err) == nil {
+		t.Errorf("Expected an ordering conflict, got: %v", err)
+	}
+	if len(manager.ListAllNodes()) != 1 {
+		t.Error("Rejected transaction should not change the graph")
+	}
+}
+
+func TestLoadRejectsOrderingConflicts(t *testing.T) {
+	memoryStore := store.NewMemoryStore()
+	for _, node := range []*types.Node{
+		{ID: "a", Name: "A", EdgeIDs: map[string][]string{"downstream_required": {"b"}}},
+		{ID: "b", Name: "B", EdgeIDs: map[string][]string{"downstream_required": {"c"}}},
+		{ID: "c", Name: "C", EdgeIDs: map[string][]string{"downstream_required": {"d"}, "prerequisites": {"d"}}},
+		{ID: "d", Name: "D"},
+	} {
+		if err := memoryStore.Put(node); err != nil {
+			t.Fatalf("Failed to put node: %v", err)
+		}
+	}
+
+	err := newTestManager(t, workflowRelationships...).LoadFromStore(memoryStore)
+	if err == nil {
+		t.Fatal("Expected a graph with contradictory orders to fail to load")
+	}
+	if chain := orderingChain(err); !slices.Equal(chain, []string{"c<d", "d<c"}) {
+		t.Errorf("Expected chain [c<d d<c], got %v (%v)", chain, err)
+	}
+}
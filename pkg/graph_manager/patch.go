@@ -0,0 +1,175 @@
+package graph_manager
+
+import (
+	"fmt"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// NodePatch describes a partial update of a node. Nil fields are left unchanged,
+// while a pointer to an empty value clears the field.
+type NodePatch struct {
+	Name        *string
+	Kind        *string
+	Condition   *string
+	Summary     *string
+	Description *string
+
+	// Status and SupersededBy change the lifecycle state of the node
+	Status       *types.NodeStatus
+	SupersededBy *string
+
+	// Tags replaces all tags when non-nil. AddTags and RemoveTags are applied after it.
+	Tags       *[]string
+	AddTags    []string
+	RemoveTags []string
+
+	// Edges replaces the targets of each listed relationship; an empty list
+	// removes the relationship. Relationships that aren't listed are kept.
+	// AddEdges and RemoveEdges are applied after it.
+	Edges       map[string][]string
+	AddEdges    map[string][]string
+	RemoveEdges map[string][]string
+
+	// EdgeAttributes sets the attributes of the listed edges, keyed by
+	// relationship name and target ID; zero attributes remove them. Edges of
+	// the relationships listed in Edges lose their attributes unless set here.
+	EdgeAttributes map[string]map[string]types.EdgeAttributes
+
+	// Attributes sets the listed attributes; a nil value removes the attribute,
+	// or resets it to its default. Attributes that aren't listed are kept.
+	Attributes map[string]any
+
+	// UpdatedAt is set on the patched node when non-zero
+	UpdatedAt time.Time
+}
+
+// PatchNode applies a partial update to an existing node and returns the new
+// version. The patch is applied and validated under a single lock, so concurrent
+// patches of the same node don't overwrite each other.
+// Pass WithExpectedRevision to fail with a ConflictError if the node changed meanwhile.
+func (m *Manager) PatchNode(id string, patch NodePatch, opts ...WriteOption) (*types.Node, error) {
+	m.logger.Debug("Patching node", zap.String("node_id", id))
+
+	if id == "" {
+		m.logger.Error("Attempted to patch node with empty ID")
+		return nil, fmt.Errorf("node ID cannot be empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.nodes[id]
+	if !exists {
+		m.logger.Warn("Node not found for patch", zap.String("node_id", id))
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	options := newWriteOptions(opts)
+	if err := options.checkRevision(existing); err != nil {
+		m.logger.Warn("Node patch conflicts with a concurrent change", zap.String("node_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return m.updateNode(existing, patch.applyTo(existing), options)
+}
+
+// applyTo returns a patched copy of the node. The original is not modified.
+func (p NodePatch) applyTo(existing *types.Node) *types.Node {
+	node := existing.Clone()
+
+	if p.Name != nil {
+		node.Name = *p.Name
+	}
+	if p.Kind != nil {
+		node.Kind = *p.Kind
+	}
+	if p.Condition != nil {
+		node.Condition = *p.Condition
+	}
+	if p.Status != nil {
+		node.Status = *p.Status
+	}
+	if p.SupersededBy != nil {
+		node.SupersededBy = *p.SupersededBy
+	}
+	if p.Summary != nil {
+		node.Summary = *p.Summary
+	}
+	if p.Description != nil {
+		node.Description = *p.Description
+	}
+
+	if p.Tags != nil {
+		node.Tags = append([]string{}, *p.Tags...)
+	}
+	for _, tag := range p.AddTags {
+		if !containsString(node.Tags, tag) {
+			node.Tags = append(node.Tags, tag)
+		}
+	}
+	for _, tag := range p.RemoveTags {
+		node.Tags = removeStringFromSlice(node.Tags, tag)
+	}
+
+	if node.EdgeIDs == nil {
+		node.EdgeIDs = make(map[string][]string)
+	}
+	for relationshipName, ids := range p.Edges {
+		node.EdgeIDs[relationshipName] = append([]string{}, ids...)
+	}
+	for relationshipName, ids := range p.AddEdges {
+		for _, id := range ids {
+			if !containsString(node.EdgeIDs[relationshipName], id) {
+				node.EdgeIDs[relationshipName] = append(node.EdgeIDs[relationshipName], id)
+			}
+		}
+	}
+	for relationshipName, ids := range p.RemoveEdges {
+		for _, id := range ids {
+			node.EdgeIDs[relationshipName] = removeStringFromSlice(node.EdgeIDs[relationshipName], id)
+			node.SetEdgeAttributes(relationshipName, id, types.EdgeAttributes{})
+		}
+	}
+
+	for relationshipName := range p.Edges {
+		delete(node.EdgeAttributes, relationshipName)
+	}
+	for relationshipName, targets := range p.EdgeAttributes {
+		for id, attributes := range targets {
+			node.SetEdgeAttributes(relationshipName, id, attributes)
+		}
+	}
+
+	// Drop relationships the patch left without targets
+	for _, changes := range []map[string][]string{p.Edges, p.AddEdges, p.RemoveEdges} {
+		for relationshipName := range changes {
+			if len(node.EdgeIDs[relationshipName]) == 0 {
+				delete(node.EdgeIDs, relationshipName)
+			}
+		}
+	}
+
+	for name, value := range p.Attributes {
+		if value == nil {
+			delete(node.Attributes, name)
+			continue
+		}
+		if node.Attributes == nil {
+			node.Attributes = make(map[string]any)
+		}
+		node.Attributes[name] = value
+	}
+	if len(node.Attributes) == 0 {
+		node.Attributes = nil
+	}
+
+	if !p.UpdatedAt.IsZero() {
+		node.UpdatedAt = p.UpdatedAt
+	}
+
+	return node
+}
@@ -0,0 +1,124 @@
+package graph_manager
+
+import (
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestPatchNode(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A"},
+		{ID: "task-b", Name: "Task B"},
+		{
+			ID:          "task-c",
+			Name:        "Task C",
+			Summary:     "Summary",
+			Description: "Description",
+			Tags:        []string{"backend", "database"},
+			EdgeIDs: map[string][]string{
+				"prerequisites": {"task-a"},
+				"related":       {"task-b"},
+			},
+		},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node: %v", err)
+		}
+	}
+
+	description := "New description"
+	summary := ""
+	patched, err := manager.PatchNode("task-c", NodePatch{
+		Description: &description,
+		Summary:     &summary,
+		AddTags:     []string{"deployment", "backend"},
+		RemoveTags:  []string{"database"},
+		AddEdges:    map[string][]string{"prerequisites": {"task-b", "task-a"}},
+		RemoveEdges: map[string][]string{"related": {"task-b"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+
+	if patched.Name != "Task C" {
+		t.Errorf("Omitted name should be kept, got %q", patched.Name)
+	}
+	if patched.Description != description {
+		t.Errorf("Expected description %q, got %q", description, patched.Description)
+	}
+	if patched.Summary != "" {
+		t.Errorf("Summary should be cleared, got %q", patched.Summary)
+	}
+	if len(patched.Tags) != 2 || patched.Tags[0] != "backend" || patched.Tags[1] != "deployment" {
+		t.Errorf("Expected tags [backend deployment], got %v", patched.Tags)
+	}
+	if prereqs := patched.GetEdgeIDs("prerequisites"); len(prereqs) != 2 || prereqs[1] != "task-b" {
+		t.Errorf("Expected prerequisites [task-a task-b], got %v", prereqs)
+	}
+	if _, exists := patched.EdgeIDs["related"]; exists {
+		t.Error("Relationship left without targets should be removed")
+	}
+	if edges := patched.GetEdges("prerequisites"); len(edges) != 2 {
+		t.Errorf("Patched edges should be resolved, got %d", len(edges))
+	}
+
+	stored, _ := manager.GetNode("task-c")
+	if stored != patched {
+		t.Error("Patched node should be stored")
+	}
+
+	// Replacing tags and a relationship's targets
+	patched, err = manager.PatchNode("task-c", NodePatch{
+		Tags:  &[]string{},
+		Edges: map[string][]string{"prerequisites": {"task-b"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	if len(patched.Tags) != 0 {
+		t.Errorf("Tags should be cleared, got %v", patched.Tags)
+	}
+	if prereqs := patched.GetEdgeIDs("prerequisites"); len(prereqs) != 1 || prereqs[0] != "task-b" {
+		t.Errorf("Expected prerequisites [task-b], got %v", prereqs)
+	}
+
+	// Setting edge attributes, which replacing the relationship's targets clears
+	note := types.EdgeAttributes{Note: "Needs the build output", Weight: 2}
+	patched, err = manager.PatchNode("task-c", NodePatch{
+		EdgeAttributes: map[string]map[string]types.EdgeAttributes{"prerequisites": {"task-b": note}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	if got := patched.GetEdgeAttributes("prerequisites", "task-b"); got != note {
+		t.Errorf("Expected edge attributes %+v, got %+v", note, got)
+	}
+	if edges := patched.GetEdges("prerequisites"); len(edges) != 1 || edges[0].Attributes != note {
+		t.Errorf("Resolved edges should carry the attributes, got %+v", edges)
+	}
+	patched, err = manager.PatchNode("task-c", NodePatch{Edges: map[string][]string{"prerequisites": {"task-b"}}})
+	if err != nil {
+		t.Fatalf("Failed to patch node: %v", err)
+	}
+	if got := patched.GetEdgeAttributes("prerequisites", "task-b"); !got.IsZero() {
+		t.Errorf("Replacing the targets should clear the edge attributes, got %+v", got)
+	}
+
+	// Invalid patches leave the node unchanged
+	if _, err := manager.PatchNode("task-b", NodePatch{AddEdges: map[string][]string{"prerequisites": {"task-c"}}}); err == nil {
+		t.Error("Expected error for patch introducing a cycle")
+	}
+	if _, err := manager.PatchNode("task-c", NodePatch{AddEdges: map[string][]string{"prerequisites": {"missing"}}}); err == nil {
+		t.Error("Expected error for patch referencing a missing node")
+	}
+	if stored, _ := manager.GetNode("task-c"); stored != patched {
+		t.Error("Rejected patch should not change the stored node")
+	}
+	if _, err := manager.PatchNode("missing", NodePatch{}); err == nil {
+		t.Error("Expected error when patching a missing node")
+	}
+}
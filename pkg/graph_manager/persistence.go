@@ -0,0 +1,303 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// LoadFromStore loads every node from the store into the manager and makes it
+// the store that Flush writes changes back to. The loaded graph is checked for
+// cycles and its pointers, tag cache and indexes are rebuilt.
+func (m *Manager) LoadFromStore(s store.Store) error {
+	// Read from the store before taking the lock so readers aren't blocked on I/O
+	loaded, err := s.LoadAll()
+	if err != nil {
+		m.logger.Error("Failed to load nodes from store", zap.Error(err))
+		return err
+	}
+
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, node := range loaded {
+		m.nodes[node.ID] = node
+	}
+
+	// Detect cycles before resolving pointers
+	m.logger.Debug("Detecting cycles in node graph")
+	if err := m.detectCycles(); err != nil {
+		m.logger.Error("Cycle detected in node graph", zap.Error(err))
+		return fmt.Errorf("cycle detected in node graph: %w", err)
+	}
+	m.logger.Debug("No cycles detected")
+
+	// Resolve node pointers after loading all nodes and validating no cycles
+	m.logger.Debug("Resolving node pointers")
+	if err := m.resolveNodePointers(); err != nil {
+		m.logger.Error("Failed to resolve node pointers", zap.Error(err))
+		return err
+	}
+	m.logger.Debug("Node pointers resolved")
+
+	// Populate tag cache for efficient tag-based lookups
+	m.logger.Debug("Populating tag cache")
+	m.populateTagCache()
+
+	// Rebuild the incoming edge index for the loaded graph
+	m.buildIncomingIndex()
+
+	// Changes are flushed back to the store they were loaded from. Nodes the
+	// store holds in the wrong place are rewritten on the next flush.
+	m.store = s
+	m.dirty = make(map[string]bool)
+	if relocator, ok := s.(store.Relocator); ok {
+		for _, id := range relocator.Misplaced() {
+			m.dirty[id] = true
+		}
+	}
+
+	m.logger.Info("Successfully loaded nodes from store",
+		zap.Int("nodes_loaded", len(loaded)),
+		zap.Int("total_nodes", len(m.nodes)),
+	)
+
+	return nil
+}
+
+// PersistToDir writes nodes to the specified directory as YAML files.
+//
+// If the manager's store is the directory (it was loaded from or first persisted
+// to it), only nodes added, changed or removed since the last persist are
+// written or deleted, so untouched files keep their contents and modification
+// times. If the manager has no store yet, the directory becomes its store and
+// every node is written. Otherwise every node is exported to the directory and
+// the manager's store is left unchanged.
+func (m *Manager) PersistToDir(dirPath string) error {
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	if dirStore, ok := m.store.(*store.DirStore); ok && dirStore.SameDir(dirPath) {
+		return m.flush()
+	}
+
+	dirStore := store.NewDirStore(dirPath, m.logger)
+	if m.store != nil {
+		return m.exportTo(dirStore)
+	}
+
+	// Adopt the directory as the store, with every node pending
+	m.mu.Lock()
+	m.store = dirStore
+	for id := range m.nodes {
+		m.markDirty(id)
+	}
+	m.mu.Unlock()
+
+	return m.flush()
+}
+
+// ExportTo writes every node to the given store. The manager's own store and
+// pending changes are unaffected.
+func (m *Manager) ExportTo(s store.Store) error {
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	return m.exportTo(s)
+}
+
+// MirrorTo makes the given store hold exactly the manager's nodes: every node is
+// written and stored nodes the manager doesn't have are deleted. It is used to
+// convert between storage backends. The manager's own store and pending changes
+// are unaffected.
+func (m *Manager) MirrorTo(s store.Store) error {
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	// Load rather than List so stores that track where each node is held, such
+	// as DirStore, also remove copies found in a non-canonical location
+	stored, err := s.LoadAll()
+	if err != nil {
+		m.logger.Error("Failed to read stored nodes", zap.Error(err))
+		return err
+	}
+
+	m.mu.RLock()
+	nodes := make([]*types.Node, 0, len(m.nodes))
+	for _, id := range m.sortedNodeIDs() {
+		nodes = append(nodes, m.nodes[id])
+	}
+	var deletes []string
+	for _, node := range stored {
+		if !m.nodeExists(node.ID) && !containsString(deletes, node.ID) {
+			deletes = append(deletes, node.ID)
+		}
+	}
+	m.mu.RUnlock()
+
+	m.logger.Info("Mirroring nodes",
+		zap.Int("node_count", len(nodes)),
+		zap.Int("stale_nodes", len(deletes)),
+	)
+
+	if err := writeToStore(s, nodes, deletes); err != nil {
+		m.logger.Error("Failed to mirror nodes", zap.Error(err))
+		return err
+	}
+
+	m.logger.Info("Successfully mirrored nodes",
+		zap.Int("nodes_written", len(nodes)),
+		zap.Int("nodes_deleted", len(deletes)),
+	)
+
+	return nil
+}
+
+// Flush writes the nodes added, changed or removed since the last persist to
+// the manager's store. It is a no-op if there are no pending changes.
+func (m *Manager) Flush() error {
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	if m.store == nil {
+		return fmt.Errorf("no store: load from or persist to a store first")
+	}
+
+	return m.flush()
+}
+
+// Store returns the store changes are flushed to, or nil if none is set
+func (m *Manager) Store() store.Store {
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	return m.store
+}
+
+// PendingChanges returns the number of nodes added, changed or removed since
+// the last persist
+func (m *Manager) PendingChanges() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.dirty)
+}
+
+// flush writes pending changes to the manager's store. Store I/O happens without
+// holding the graph lock; mutations made meanwhile are picked up by the next flush.
+// The caller must hold persistMu.
+func (m *Manager) flush() error {
+	// Take a snapshot of what needs writing and reset the dirty set
+	m.mu.Lock()
+	dirty := m.dirty
+	m.dirty = nil
+	writes := make(map[string]*types.Node, len(dirty))
+	for id := range dirty {
+		writes[id] = m.nodes[id] // nil for removed nodes
+	}
+	m.mu.Unlock()
+
+	if len(writes) == 0 {
+		m.logger.Debug("No pending changes to persist")
+		return nil
+	}
+
+	m.logger.Info("Persisting pending changes", zap.Int("dirty_nodes", len(writes)))
+
+	var puts []*types.Node
+	var deletes []string
+	for _, id := range sortedKeys(dirty) {
+		if node := writes[id]; node != nil {
+			puts = append(puts, node)
+		} else {
+			deletes = append(deletes, id)
+		}
+	}
+
+	if err := writeToStore(m.store, puts, deletes); err != nil {
+		m.logger.Error("Failed to persist pending changes", zap.Error(err))
+
+		// Keep the changes pending so the next flush retries them
+		m.mu.Lock()
+		for id := range dirty {
+			m.markDirty(id)
+		}
+		m.mu.Unlock()
+		return err
+	}
+
+	m.logger.Info("Successfully persisted pending changes",
+		zap.Int("nodes_written", len(puts)),
+		zap.Int("nodes_deleted", len(deletes)),
+	)
+
+	return nil
+}
+
+// exportTo writes every node to the store.
+// The caller must hold persistMu.
+func (m *Manager) exportTo(s store.Store) error {
+	m.mu.RLock()
+	nodes := make([]*types.Node, 0, len(m.nodes))
+	for _, id := range m.sortedNodeIDs() {
+		nodes = append(nodes, m.nodes[id])
+	}
+	m.mu.RUnlock()
+
+	m.logger.Info("Exporting nodes", zap.Int("node_count", len(nodes)))
+
+	if err := writeToStore(s, nodes, nil); err != nil {
+		m.logger.Error("Failed to export nodes", zap.Error(err))
+		return err
+	}
+
+	m.logger.Info("Successfully exported nodes", zap.Int("nodes_exported", len(nodes)))
+
+	return nil
+}
+
+// writeToStore writes the puts and deletes to the store, in a single transaction
+// if the store supports it
+func writeToStore(s store.Store, puts []*types.Node, deletes []string) error {
+	if batcher, ok := s.(store.Batcher); ok {
+		return batcher.Apply(puts, deletes)
+	}
+
+	for _, node := range puts {
+		if err := s.Put(node); err != nil {
+			return err
+		}
+	}
+	for _, id := range deletes {
+		if err := s.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markDirty records that a node needs to be written to or removed from the store.
+// The caller must hold the write lock.
+func (m *Manager) markDirty(id string) {
+	if m.dirty == nil {
+		m.dirty = make(map[string]bool)
+	}
+	m.dirty[id] = true
+}
+
+// sortedNodeIDs returns the IDs of all nodes in sorted order.
+// The caller must hold the lock.
+func (m *Manager) sortedNodeIDs() []string {
+	ids := make([]string, 0, len(m.nodes))
+	for id := range m.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,370 @@
+package graph_manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestPersistRemovesDeletedNodeFiles(t *testing.T) {
+	testDir := filepath.Join(t.TempDir(), "nodes")
+	log, _ := logger.New(false)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	manager := NewManager(log)
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now},
+		{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}, CreatedAt: now, UpdatedAt: now},
+	} {
+		manager.nodes[node.ID] = node
+	}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+
+	if err := manager.DeleteNode("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(testDir, "task-a.yaml")); !os.IsNotExist(err) {
+		t.Error("File of deleted node should have been removed")
+	}
+
+	// The deleted node must not come back on reload
+	reloaded := NewManager(log)
+	if err := reloaded.LoadNodesFromDir(testDir); err != nil {
+		t.Fatalf("Failed to reload nodes: %v", err)
+	}
+	if len(reloaded.nodes) != 1 {
+		t.Errorf("Expected 1 node after reload, got %d", len(reloaded.nodes))
+	}
+	if ids := reloaded.nodes["task-b"].GetEdgeIDs("prerequisites"); len(ids) != 0 {
+		t.Errorf("task-b should no longer reference task-a, got %v", ids)
+	}
+}
+
+func TestPersistMovesRenamedNodeFiles(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+
+	// A node stored under a file name that doesn't match its ID
+	if err := os.WriteFile(filepath.Join(testDir, "old-name.yaml"), []byte("id: task-a\nname: Task A\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+
+	manager := NewManager(log)
+	if err := manager.LoadNodesFromDir(testDir); err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(testDir, "old-name.yaml")); !os.IsNotExist(err) {
+		t.Error("Old file should have been removed")
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "task-a.yaml")); err != nil {
+		t.Errorf("Node should be stored under its ID: %v", err)
+	}
+}
+
+func TestPersistLeavesUntrackedFiles(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+
+	// Files the manager never loaded or wrote are not its to remove
+	untracked := filepath.Join(testDir, "other.yaml")
+	if err := os.WriteFile(untracked, []byte("id: other\nname: Other\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+
+	manager := NewManager(log)
+	manager.nodes["task-a"] = &types.Node{ID: "task-a", Name: "Task A"}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+
+	if _, err := os.Stat(untracked); err != nil {
+		t.Errorf("Untracked file should be left alone: %v", err)
+	}
+}
+
+func TestPersistWritesAtomically(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+
+	manager := NewManager(log)
+	manager.nodes["task-a"] = &types.Node{ID: "task-a", Name: "Task A"}
+	for i := 0; i < 2; i++ {
+		if err := manager.PersistToDir(testDir); err != nil {
+			t.Fatalf("Failed to persist nodes: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(testDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Temporary file %s was left behind", entry.Name())
+		}
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected exactly 1 file, got %d", len(entries))
+	}
+
+	info, err := os.Stat(filepath.Join(testDir, "task-a.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to stat node file: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected file mode 0644, got %v", info.Mode().Perm())
+	}
+}
+
+func TestPersistOnlyWritesChangedNodes(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	manager := NewManager(log)
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now},
+		{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node: %v", err)
+		}
+	}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+	if pending := manager.PendingChanges(); pending != 0 {
+		t.Errorf("Expected no pending changes after persist, got %d", pending)
+	}
+
+	// Backdate both files so a rewrite is detectable
+	past := now.Add(-time.Hour)
+	for _, id := range []string{"task-a", "task-b"} {
+		if err := os.Chtimes(filepath.Join(testDir, id+".yaml"), past, past); err != nil {
+			t.Fatalf("Failed to backdate file: %v", err)
+		}
+	}
+
+	if err := manager.UpdateNode(&types.Node{ID: "task-b", Name: "Task B v2", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	if pending := manager.PendingChanges(); pending != 1 {
+		t.Errorf("Expected 1 pending change, got %d", pending)
+	}
+	if err := manager.PersistToDir(testDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+
+	infoA, err := os.Stat(filepath.Join(testDir, "task-a.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !infoA.ModTime().Equal(past) {
+		t.Error("Unchanged node file should not have been rewritten")
+	}
+	infoB, err := os.Stat(filepath.Join(testDir, "task-b.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if infoB.ModTime().Equal(past) {
+		t.Error("Changed node file should have been rewritten")
+	}
+
+	// Persisting to another directory writes everything
+	otherDir := t.TempDir()
+	if err := manager.PersistToDir(otherDir); err != nil {
+		t.Fatalf("Failed to persist nodes: %v", err)
+	}
+	if entries, _ := os.ReadDir(otherDir); len(entries) != 2 {
+		t.Errorf("Expected 2 files in new directory, got %d", len(entries))
+	}
+}
+
+func TestFlush(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+
+	if err := manager.AddNode(&types.Node{ID: "task-a", Name: "Task A"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err := manager.Flush(); err == nil {
+		t.Error("Expected error when flushing without a persistence directory")
+	}
+
+	testDir := t.TempDir()
+	if err := manager.LoadNodesFromDir(testDir); err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if err := manager.AddNode(&types.Node{ID: "task-b", Name: "Task B"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err := manager.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(testDir, "task-b.yaml")); err != nil {
+		t.Errorf("Flushed node should have been written: %v", err)
+	}
+	if manager.PendingChanges() != 0 {
+		t.Errorf("Expected no pending changes after flush, got %d", manager.PendingChanges())
+	}
+}
+
+func TestWriteBehindCoalescesChanges(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+
+	manager := NewManager(log)
+	if err := manager.LoadNodesFromDir(testDir); err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+
+	writeBehind := NewWriteBehind(manager, time.Hour)
+	for _, id := range []string{"task-a", "task-b", "task-c"} {
+		if err := manager.AddNode(&types.Node{ID: id, Name: id}); err != nil {
+			t.Fatalf("Failed to add node: %v", err)
+		}
+		if err := writeBehind.Schedule(); err != nil {
+			t.Fatalf("Failed to schedule flush: %v", err)
+		}
+	}
+
+	if entries, _ := os.ReadDir(testDir); len(entries) != 0 {
+		t.Errorf("Expected no files before the flush, got %d", len(entries))
+	}
+	if manager.PendingChanges() != 3 {
+		t.Errorf("Expected 3 pending changes, got %d", manager.PendingChanges())
+	}
+
+	if err := writeBehind.Close(); err != nil {
+		t.Fatalf("Failed to close write-behind: %v", err)
+	}
+	if entries, _ := os.ReadDir(testDir); len(entries) != 3 {
+		t.Errorf("Expected 3 files after close, got %d", len(entries))
+	}
+}
+
+func TestWriteBehindFlushesAfterInterval(t *testing.T) {
+	testDir := t.TempDir()
+	log, _ := logger.New(false)
+
+	manager := NewManager(log)
+	if err := manager.LoadNodesFromDir(testDir); err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+
+	writeBehind := NewWriteBehind(manager, 10*time.Millisecond)
+	defer writeBehind.Close()
+
+	if err := manager.AddNode(&types.Node{ID: "task-a", Name: "Task A"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err := writeBehind.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule flush: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(testDir, "task-a.yaml")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for write-behind flush")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLoadFromStoreAndFlush(t *testing.T) {
+	log, _ := logger.New(false)
+	memoryStore := store.NewMemoryStore()
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A"},
+		{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}},
+	} {
+		if err := memoryStore.Put(node); err != nil {
+			t.Fatalf("Failed to put node: %v", err)
+		}
+	}
+
+	manager := NewManager(log)
+	if err := manager.LoadFromStore(memoryStore); err != nil {
+		t.Fatalf("Failed to load from store: %v", err)
+	}
+	if manager.Store() != memoryStore {
+		t.Error("Loaded store should become the manager's store")
+	}
+	if edges := manager.nodes["task-b"].GetEdges("prerequisites"); len(edges) != 1 || edges[0].To != manager.nodes["task-a"] {
+		t.Error("Pointers should be resolved after loading from store")
+	}
+
+	if err := manager.DeleteNode("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := manager.AddNode(&types.Node{ID: "task-c", Name: "Task C"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err := manager.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	ids, _ := memoryStore.List()
+	if len(ids) != 2 || ids[0] != "task-b" || ids[1] != "task-c" {
+		t.Errorf("Expected [task-b task-c] in store, got %v", ids)
+	}
+	stored, _ := memoryStore.LoadAll()
+	for _, node := range stored {
+		if node.ID == "task-b" && len(node.GetEdgeIDs("prerequisites")) != 0 {
+			t.Error("Cleaned references should be flushed to the store")
+		}
+	}
+}
+
+func TestMirrorTo(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	for _, node := range []*types.Node{
+		{ID: "task-a", Name: "Task A"},
+		{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node: %v", err)
+		}
+	}
+
+	target := store.NewMemoryStore()
+	if err := target.Put(&types.Node{ID: "stale", Name: "Stale"}); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+
+	if err := manager.MirrorTo(target); err != nil {
+		t.Fatalf("Failed to mirror: %v", err)
+	}
+
+	ids, _ := target.List()
+	if len(ids) != 2 || ids[0] != "task-a" || ids[1] != "task-b" {
+		t.Errorf("Expected [task-a task-b] in target, got %v", ids)
+	}
+	if manager.Store() != nil {
+		t.Error("Mirroring should not change the manager's store")
+	}
+	if manager.PendingChanges() != 2 {
+		t.Errorf("Mirroring should not clear pending changes, got %d", manager.PendingChanges())
+	}
+}
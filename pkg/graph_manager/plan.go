@@ -0,0 +1,163 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// ExecutionPlan is the order in which the nodes around a target have to be
+// carried out. Nodes in the same level don't depend on each other and can be
+// carried out in parallel once all earlier levels are done.
+type ExecutionPlan struct {
+	TargetID string
+	Levels   [][]*types.Node
+
+	// Excluded lists the edges left out because their conditions don't hold
+	// in the request context given with WithFacts
+	Excluded []ExcludedEdge
+}
+
+// Steps returns the nodes of the plan in execution order
+func (p *ExecutionPlan) Steps() []*types.Node {
+	var steps []*types.Node
+	for _, level := range p.Levels {
+		steps = append(steps, level...)
+	}
+	return steps
+}
+
+// ExecutionPlan builds the execution plan of a node from the relationships that
+// have a direction. Starting at the target, it follows the edges of every
+// backward relationship (nodes that come before) and forward relationship
+// (nodes that come after), transitively, and orders everything it reaches:
+// the target of a backward edge runs before its source and the target of a
+// forward edge runs after it. Relationships without a direction are ignored.
+// Each relationship is acyclic on its own, but combined they can contradict
+// each other, in which case an error names the nodes involved.
+// Pass WithFacts to plan only the edges whose conditions hold.
+func (m *Manager) ExecutionPlan(targetID string, opts ...QueryOption) (*ExecutionPlan, error) {
+	plan := &ExecutionPlan{TargetID: targetID}
+	options := newQueryOptions(opts)
+	report := options.excluded
+	options.excluded = func(excluded ExcludedEdge) {
+		plan.Excluded = append(plan.Excluded, excluded)
+		if report != nil {
+			report(excluded)
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	target, exists := m.nodes[targetID]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", targetID)
+	}
+
+	// Collect the nodes of the plan and the precedence between them
+	nodes := map[string]*types.Node{target.ID: target}
+	after := make(map[string]map[string]bool) // node ID -> IDs that must run after it
+	before := make(map[string]int)            // node ID -> number of nodes that must run before it
+	precede := func(first, second string) {
+		if after[first] == nil {
+			after[first] = make(map[string]bool)
+		}
+		if !after[first][second] {
+			after[first][second] = true
+			before[second]++
+		}
+	}
+
+	queue := []*types.Node{target}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for relationshipName, targetIDs := range node.EdgeIDs {
+			rel, registered := m.relationshipTypes[relationshipName]
+			if !registered || rel.Direction == types.DirectionNone {
+				continue
+			}
+			for _, targetID := range targetIDs {
+				target, exists := m.nodes[targetID]
+				if !exists || !options.follows(node, relationshipName, target, target) {
+					continue
+				}
+				if rel.Direction == types.DirectionBackward {
+					precede(target.ID, node.ID)
+				} else {
+					precede(node.ID, target.ID)
+				}
+				if _, seen := nodes[target.ID]; !seen {
+					nodes[target.ID] = target
+					queue = append(queue, target)
+				}
+			}
+		}
+	}
+
+	// Sort topologically, one level at a time
+	var ready []string
+	for id := range nodes {
+		if before[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	planned := 0
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		level := make([]*types.Node, len(ready))
+		var next []string
+		for i, id := range ready {
+			level[i] = nodes[id]
+			for successor := range after[id] {
+				before[successor]--
+				if before[successor] == 0 {
+					next = append(next, successor)
+				}
+			}
+		}
+		plan.Levels = append(plan.Levels, level)
+		planned += len(level)
+		ready = next
+	}
+
+	if planned < len(nodes) {
+		var conflicting []string
+		for id := range nodes {
+			if before[id] > 0 {
+				conflicting = append(conflicting, id)
+			}
+		}
+		sort.Strings(conflicting)
+		m.logger.Warn("Relationships give conflicting execution orders",
+			zap.String("node_id", targetID),
+			zap.Strings("conflicting", conflicting),
+		)
+		return nil, fmt.Errorf("cannot order the plan of %s: relationships give conflicting execution orders for %s", targetID, strings.Join(conflicting, ", "))
+	}
+
+	sort.Slice(plan.Excluded, func(i, j int) bool {
+		a, b := plan.Excluded[i], plan.Excluded[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Relationship != b.Relationship {
+			return a.Relationship < b.Relationship
+		}
+		return a.To < b.To
+	})
+
+	m.logger.Debug("Built execution plan",
+		zap.String("node_id", targetID),
+		zap.Int("steps", planned),
+		zap.Int("levels", len(plan.Levels)),
+	)
+
+	return plan, nil
+}
@@ -0,0 +1,97 @@
+package graph_manager
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// planLevelIDs flattens the levels of a plan to their node IDs
+func planLevelIDs(plan *ExecutionPlan) [][]string {
+	levels := make([][]string, len(plan.Levels))
+	for i, level := range plan.Levels {
+		for _, node := range level {
+			levels[i] = append(levels[i], node.ID)
+		}
+	}
+	return levels
+}
+
+func TestExecutionPlan(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "checkout", Name: "Checkout"},
+		{ID: "lint", Name: "Lint"},
+		{ID: "notify", Name: "Notify"},
+		{ID: "unrelated", Name: "Unrelated"},
+		{ID: "build", Name: "Build", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}}},
+		{ID: "test", Name: "Test", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}, "related": {"unrelated"}}},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{
+			"prerequisites":       {"build", "test"},
+			"downstream_required": {"notify"},
+			"related":             {"lint"},
+		}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	plan, err := manager.ExecutionPlan("deploy")
+	if err != nil {
+		t.Fatalf("ExecutionPlan failed: %v", err)
+	}
+
+	expected := [][]string{{"checkout"}, {"build", "test"}, {"deploy"}, {"notify"}}
+	if got := planLevelIDs(plan); !slices.EqualFunc(got, expected, slices.Equal) {
+		t.Errorf("Expected levels %v, got %v", expected, got)
+	}
+	if steps := plan.Steps(); len(steps) != 5 || steps[4].ID != "notify" {
+		t.Errorf("Expected 5 steps ending with notify, got %d", len(steps))
+	}
+
+	plan, err = manager.ExecutionPlan("checkout")
+	if err != nil {
+		t.Fatalf("ExecutionPlan failed: %v", err)
+	}
+	if got := planLevelIDs(plan); !slices.EqualFunc(got, [][]string{{"checkout"}}, slices.Equal) {
+		t.Errorf("A node without ordered edges should plan only itself, got %v", got)
+	}
+
+	if _, err := manager.ExecutionPlan("missing"); err == nil {
+		t.Error("Expected error for missing node")
+	}
+}
+
+func TestExecutionPlanConflictingOrders(t *testing.T) {
+	manager := newTestManager(t, workflowRelationships...)
+
+	// Relationships whose kind allows cycles are not checked against the
+	// execution order on write, so they can still contradict it
+	if err := manager.RegisterRelationship(types.Relationship{Name: "follows_up", Direction: types.DirectionForward, Kind: types.KindGraph}); err != nil {
+		t.Fatalf("Failed to register relationship: %v", err)
+	}
+
+	// Together the relationships order a and b both ways
+	for _, node := range []*types.Node{
+		{ID: "b", Name: "B"},
+		{ID: "a", Name: "A", EdgeIDs: map[string][]string{
+			"prerequisites": {"b"},
+			"follows_up":    {"b"},
+		}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+
+	_, err := manager.ExecutionPlan("a")
+	if err == nil {
+		t.Fatal("Expected conflicting orders to fail")
+	}
+	if !strings.Contains(err.Error(), "a, b") {
+		t.Errorf("Expected error to name the conflicting nodes, got: %v", err)
+	}
+}
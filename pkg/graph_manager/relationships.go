@@ -30,16 +30,28 @@ func (m *Manager) RegisterRelationship(rel types.Relationship) error {
 		return fmt.Errorf("invalid relationship %s: %w", rel.Name, err)
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Check if already exists
 	if _, exists := m.relationshipTypes[rel.Name]; exists {
 		m.logger.Warn("Relationship already registered", zap.String("name", rel.Name))
 		return fmt.Errorf("relationship %s already registered", rel.Name)
 	}
 
+	if err := m.checkInversePairing(rel); err != nil {
+		m.logger.Error("Invalid relationship inverse",
+			zap.String("name", rel.Name),
+			zap.Error(err),
+		)
+		return fmt.Errorf("invalid relationship %s: %w", rel.Name, err)
+	}
+
 	m.relationshipTypes[rel.Name] = &rel
 	m.logger.Info("Registered relationship",
 		zap.String("name", rel.Name),
 		zap.String("direction", string(rel.Direction)),
+		zap.String("inverse", rel.Inverse),
 	)
 
 	return nil
@@ -83,6 +95,16 @@ func (m *Manager) LoadRelationshipsFromFile(filePath string) error {
 		registered++
 	}
 
+	// Inverses only take effect once both relationships are registered
+	for _, rel := range config.Relationships {
+		if rel.Inverse != "" && m.IsRelationshipRegistered(rel.Name) && !m.IsRelationshipRegistered(rel.Inverse) {
+			m.logger.Warn("Inverse relationship is not registered",
+				zap.String("name", rel.Name),
+				zap.String("inverse", rel.Inverse),
+			)
+		}
+	}
+
 	m.logger.Info("Loaded relationships from file",
 		zap.String("path", filePath),
 		zap.Int("registered", registered),
@@ -111,6 +133,15 @@ func (m *Manager) LoadRelationshipsFromDir(dirPath string) error {
 // GetRelationship retrieves a relationship by name.
 // Returns nil if the relationship is not registered.
 func (m *Manager) GetRelationship(name string) *types.Relationship {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.getRelationship(name)
+}
+
+// getRelationship is the lock-free implementation of GetRelationship.
+// The caller must hold the lock.
+func (m *Manager) getRelationship(name string) *types.Relationship {
 	if rel, exists := m.relationshipTypes[name]; exists {
 		return rel
 	}
@@ -120,6 +151,9 @@ func (m *Manager) GetRelationship(name string) *types.Relationship {
 // GetAllRelationships returns all registered relationships.
 // Returns a copy to prevent external modification.
 func (m *Manager) GetAllRelationships() map[string]types.Relationship {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	copy := make(map[string]types.Relationship, len(m.relationshipTypes))
 	for k, v := range m.relationshipTypes {
 		copy[k] = *v
@@ -129,12 +163,24 @@ func (m *Manager) GetAllRelationships() map[string]types.Relationship {
 
 // IsRelationshipRegistered checks if a relationship type is registered.
 func (m *Manager) IsRelationshipRegistered(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.isRelationshipRegistered(name)
+}
+
+// isRelationshipRegistered is the lock-free implementation of IsRelationshipRegistered.
+// The caller must hold the lock.
+func (m *Manager) isRelationshipRegistered(name string) bool {
 	_, exists := m.relationshipTypes[name]
 	return exists
 }
 
 // GetRegisteredRelationshipNames returns a list of all registered relationship names.
 func (m *Manager) GetRegisteredRelationshipNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	names := make([]string, 0, len(m.relationshipTypes))
 	for name := range m.relationshipTypes {
 		names = append(names, name)
@@ -147,13 +193,16 @@ func (m *Manager) GetRegisteredRelationshipNames() []string {
 func (m *Manager) ValidateRelationships() error {
 	m.logger.Debug("Validating all relationships are registered")
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	unregistered := make(map[string]bool)
 
 	// Check all nodes for unregistered relationship types
 	for _, node := range m.nodes {
 		if node.EdgeIDs != nil {
 			for relationshipName := range node.EdgeIDs {
-				if !m.IsRelationshipRegistered(relationshipName) {
+				if !m.isRelationshipRegistered(relationshipName) {
 					unregistered[relationshipName] = true
 				}
 			}
@@ -279,6 +279,8 @@ func TestValidateRelationships(t *testing.T) {
 			Direction:   types.DirectionBackward,
 		})
 
+		manager.AddNode(&types.Node{ID: "other-node", Name: "Other Node"})
+
 		// Add a node using the registered relationship
 		node := &types.Node{
 			ID:   "test-node",
@@ -299,6 +301,8 @@ func TestValidateRelationships(t *testing.T) {
 	t.Run("unregistered relationship in use", func(t *testing.T) {
 		manager := NewManager(log)
 
+		manager.AddNode(&types.Node{ID: "other-node", Name: "Other Node"})
+
 		// Add a node using an unregistered relationship
 		node := &types.Node{
 			ID:   "test-node",
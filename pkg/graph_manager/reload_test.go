@@ -0,0 +1,84 @@
+package graph_manager
+
+import (
+	"errors"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/store"
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestReload(t *testing.T) {
+	log, _ := logger.New(false)
+	memoryStore := store.NewMemoryStore()
+	putNodes := func(nodes ...*types.Node) {
+		t.Helper()
+		for _, node := range nodes {
+			if err := memoryStore.Put(node); err != nil {
+				t.Fatalf("Failed to put node: %v", err)
+			}
+		}
+	}
+	putNodes(&types.Node{ID: "task-a", Name: "Task A", Tags: []string{"old"}})
+
+	manager := NewManager(log)
+	if err := manager.LoadFromStore(memoryStore); err != nil {
+		t.Fatalf("Failed to load from store: %v", err)
+	}
+	registerPrerequisites := func(fresh *Manager) error {
+		return fresh.RegisterRelationship(types.Relationship{Name: "prerequisites", Direction: types.DirectionBackward})
+	}
+
+	// Changes made to the store by someone else are picked up
+	putNodes(
+		&types.Node{ID: "task-a", Name: "Task A", Tags: []string{"new"}},
+		&types.Node{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}},
+	)
+	changed, err := manager.Reload(registerPrerequisites)
+	if err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if !changed {
+		t.Error("Expected reload to report a change")
+	}
+	if nodes, _ := manager.GetNodesByTag("new"); len(nodes) != 1 {
+		t.Errorf("Expected tag cache to be rebuilt, got %v", nodes)
+	}
+	taskB, err := manager.GetNode("task-b")
+	if err != nil {
+		t.Fatalf("Reloaded node missing: %v", err)
+	}
+	if edges := taskB.GetEdges("prerequisites"); len(edges) != 1 || edges[0].Type == nil {
+		t.Error("Reloaded edges should resolve to the reloaded relationship types")
+	}
+
+	changed, err = manager.Reload(registerPrerequisites)
+	if err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if changed {
+		t.Error("Reloading an unchanged store should not report a change")
+	}
+
+	// Invalid graphs are rejected and the current graph is kept
+	putNodes(&types.Node{ID: "task-a", Name: "Task A", EdgeIDs: map[string][]string{"prerequisites": {"task-b"}}})
+	if _, err := manager.Reload(registerPrerequisites); err == nil {
+		t.Error("Expected reload with a cycle to fail")
+	}
+	putNodes(&types.Node{ID: "task-a", Name: "Task A", EdgeIDs: map[string][]string{"prerequisites": {"missing"}}})
+	if _, err := manager.Reload(registerPrerequisites); err == nil {
+		t.Error("Expected reload with a dangling reference to fail")
+	}
+	if taskA, _ := manager.GetNode("task-a"); len(taskA.GetEdgeIDs("prerequisites")) != 0 {
+		t.Error("Rejected reload should leave the current graph unchanged")
+	}
+
+	// Unflushed changes would be lost, so reloading is refused
+	if err := manager.AddNode(&types.Node{ID: "task-c", Name: "Task C"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if _, err := manager.Reload(registerPrerequisites); !errors.Is(err, ErrPendingChanges) {
+		t.Errorf("Expected ErrPendingChanges, got %v", err)
+	}
+}
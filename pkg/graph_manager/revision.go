@@ -0,0 +1,70 @@
+package graph_manager
+
+import (
+	"fmt"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// ConflictError is returned when a node was changed since the revision the
+// caller based its write on
+type ConflictError struct {
+	ID               string
+	ExpectedRevision string
+
+	// Current is the node as it is now
+	Current *types.Node
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("node %s was modified concurrently: expected revision %s, current revision %s",
+		e.ID, e.ExpectedRevision, e.Current.Revision())
+}
+
+// WriteOption configures a single update, patch or delete
+type WriteOption func(*writeOptions)
+
+// writeOptions holds the settings applied by WriteOptions
+type writeOptions struct {
+	expectedRevision       string
+	allowDeprecatedTargets bool
+}
+
+// WithExpectedRevision makes the write fail with a ConflictError unless the
+// node's current revision matches. An empty revision disables the check.
+func WithExpectedRevision(revision string) WriteOption {
+	return func(o *writeOptions) {
+		o.expectedRevision = revision
+	}
+}
+
+// AllowDeprecatedTargets lets the write add edges pointing at deprecated and
+// superseded nodes, which are otherwise refused
+func AllowDeprecatedTargets() WriteOption {
+	return func(o *writeOptions) {
+		o.allowDeprecatedTargets = true
+	}
+}
+
+// newWriteOptions applies the options to the defaults
+func newWriteOptions(opts []WriteOption) writeOptions {
+	var options writeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// checkRevision returns a ConflictError if an expected revision was given and
+// the existing node no longer has it
+func (o writeOptions) checkRevision(existing *types.Node) error {
+	if o.expectedRevision == "" || existing.Revision() == o.expectedRevision {
+		return nil
+	}
+	return &ConflictError{
+		ID:               existing.ID,
+		ExpectedRevision: o.expectedRevision,
+		Current:          existing,
+	}
+}
@@ -0,0 +1,104 @@
+package graph_manager
+
+import (
+	"errors"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestWritesWithExpectedRevision(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+	if err := manager.AddNode(&types.Node{ID: "task-a", Name: "Task A"}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	original, _ := manager.GetNode("task-a")
+	staleRevision := original.Revision()
+
+	// A write based on the current revision succeeds
+	if err := manager.UpdateNode(&types.Node{ID: "task-a", Name: "Renamed"}, WithExpectedRevision(staleRevision)); err != nil {
+		t.Fatalf("Update with current revision failed: %v", err)
+	}
+
+	// Writes based on the old revision conflict
+	var conflict *ConflictError
+	err := manager.UpdateNode(&types.Node{ID: "task-a", Name: "Other"}, WithExpectedRevision(staleRevision))
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected ConflictError from update, got %v", err)
+	}
+	if conflict.Current == nil || conflict.Current.Name != "Renamed" {
+		t.Errorf("Conflict should report the current node, got %v", conflict.Current)
+	}
+
+	name := "Patched"
+	if _, err := manager.PatchNode("task-a", NodePatch{Name: &name}, WithExpectedRevision(staleRevision)); !errors.As(err, &conflict) {
+		t.Errorf("Expected ConflictError from patch, got %v", err)
+	}
+	if err := manager.DeleteNode("task-a", WithExpectedRevision(staleRevision)); !errors.As(err, &conflict) {
+		t.Errorf("Expected ConflictError from delete, got %v", err)
+	}
+	if current, _ := manager.GetNode("task-a"); current.Name != "Renamed" {
+		t.Errorf("Conflicting writes should not change the node, got %q", current.Name)
+	}
+
+	current, _ := manager.GetNode("task-a")
+	if err := manager.DeleteNode("task-a", WithExpectedRevision(current.Revision())); err != nil {
+		t.Errorf("Delete with current revision failed: %v", err)
+	}
+}
+
+func TestTransactionWithExpectedRevision(t *testing.T) {
+	manager := newTestManager(t)
+	addTestNodes(t, manager, &types.Node{ID: "task-a", Name: "Task A"}, &types.Node{ID: "task-b", Name: "Task B"})
+	original, _ := manager.GetNode("task-a")
+	staleRevision := original.Revision()
+	if err := manager.UpdateNode(&types.Node{ID: "task-a", Name: "Renamed"}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+
+	// A stale update or delete fails the whole transaction
+	var conflict *ConflictError
+	for _, stage := range []func(tx *Transaction) error{
+		func(tx *Transaction) error {
+			return tx.UpdateNode(&types.Node{ID: "task-a", Name: "Other"}, WithExpectedRevision(staleRevision))
+		},
+		func(tx *Transaction) error { return tx.DeleteNode("task-a", WithExpectedRevision(staleRevision)) },
+	} {
+		tx := manager.Begin()
+		if err := tx.DeleteNode("task-b"); err != nil {
+			t.Fatalf("Failed to stage delete: %v", err)
+		}
+		if err := stage(tx); err != nil {
+			t.Fatalf("Failed to stage operation: %v", err)
+		}
+		err := tx.Commit()
+		if !errors.As(err, &conflict) {
+			t.Fatalf("Expected ConflictError from commit, got %v", err)
+		}
+		if conflict.Current == nil || conflict.Current.Name != "Renamed" {
+			t.Errorf("Conflict should report the current node, got %v", conflict.Current)
+		}
+	}
+	if _, err := manager.GetNode("task-b"); err != nil {
+		t.Errorf("Conflicting transactions should not change the graph: %v", err)
+	}
+
+	// Every operation is checked against the revision the node had before the
+	// transaction, even after an earlier operation replaced it
+	current, _ := manager.GetNode("task-a")
+	tx := manager.Begin()
+	if err := tx.UpdateNode(&types.Node{ID: "task-a", Name: "First"}, WithExpectedRevision(current.Revision())); err != nil {
+		t.Fatalf("Failed to stage update: %v", err)
+	}
+	if err := tx.UpdateNode(&types.Node{ID: "task-a", Name: "Second"}, WithExpectedRevision(current.Revision())); err != nil {
+		t.Fatalf("Failed to stage update: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit with current revision failed: %v", err)
+	}
+	if node, _ := manager.GetNode("task-a"); node.Name != "Second" {
+		t.Errorf("Expected both updates to be applied, got %q", node.Name)
+	}
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// nodesBucket is the bbolt bucket holding nodes, keyed by node ID
+var nodesBucket = []byte("nodes")
+
+// boltOpenTimeout bounds how long opening waits for another process to release the file
+const boltOpenTimeout = time.Second
+
+// BoltStore keeps all nodes in a single bbolt database file. Nodes are stored
+// as JSON keyed by ID and writes are transactional. LoadAll still decodes every
+// node, since the manager holds the whole graph in memory, so loading time grows
+// with the graph; it is only cheaper than reading one YAML file per node. The
+// file is locked while open, so only one process can use it at a time; Watch
+// therefore reports the changes made through this store.
+type BoltStore struct {
+	path    string
+	db      *bbolt.DB
+	logger  *zap.Logger
+	watches broadcaster
+}
+
+// NewBoltStore opens (or creates) the database file at path
+func NewBoltStore(path string, logger *zap.Logger) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Error("Failed to create directory", zap.String("path", filepath.Dir(path)), zap.Error(err))
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		logger.Error("Failed to open database", zap.String("path", path), zap.Error(err))
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(nodesBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %w", path, err)
+	}
+
+	logger.Info("Opened node database", zap.String("path", path))
+
+	return &BoltStore{path: path, db: db, logger: logger}, nil
+}
+
+// Path returns the database file path
+func (b *BoltStore) Path() string {
+	return b.path
+}
+
+// LoadAll decodes every node in the database
+func (b *BoltStore) LoadAll() ([]*types.Node, error) {
+	b.logger.Info("Loading nodes from database", zap.String("path", b.path))
+
+	var nodes []*types.Node
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(nodesBucket).ForEach(func(key, value []byte) error {
+			var node types.Node
+			if err := json.Unmarshal(value, &node); err != nil {
+				return fmt.Errorf("failed to decode node %s: %w", key, err)
+			}
+			nodes = append(nodes, &node)
+			return nil
+		})
+	})
+	if err != nil {
+		b.logger.Error("Failed to load nodes from database", zap.String("path", b.path), zap.Error(err))
+		return nil, err
+	}
+
+	b.logger.Info("Finished loading nodes from database", zap.Int("nodes_loaded", len(nodes)))
+
+	return nodes, nil
+}
+
+// Put stores the node
+func (b *BoltStore) Put(node *types.Node) error {
+	return b.Apply([]*types.Node{node}, nil)
+}
+
+// Delete removes the node with the given ID
+func (b *BoltStore) Delete(id string) error {
+	return b.Apply(nil, []string{id})
+}
+
+// Apply implements Batcher: all puts and deletes are written in one transaction
+func (b *BoltStore) Apply(puts []*types.Node, deletes []string) error {
+	encoded := make(map[string][]byte, len(puts))
+	for _, node := range puts {
+		if node == nil {
+			return fmt.Errorf("node cannot be nil")
+		}
+		if node.ID == "" {
+			return fmt.Errorf("node ID cannot be empty")
+		}
+		data, err := json.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+		}
+		encoded[node.ID] = data
+	}
+	for _, id := range deletes {
+		if id == "" {
+			return fmt.Errorf("node ID cannot be empty")
+		}
+	}
+
+	var events []Event
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(nodesBucket)
+		for _, node := range puts {
+			if err := bucket.Put([]byte(node.ID), encoded[node.ID]); err != nil {
+				return fmt.Errorf("failed to write node %s: %w", node.ID, err)
+			}
+			events = append(events, Event{Type: EventPut, ID: node.ID})
+		}
+		for _, id := range deletes {
+			if bucket.Get([]byte(id)) == nil {
+				continue
+			}
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return fmt.Errorf("failed to delete node %s: %w", id, err)
+			}
+			events = append(events, Event{Type: EventDelete, ID: id})
+		}
+		return nil
+	})
+	if err != nil {
+		b.logger.Error("Failed to write to database", zap.String("path", b.path), zap.Error(err))
+		return err
+	}
+
+	b.logger.Debug("Applied database changes",
+		zap.Int("puts", len(puts)),
+		zap.Int("deletes", len(deletes)),
+	)
+
+	// Notify watchers only once the transaction has committed
+	for _, event := range events {
+		b.watches.notify(event)
+	}
+
+	return nil
+}
+
+// List returns the IDs of all stored nodes. Keys are kept sorted by bbolt.
+func (b *BoltStore) List() ([]string, error) {
+	var ids []string
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(nodesBucket).ForEach(func(key, _ []byte) error {
+			ids = append(ids, string(key))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return ids, nil
+}
+
+// Watch reports every change made through this store. Each watcher buffers a
+// limited number of events; events are dropped while a watcher's buffer is full.
+func (b *BoltStore) Watch(ctx context.Context) (<-chan Event, error) {
+	return b.watches.subscribe(ctx), nil
+}
+
+// Close closes the database file
+func (b *BoltStore) Close() error {
+	b.logger.Debug("Closing node database", zap.String("path", b.path))
+	return b.db.Close()
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestBoltStorePutLoadDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "nodes.db")
+	boltStore, err := NewBoltStore(path, logger.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	node := &types.Node{
+		ID:      "task-a",
+		Name:    "Task A",
+		Tags:    []string{"test"},
+		EdgeIDs: map[string][]string{"prerequisites": {"task-b"}},
+		Attributes: map[string]any{
+			"prep_time": 10,
+			"rating":    4.5,
+			"owner":     "platform",
+			"critical":  true,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := boltStore.Put(node); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+	if err := boltStore.Put(&types.Node{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+	if err := boltStore.Put(&types.Node{ID: ""}); err == nil {
+		t.Error("Expected error when putting node with empty ID")
+	}
+
+	// Nodes survive reopening the database
+	if err := boltStore.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+	boltStore, err = NewBoltStore(path, logger.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer boltStore.Close()
+
+	loaded, err := boltStore.LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(loaded))
+	}
+	for _, loadedNode := range loaded {
+		if loadedNode.ID == "task-a" && !loadedNode.Equals(node) {
+			t.Error("Loaded node does not match stored node")
+		}
+	}
+
+	if err := boltStore.Delete("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := boltStore.Delete("task-a"); err != nil {
+		t.Errorf("Deleting a missing node should not fail: %v", err)
+	}
+
+	ids, err := boltStore.List()
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "task-b" {
+		t.Errorf("Expected [task-b], got %v", ids)
+	}
+}
+
+func TestBoltStoreApplyIsAtomic(t *testing.T) {
+	boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "nodes.db"), logger.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer boltStore.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := boltStore.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch store: %v", err)
+	}
+
+	if err := boltStore.Apply([]*types.Node{{ID: "task-a"}, {ID: "task-b"}}, nil); err != nil {
+		t.Fatalf("Failed to apply changes: %v", err)
+	}
+
+	// A batch with an invalid node is rejected as a whole
+	if err := boltStore.Apply([]*types.Node{{ID: "task-c"}, {ID: ""}}, []string{"task-a"}); err == nil {
+		t.Fatal("Expected error when applying a node with empty ID")
+	}
+	if ids, _ := boltStore.List(); len(ids) != 2 {
+		t.Errorf("Rejected batch should not change the store, got %v", ids)
+	}
+
+	if err := boltStore.Apply([]*types.Node{{ID: "task-c"}}, []string{"task-a", "missing"}); err != nil {
+		t.Fatalf("Failed to apply changes: %v", err)
+	}
+	if ids, _ := boltStore.List(); len(ids) != 2 || ids[0] != "task-b" || ids[1] != "task-c" {
+		t.Errorf("Expected [task-b task-c], got %v", ids)
+	}
+
+	for _, expected := range []Event{
+		{Type: EventPut, ID: "task-a"},
+		{Type: EventPut, ID: "task-b"},
+		{Type: EventPut, ID: "task-c"},
+		{Type: EventDelete, ID: "task-a"},
+	} {
+		if event := <-events; event != expected {
+			t.Errorf("Expected event %v, got %v", expected, event)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"sync"
+)
+
+// watchBufferSize is the number of events buffered per watcher
+const watchBufferSize = 64
+
+// broadcaster fans out events to in-process watchers. It is used by stores
+// whose changes can only be made through the store itself.
+type broadcaster struct {
+	mu       sync.Mutex
+	watchers map[chan Event]struct{}
+}
+
+// subscribe registers a watcher that is removed and closed when ctx is done
+func (b *broadcaster) subscribe(ctx context.Context) <-chan Event {
+	events := make(chan Event, watchBufferSize)
+
+	b.mu.Lock()
+	if b.watchers == nil {
+		b.watchers = make(map[chan Event]struct{})
+	}
+	b.watchers[events] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.watchers, events)
+		close(events)
+		b.mu.Unlock()
+	}()
+
+	return events
+}
+
+// notify sends an event to every watcher without blocking. Events are dropped
+// while a watcher's buffer is full.
+func (b *broadcaster) notify(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for watcher := range b.watchers {
+		select {
+		case watcher <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// DirStore stores each node as a YAML file named <id>.yaml in a directory.
+// Files are written atomically (temporary file + rename), so a crash never
+// leaves a half-written node. Only .yaml files are read; anything else in the
+// directory is ignored and left alone. Watch reports only changes made by
+// others: the files the store writes and removes itself are left out.
+type DirStore struct {
+	dir    string
+	logger *zap.Logger
+
+	mu sync.Mutex
+
+	// files maps the file names known to exist in dir to the ID of the node
+	// stored in them, so files of renamed or deleted nodes can be removed
+	files map[string]string
+
+	// misplaced holds the IDs of nodes loaded from a file not named after them
+	misplaced []string
+
+	// written maps the files last written by Put to a hash of their content and
+	// removed holds the files removed by the store, so Watch can recognize the
+	// events caused by the store itself
+	written map[string][sha256.Size]byte
+	removed map[string]bool
+}
+
+// NewDirStore creates a store backed by the given directory. The directory is
+// created on first use if it doesn't exist.
+func NewDirStore(dir string, logger *zap.Logger) *DirStore {
+	return &DirStore{
+		dir:     dir,
+		logger:  logger,
+		files:   make(map[string]string),
+		written: make(map[string][sha256.Size]byte),
+		removed: make(map[string]bool),
+	}
+}
+
+// Dir returns the directory the store writes to
+func (d *DirStore) Dir() string {
+	return d.dir
+}
+
+// SameDir reports whether the store writes to the given directory
+func (d *DirStore) SameDir(dir string) bool {
+	return absDir(d.dir) == absDir(dir)
+}
+
+// LoadAll reads every node file in the directory
+func (d *DirStore) LoadAll() ([]*types.Node, error) {
+	d.logger.Info("Loading nodes from directory", zap.String("path", d.dir))
+
+	nodes, files, err := d.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var misplaced []string
+	for filename, id := range files {
+		if filename != nodeFileName(id) {
+			misplaced = append(misplaced, id)
+		}
+	}
+	sort.Strings(misplaced)
+
+	d.mu.Lock()
+	d.files = files
+	d.misplaced = misplaced
+	d.mu.Unlock()
+
+	d.logger.Info("Finished loading node files", zap.Int("nodes_loaded", len(nodes)))
+
+	return nodes, nil
+}
+
+// Misplaced implements Relocator
+func (d *DirStore) Misplaced() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	misplaced := make([]string, len(d.misplaced))
+	copy(misplaced, d.misplaced)
+	return misplaced
+}
+
+// Put writes the node to <id>.yaml and removes any other file it was loaded from
+func (d *DirStore) Put(node *types.Node) error {
+	if node == nil {
+		return fmt.Errorf("node cannot be nil")
+	}
+	if node.ID == "" {
+		return fmt.Errorf("node ID cannot be empty")
+	}
+
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		d.logger.Error("Failed to create directory", zap.String("path", d.dir), zap.Error(err))
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	d.logger.Debug("Marshaling node", zap.String("node_id", node.ID))
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		d.logger.Error("Failed to marshal node", zap.String("node_id", node.ID), zap.Error(err))
+		return fmt.Errorf("failed to marshal node %s: %w", node.ID, err)
+	}
+
+	filename := nodeFileName(node.ID)
+	path := filepath.Join(d.dir, filename)
+
+	// Recorded before writing, as the watcher may see the file before Put returns
+	d.mu.Lock()
+	d.written[filename] = sha256.Sum256(data)
+	delete(d.removed, filename)
+	d.mu.Unlock()
+
+	d.logger.Debug("Writing node file", zap.String("filename", path))
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		d.mu.Lock()
+		delete(d.written, filename)
+		d.mu.Unlock()
+
+		d.logger.Error("Failed to write node file",
+			zap.String("node_id", node.ID),
+			zap.String("filename", path),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to write node %s: %w", node.ID, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.files[filename] = node.ID
+	d.misplaced = removeString(d.misplaced, node.ID)
+
+	// Remove files the node was previously stored in under another name
+	return d.removeFiles(node.ID, filename)
+}
+
+// Delete removes every file known to hold the node, including <id>.yaml
+func (d *DirStore) Delete(id string) error {
+	if id == "" {
+		return fmt.Errorf("node ID cannot be empty")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The canonical file is removed even if it wasn't loaded through this store,
+	// unless it is known to hold another node
+	if _, known := d.files[nodeFileName(id)]; !known {
+		d.files[nodeFileName(id)] = id
+	}
+	d.misplaced = removeString(d.misplaced, id)
+
+	return d.removeFiles(id, "")
+}
+
+// List returns the IDs of the nodes stored in the directory
+func (d *DirStore) List() ([]string, error) {
+	nodes, _, err := d.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(nodes))
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if !seen[node.ID] {
+			seen[node.ID] = true
+			ids = append(ids, node.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Watch reports node files created, changed or removed in the directory.
+// Files that can't be parsed (for example while an editor is still writing
+// them) are skipped until their next change.
+func (d *DirStore) Watch(ctx context.Context) (<-chan Event, error) {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := watcher.Add(d.dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch directory %s: %w", d.dir, err)
+	}
+
+	d.logger.Info("Watching node directory", zap.String("path", d.dir))
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				d.logger.Debug("Stopped watching node directory", zap.String("path", d.dir))
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				d.logger.Warn("File watcher error", zap.String("path", d.dir), zap.Error(err))
+			case fsEvent, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				event, ok := d.translate(fsEvent)
+				if !ok {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Close implements Store. A DirStore holds no resources outside of Watch.
+func (d *DirStore) Close() error {
+	return nil
+}
+
+// translate converts a file system event into a store event, skipping the
+// events caused by the store's own writes and removals
+func (d *DirStore) translate(fsEvent fsnotify.Event) (Event, bool) {
+	filename := filepath.Base(fsEvent.Name)
+	if filepath.Ext(filename) != ".yaml" {
+		return Event{}, false
+	}
+
+	switch {
+	case fsEvent.Has(fsnotify.Create) || fsEvent.Has(fsnotify.Write):
+		data, err := os.ReadFile(fsEvent.Name)
+		if err != nil {
+			d.logger.Debug("Skipping unreadable node file", zap.String("filename", fsEvent.Name), zap.Error(err))
+			return Event{}, false
+		}
+
+		d.mu.Lock()
+		delete(d.removed, filename)
+		hash, written := d.written[filename]
+		own := written && hash == sha256.Sum256(data)
+		if written && !own {
+			// Changed by someone else since the store wrote it
+			delete(d.written, filename)
+		}
+		d.mu.Unlock()
+		if own {
+			d.logger.Debug("Skipping node file written by this store", zap.String("filename", fsEvent.Name))
+			return Event{}, false
+		}
+
+		node, err := unmarshalNode(data, fsEvent.Name)
+		if err != nil || node.ID == "" {
+			d.logger.Debug("Skipping unreadable node file", zap.String("filename", fsEvent.Name), zap.Error(err))
+			return Event{}, false
+		}
+
+		d.mu.Lock()
+		d.files[filename] = node.ID
+		d.mu.Unlock()
+
+		return Event{Type: EventPut, ID: node.ID}, true
+	case fsEvent.Has(fsnotify.Remove) || fsEvent.Has(fsnotify.Rename):
+		d.mu.Lock()
+		id, known := d.files[filename]
+		delete(d.files, filename)
+		delete(d.written, filename)
+		own := d.removed[filename]
+		delete(d.removed, filename)
+		d.mu.Unlock()
+
+		if own {
+			d.logger.Debug("Skipping node file removed by this store", zap.String("filename", fsEvent.Name))
+			return Event{}, false
+		}
+
+		if !known {
+			id = strings.TrimSuffix(filename, ".yaml")
+		}
+		return Event{Type: EventDelete, ID: id}, true
+	}
+
+	return Event{}, false
+}
+
+// removeFiles removes every known file holding the node except keep.
+// The caller must hold mu.
+func (d *DirStore) removeFiles(id, keep string) error {
+	for filename, fileID := range d.files {
+		if fileID != id || filename == keep {
+			continue
+		}
+
+		path := filepath.Join(d.dir, filename)
+		if keep != "" {
+			d.logger.Debug("Removing file of renamed node",
+				zap.String("node_id", id),
+				zap.String("old_filename", filename),
+				zap.String("new_filename", keep),
+			)
+		} else {
+			d.logger.Debug("Removing file of deleted node",
+				zap.String("node_id", id),
+				zap.String("filename", filename),
+			)
+		}
+
+		d.removed[filename] = true
+		delete(d.written, filename)
+		err := os.Remove(path)
+		if err != nil {
+			// No event will arrive for a file that wasn't removed
+			delete(d.removed, filename)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			d.logger.Error("Failed to remove stale node file",
+				zap.String("filename", path),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to remove stale node file %s: %w", filename, err)
+		}
+		delete(d.files, filename)
+	}
+	return nil
+}
+
+// readAll parses every .yaml file in the directory, creating it if needed
+func (d *DirStore) readAll() ([]*types.Node, map[string]string, error) {
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		d.logger.Error("Failed to create directory", zap.String("path", d.dir), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		d.logger.Error("Failed to read directory", zap.String("path", d.dir), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	d.logger.Debug("Found directory entries", zap.Int("count", len(entries)))
+
+	nodes := make([]*types.Node, 0, len(entries))
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			d.logger.Debug("Skipping non-YAML file", zap.String("filename", entry.Name()))
+			continue
+		}
+
+		filename := filepath.Join(d.dir, entry.Name())
+		d.logger.Debug("Reading node file", zap.String("filename", filename))
+
+		node, err := readNodeFile(filename)
+		if err != nil {
+			d.logger.Error("Failed to read node file", zap.String("filename", entry.Name()), zap.Error(err))
+			return nil, nil, err
+		}
+
+		nodes = append(nodes, node)
+		files[entry.Name()] = node.ID
+		d.logger.Debug("Loaded node from file",
+			zap.String("node_id", node.ID),
+			zap.String("node_name", node.Name),
+			zap.String("filename", entry.Name()),
+		)
+	}
+
+	return nodes, files, nil
+}
+
+// readNodeFile parses a single node file
+func readNodeFile(filename string) (*types.Node, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filepath.Base(filename), err)
+	}
+	return unmarshalNode(data, filename)
+}
+
+// unmarshalNode parses the content of the named node file
+func unmarshalNode(data []byte, filename string) (*types.Node, error) {
+	var node types.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node from %s: %w", filepath.Base(filename), err)
+	}
+	return &node, nil
+}
+
+// nodeFileName returns the file name a node with the given ID is stored under
+func nodeFileName(id string) string {
+	return fmt.Sprintf("%s.yaml", id)
+}
+
+// writeFileAtomic writes data to a temporary file in the target directory and
+// renames it into place, so readers and crashes never observe a partial file.
+// The temporary file name doesn't end in .yaml, so it is never loaded as a node.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// Clean up the temporary file on any failure
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	success = true
+	return nil
+}
+
+// absDir returns a cleaned absolute form of the path for comparisons
+func absDir(dirPath string) string {
+	if abs, err := filepath.Abs(dirPath); err == nil {
+		return abs
+	}
+	return filepath.Clean(dirPath)
+}
+
+// removeString returns the slice without any occurrence of the value
+func removeString(slice []string, value string) []string {
+	result := slice[:0:0]
+	for _, v := range slice {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+func TestDirStorePutLoadDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nodes")
+	dirStore := NewDirStore(dir, logger.NewNop())
+	now := time.Now().UTC().Truncate(time.Second)
+
+	node := &types.Node{
+		ID:      "task-a",
+		Name:    "Task A",
+		Tags:    []string{"test"},
+		EdgeIDs: map[string][]string{"prerequisites": {"task-b"}},
+		Attributes: map[string]any{
+			"prep_time": 10,
+			"rating":    4.5,
+			"owner":     "platform",
+			"critical":  true,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := dirStore.Put(node); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+	if err := dirStore.Put(&types.Node{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+
+	loaded, err := NewDirStore(dir, logger.NewNop()).LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(loaded))
+	}
+	for _, loadedNode := range loaded {
+		if loadedNode.ID == "task-a" && !loadedNode.Equals(node) {
+			t.Error("Loaded node does not match stored node")
+		}
+	}
+
+	if err := dirStore.Delete("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := dirStore.Delete("task-a"); err != nil {
+		t.Errorf("Deleting a missing node should not fail: %v", err)
+	}
+
+	ids, err := dirStore.List()
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "task-b" {
+		t.Errorf("Expected [task-b], got %v", ids)
+	}
+}
+
+func TestDirStoreRelocatesMisplacedNodes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "old-name.yaml"), []byte("id: task-a\nname: Task A\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a node"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	dirStore := NewDirStore(dir, logger.NewNop())
+	nodes, err := dirStore.LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("Expected 1 node, got %d", len(nodes))
+	}
+	if misplaced := dirStore.Misplaced(); len(misplaced) != 1 || misplaced[0] != "task-a" {
+		t.Errorf("Expected task-a to be misplaced, got %v", misplaced)
+	}
+
+	if err := dirStore.Put(nodes[0]); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old-name.yaml")); !os.IsNotExist(err) {
+		t.Error("Old file should have been removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Non-YAML file should be left alone: %v", err)
+	}
+	if misplaced := dirStore.Misplaced(); len(misplaced) != 0 {
+		t.Errorf("Expected no misplaced nodes after put, got %v", misplaced)
+	}
+}
+
+func TestDirStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	dirStore := NewDirStore(dir, logger.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := dirStore.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch directory: %v", err)
+	}
+
+	// A change made by another process
+	if err := os.WriteFile(filepath.Join(dir, "task-a.yaml"), []byte("id: task-a\nname: Task A\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+	waitForEvent(t, events, Event{Type: EventPut, ID: "task-a"})
+
+	if err := os.Remove(filepath.Join(dir, "task-a.yaml")); err != nil {
+		t.Fatalf("Failed to remove node file: %v", err)
+	}
+	waitForEvent(t, events, Event{Type: EventDelete, ID: "task-a"})
+
+	cancel()
+	for range events {
+		// Drain until the watcher closes the channel
+	}
+}
+
+func TestDirStoreWatchSkipsOwnChanges(t *testing.T) {
+	dir := t.TempDir()
+	dirStore := NewDirStore(dir, logger.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := dirStore.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch directory: %v", err)
+	}
+
+	for _, id := range []string{"task-a", "task-b"} {
+		if err := dirStore.Put(&types.Node{ID: id, Name: id}); err != nil {
+			t.Fatalf("Failed to put node: %v", err)
+		}
+	}
+	if err := dirStore.Delete("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+
+	// Events arrive in order, so the store's own changes would be reported first
+	if err := os.WriteFile(filepath.Join(dir, "task-c.yaml"), []byte("id: task-c\nname: Task C\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+	expectNextEvent(t, events, Event{Type: EventPut, ID: "task-c"})
+
+	// Another process changing a file the store wrote is reported
+	if err := os.WriteFile(filepath.Join(dir, "task-b.yaml"), []byte("id: task-b\nname: Task B v2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write node file: %v", err)
+	}
+	expectNextEvent(t, events, Event{Type: EventPut, ID: "task-b"})
+
+	cancel()
+	for range events {
+		// Drain until the watcher closes the channel
+	}
+}
+
+// expectNextEvent fails the test unless the next event is the expected one.
+// Repeats of it are allowed, as a file written in several steps may be
+// reported more than once.
+func expectNextEvent(t *testing.T, events <-chan Event, expected Event) {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatalf("Event channel closed while waiting for %v", expected)
+		}
+		if event != expected {
+			t.Fatalf("Expected %v, got %v", expected, event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %v", expected)
+	}
+	for {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Fatalf("Expected only %v, got %v", expected, event)
+			}
+		case <-time.After(100 * time.Millisecond):
+			return
+		}
+	}
+}
+
+// waitForEvent reads events until the expected one arrives or the test times out
+func waitForEvent(t *testing.T, events <-chan Event, expected Event) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Event channel closed while waiting for %v", expected)
+			}
+			if event == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %v", expected)
+		}
+	}
+}
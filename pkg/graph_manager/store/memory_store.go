@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// MemoryStore keeps nodes in memory. It is intended for tests and for running
+// without persistence; nothing survives a restart.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	nodes   map[string]*types.Node
+	watches broadcaster
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nodes: make(map[string]*types.Node),
+	}
+}
+
+// LoadAll returns copies of every stored node
+func (s *MemoryStore) LoadAll() ([]*types.Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nodes := make([]*types.Node, 0, len(s.nodes))
+	for _, id := range s.sortedIDs() {
+		nodes = append(nodes, s.nodes[id].Clone())
+	}
+	return nodes, nil
+}
+
+// Put stores a copy of the node
+func (s *MemoryStore) Put(node *types.Node) error {
+	if node == nil {
+		return fmt.Errorf("node cannot be nil")
+	}
+	if node.ID == "" {
+		return fmt.Errorf("node ID cannot be empty")
+	}
+
+	stored := node.Clone()
+	stored.Edges = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes[node.ID] = stored
+	s.watches.notify(Event{Type: EventPut, ID: node.ID})
+	return nil
+}
+
+// Delete removes the node with the given ID
+func (s *MemoryStore) Delete(id string) error {
+	if id == "" {
+		return fmt.Errorf("node ID cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.nodes[id]; !exists {
+		return nil
+	}
+	delete(s.nodes, id)
+	s.watches.notify(Event{Type: EventDelete, ID: id})
+	return nil
+}
+
+// List returns the IDs of all stored nodes
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedIDs(), nil
+}
+
+// Watch reports every Put and Delete. Each watcher buffers a limited number of
+// events; events are dropped while a watcher's buffer is full.
+func (s *MemoryStore) Watch(ctx context.Context) (<-chan Event, error) {
+	return s.watches.subscribe(ctx), nil
+}
+
+// Close implements Store. A MemoryStore holds no resources.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// sortedIDs returns the stored node IDs in sorted order.
+// The caller must hold the lock.
+func (s *MemoryStore) sortedIDs() []string {
+	ids := make([]string, 0, len(s.nodes))
+	for id := range s.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
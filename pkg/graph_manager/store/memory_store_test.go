@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+func TestMemoryStore(t *testing.T) {
+	memoryStore := NewMemoryStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := memoryStore.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch store: %v", err)
+	}
+
+	node := &types.Node{ID: "task-a", Name: "Task A", Tags: []string{"test"}}
+	if err := memoryStore.Put(node); err != nil {
+		t.Fatalf("Failed to put node: %v", err)
+	}
+
+	// The store keeps its own copy
+	node.Tags[0] = "modified"
+	loaded, err := memoryStore.LoadAll()
+	if err != nil {
+		t.Fatalf("Failed to load nodes: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Tags[0] != "test" {
+		t.Errorf("Stored node should not be affected by caller changes, got %v", loaded)
+	}
+
+	if err := memoryStore.Put(&types.Node{ID: ""}); err == nil {
+		t.Error("Expected error when putting node with empty ID")
+	}
+
+	if err := memoryStore.Delete("task-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := memoryStore.Delete("task-a"); err != nil {
+		t.Errorf("Deleting a missing node should not fail: %v", err)
+	}
+	if ids, _ := memoryStore.List(); len(ids) != 0 {
+		t.Errorf("Expected no nodes, got %v", ids)
+	}
+
+	for _, expected := range []Event{{Type: EventPut, ID: "task-a"}, {Type: EventDelete, ID: "task-a"}} {
+		if event := <-events; event != expected {
+			t.Errorf("Expected event %v, got %v", expected, event)
+		}
+	}
+
+	cancel()
+	for range events {
+		// Drain until the watcher closes the channel
+	}
+}
@@ -0,0 +1,68 @@
+// Package store defines the storage backends the graph manager persists nodes to.
+package store
+
+import (
+	"context"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// Store persists nodes. Implementations must be safe for concurrent use.
+// Nodes passed to Put and returned by LoadAll are not retained or shared:
+// the store works on its own copies or serialized forms.
+type Store interface {
+	// LoadAll returns every stored node
+	LoadAll() ([]*types.Node, error)
+
+	// Put creates or replaces the stored node with the same ID
+	Put(node *types.Node) error
+
+	// Delete removes the node with the given ID. Deleting a node that isn't
+	// stored is not an error.
+	Delete(id string) error
+
+	// List returns the IDs of all stored nodes in sorted order
+	List() ([]string, error)
+
+	// Watch reports changes to the store until ctx is cancelled, at which point
+	// the channel is closed. Stores only this process uses report the changes
+	// made through them; stores others can change, such as DirStore, report
+	// only the changes made by others.
+	Watch(ctx context.Context) (<-chan Event, error)
+
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// Batcher is implemented by stores that can apply several changes atomically
+type Batcher interface {
+	// Apply writes the puts and performs the deletes in a single transaction:
+	// either every change is applied or none is
+	Apply(puts []*types.Node, deletes []string) error
+}
+
+// Relocator is implemented by stores that can hold a node somewhere other than
+// its canonical location, such as a file whose name doesn't match the node ID.
+// Putting such a node again moves it to its canonical location.
+type Relocator interface {
+	// Misplaced returns the IDs of nodes found in a non-canonical location by
+	// the last LoadAll
+	Misplaced() []string
+}
+
+// EventType identifies the kind of change reported by Watch
+type EventType string
+
+const (
+	// EventPut reports that a node was created or replaced
+	EventPut EventType = "put"
+
+	// EventDelete reports that a node was removed
+	EventDelete EventType = "delete"
+)
+
+// Event is a single change reported by Watch
+type Event struct {
+	Type EventType
+	ID   string
+}
@@ -9,6 +9,15 @@ import (
 // PopulateTagCache builds the tag cache by iterating through all nodes
 // and indexing them by their tags for efficient tag-based lookups
 func (m *Manager) PopulateTagCache() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.populateTagCache()
+}
+
+// populateTagCache is the lock-free implementation of PopulateTagCache.
+// The caller must hold the write lock.
+func (m *Manager) populateTagCache() {
 	// Clear existing cache
 	m.tagCache = make(map[string][]*types.Node)
 
@@ -20,23 +29,73 @@ func (m *Manager) PopulateTagCache() {
 	}
 }
 
+// addToTagCache indexes a single node by its tags.
+// The caller must hold the write lock.
+func (m *Manager) addToTagCache(node *types.Node) {
+	for _, tag := range node.Tags {
+		m.tagCache[tag] = append(m.tagCache[tag], node)
+	}
+}
+
+// removeFromTagCache removes a single node from the tag cache, dropping tags
+// that no longer have any nodes.
+// The caller must hold the write lock.
+func (m *Manager) removeFromTagCache(node *types.Node) {
+	for _, tag := range node.Tags {
+		nodes, exists := m.tagCache[tag]
+		if !exists {
+			continue
+		}
+
+		remaining := make([]*types.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n != node {
+				remaining = append(remaining, n)
+			}
+		}
+
+		if len(remaining) == 0 {
+			delete(m.tagCache, tag)
+		} else {
+			m.tagCache[tag] = remaining
+		}
+	}
+}
+
+// replaceInTagCache swaps a cached node for its new version.
+// The caller must hold the write lock.
+func (m *Manager) replaceInTagCache(old, node *types.Node) {
+	m.removeFromTagCache(old)
+	m.addToTagCache(node)
+}
+
 // GetNodesByTag retrieves all nodes with the specified tag
 func (m *Manager) GetNodesByTag(tag string) ([]*types.Node, error) {
 	if tag == "" {
 		return nil, fmt.Errorf("tag cannot be empty")
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	nodes, exists := m.tagCache[tag]
 	if !exists {
 		return []*types.Node{}, nil
 	}
 
-	return nodes, nil
+	// Return a copy so the caller's snapshot is unaffected by later mutations
+	snapshot := make([]*types.Node, len(nodes))
+	copy(snapshot, nodes)
+
+	return snapshot, nil
 }
 
 // GetAllTags retrieves all unique tags from the tag cache
 // Returns a map where keys are tag names and values are the count of nodes with that tag
 func (m *Manager) GetAllTags() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	tags := make(map[string]int)
 
 	for tag, nodes := range m.tagCache {
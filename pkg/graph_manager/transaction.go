@@ -0,0 +1,450 @@
+package graph_manager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// ErrTransactionClosed is returned when a transaction is used after Commit or Rollback
+var ErrTransactionClosed = errors.New("transaction already committed or rolled back")
+
+// OperationType identifies the kind of change staged in a transaction
+type OperationType string
+
+const (
+	// OperationAdd stages the creation of a new node
+	OperationAdd OperationType = "add"
+
+	// OperationUpdate stages the replacement of an existing node
+	OperationUpdate OperationType = "update"
+
+	// OperationDelete stages the removal of a node and all references to it
+	OperationDelete OperationType = "delete"
+)
+
+// Operation is a single change staged in a transaction
+type Operation struct {
+	Type OperationType
+
+	// ID is the target node ID (always set, taken from Node for adds and updates)
+	ID string
+
+	// Node is the new node state for adds and updates (nil for deletes)
+	Node *types.Node
+
+	// AllowDeprecatedTargets lets an add or update point new edges at
+	// deprecated and superseded nodes
+	AllowDeprecatedTargets bool
+
+	// ExpectedRevision makes an update or delete fail with a ConflictError
+	// unless the node still has this revision when the transaction commits
+	ExpectedRevision string
+}
+
+// Transaction stages node additions, updates and deletions and applies them
+// atomically. Intermediate states are never visible to readers; the final graph
+// is validated once on Commit (cycles, dangling references and unregistered
+// relationships) and either fully applied or discarded.
+// Unless the manager allows forward references, each staged addition or update
+// must only reference nodes that exist once the operations staged before it are
+// applied, and is rejected with a *DanglingReferenceError when staged.
+// A Transaction is not safe for concurrent use.
+type Transaction struct {
+	manager    *Manager
+	operations []Operation
+	closed     bool
+}
+
+// Begin starts a new transaction against the manager
+func (m *Manager) Begin() *Transaction {
+	m.logger.Debug("Beginning transaction")
+	return &Transaction{manager: m}
+}
+
+// AddNode stages the addition of a node. Only AllowDeprecatedTargets applies
+// to staged additions.
+func (tx *Transaction) AddNode(node *types.Node, opts ...WriteOption) error {
+	if node == nil {
+		return fmt.Errorf("node cannot be nil")
+	}
+	return tx.stage(Operation{
+		Type:                   OperationAdd,
+		ID:                     node.ID,
+		Node:                   node,
+		AllowDeprecatedTargets: newWriteOptions(opts).allowDeprecatedTargets,
+	})
+}
+
+// UpdateNode stages the replacement of an existing node. Pass
+// WithExpectedRevision to fail the commit with a ConflictError if the node
+// changed meanwhile.
+func (tx *Transaction) UpdateNode(node *types.Node, opts ...WriteOption) error {
+	if node == nil {
+		return fmt.Errorf("node cannot be nil")
+	}
+	options := newWriteOptions(opts)
+	return tx.stage(Operation{
+		Type:                   OperationUpdate,
+		ID:                     node.ID,
+		Node:                   node,
+		AllowDeprecatedTargets: options.allowDeprecatedTargets,
+		ExpectedRevision:       options.expectedRevision,
+	})
+}
+
+// DeleteNode stages the removal of a node. Pass WithExpectedRevision to fail
+// the commit with a ConflictError if the node changed meanwhile.
+func (tx *Transaction) DeleteNode(id string, opts ...WriteOption) error {
+	return tx.stage(Operation{
+		Type:             OperationDelete,
+		ID:               id,
+		ExpectedRevision: newWriteOptions(opts).expectedRevision,
+	})
+}
+
+// Operations returns the operations staged so far, in order
+func (tx *Transaction) Operations() []Operation {
+	operations := make([]Operation, len(tx.operations))
+	copy(operations, tx.operations)
+	return operations
+}
+
+// stage appends an operation after basic validation
+func (tx *Transaction) stage(op Operation) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if op.ID == "" {
+		return fmt.Errorf("node ID cannot be empty")
+	}
+	if op.Node != nil {
+		if err := tx.checkReferences(op.Node); err != nil {
+			return err
+		}
+	}
+
+	tx.operations = append(tx.operations, op)
+	tx.manager.logger.Debug("Staged transaction operation",
+		zap.String("operation", string(op.Type)),
+		zap.String("node_id", op.ID),
+	)
+	return nil
+}
+
+// checkReferences rejects edges to nodes that won't exist once the operations
+// staged so far are applied. It is a no-op when forward references are allowed,
+// in which case dangling references are only rejected on Commit.
+func (tx *Transaction) checkReferences(node *types.Node) error {
+	m := tx.manager
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.allowForwardReferences {
+		return nil
+	}
+
+	dangling := findDanglingReferences(node, func(id string) bool {
+		// The most recent staged operation on the ID decides whether it exists
+		for i := len(tx.operations) - 1; i >= 0; i-- {
+			if tx.operations[i].ID == id {
+				return tx.operations[i].Type != OperationDelete
+			}
+		}
+		return m.nodeExists(id)
+	})
+	if dangling != nil {
+		m.logger.Debug("Rejected staged operation with missing references",
+			zap.String("node_id", node.ID),
+			zap.Strings("missing_ids", dangling.MissingIDs()),
+		)
+		return dangling
+	}
+
+	return nil
+}
+
+// Rollback discards all staged operations. Rolling back a closed transaction is a no-op.
+func (tx *Transaction) Rollback() {
+	if tx.closed {
+		return
+	}
+	tx.manager.logger.Debug("Rolling back transaction", zap.Int("operations", len(tx.operations)))
+	tx.operations = nil
+	tx.closed = true
+}
+
+// Commit validates the staged operations against the current graph and applies
+// them atomically. If validation fails nothing is changed and the transaction
+// is closed.
+func (tx *Transaction) Commit() error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	tx.closed = true
+
+	m := tx.manager
+	m.logger.Debug("Committing transaction", zap.Int("operations", len(tx.operations)))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	staged, err := m.applyOperations(tx.operations)
+	if err != nil {
+		m.logger.Warn("Transaction rejected", zap.Error(err))
+		return err
+	}
+
+	// Nothing below can fail. Publish only the nodes the staged graph replaced,
+	// added or removed, the same way single mutations do.
+	var published []string
+	for _, id := range sortedKeys(staged.dirty) {
+		old, existed := m.nodes[id]
+		node, exists := staged.nodes[id]
+		switch {
+		case existed && !exists:
+			m.removeNode(old)
+		case !existed && exists:
+			m.insertNode(node)
+			published = append(published, id)
+		case existed && old != node:
+			m.replaceNode(old, node)
+			published = append(published, id)
+		}
+	}
+	m.refreshEdges(published...)
+
+	m.logger.Info("Transaction committed",
+		zap.Int("operations", len(tx.operations)),
+		zap.Int("total_nodes", len(m.nodes)),
+	)
+
+	return nil
+}
+
+// applyOperations applies the operations in order to a staging copy of the graph
+// and validates the result. The staging manager shares unchanged nodes with m.
+// The caller must hold the lock.
+func (m *Manager) applyOperations(operations []Operation) (*Manager, error) {
+	staged := &Manager{
+		nodes:             make(map[string]*types.Node, len(m.nodes)),
+		relationshipTypes: m.relationshipTypes,
+		tagCache:          make(map[string][]*types.Node),
+		logger:            m.logger,
+		nodeKinds:         m.nodeKinds,
+		attributes:        m.attributes,
+	}
+	for id, node := range m.nodes {
+		staged.nodes[id] = node
+	}
+
+	// Track the nodes whose edges were written so validation can focus on them
+	changed := make(map[string]bool)
+
+	// Nodes that only lost edges to deleted nodes are checked for constraints
+	// the deletes break
+	cleaned := make(map[string]bool)
+
+	// New edges are checked against the status of their targets when they are
+	// staged, so edges to a node deprecated later in the transaction are kept
+	var deprecated []ConstraintViolation
+	lookup := func(id string) *types.Node { return staged.nodes[id] }
+
+	for i, op := range operations {
+		switch op.Type {
+		case OperationAdd:
+			if _, exists := staged.nodes[op.ID]; exists {
+				return nil, fmt.Errorf("operation %d: node with ID %s already exists", i+1, op.ID)
+			}
+			node := staged.withAttributeDefaults(op.Node)
+			if !op.AllowDeprecatedTargets {
+				deprecated = append(deprecated, deprecatedTargetViolations(nil, node, lookup)...)
+			}
+			staged.mirror(nil, node, changed)
+			staged.stageNode(node)
+			changed[op.ID] = true
+		case OperationUpdate:
+			existing, exists := staged.nodes[op.ID]
+			if !exists {
+				return nil, fmt.Errorf("operation %d: node with ID %s not found", i+1, op.ID)
+			}
+			if err := m.checkOperationRevision(op, existing); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i+1, err)
+			}
+			node := staged.withAttributeDefaults(op.Node)
+			if !op.AllowDeprecatedTargets {
+				deprecated = append(deprecated, deprecatedTargetViolations(existing, node, lookup)...)
+			}
+			staged.mirror(existing, node, changed)
+			staged.stageNode(node)
+			changed[op.ID] = true
+		case OperationDelete:
+			existing, exists := staged.nodes[op.ID]
+			if !exists {
+				return nil, fmt.Errorf("operation %d: node with ID %s not found", i+1, op.ID)
+			}
+			if err := m.checkOperationRevision(op, existing); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i+1, err)
+			}
+			referrers := staged.cleanReferrers(op.ID)
+			staged.purgeNode(op.ID, referrers)
+			for _, referrer := range referrers {
+				cleaned[referrer.new.ID] = true
+			}
+			delete(changed, op.ID)
+			delete(cleaned, op.ID)
+		default:
+			return nil, fmt.Errorf("operation %d: unknown operation type %q", i+1, op.Type)
+		}
+	}
+
+	if err := staged.validateChangedNodes(changed); err != nil {
+		return nil, err
+	}
+	if len(deprecated) > 0 {
+		return nil, fmt.Errorf("transaction validation failed: %w", &ConstraintError{Violations: deprecated})
+	}
+
+	var changedNodes []*types.Node
+	for _, id := range sortedKeys(changed) {
+		if node, exists := staged.nodes[id]; exists {
+			changedNodes = append(changedNodes, node)
+		}
+	}
+	if err := staged.checkConstraints(changedNodes...); err != nil {
+		return nil, fmt.Errorf("transaction validation failed: %w", err)
+	}
+
+	var referrers []nodeReplacement
+	for _, id := range sortedKeys(cleaned) {
+		old, existed := m.nodes[id]
+		node, exists := staged.nodes[id]
+		if existed && exists && !changed[id] {
+			referrers = append(referrers, nodeReplacement{old: old, new: node})
+		}
+	}
+	if err := staged.checkReferrerConstraints(referrers, lookup); err != nil {
+		return nil, fmt.Errorf("transaction validation failed: %w", err)
+	}
+
+	// Nodes superseded by a deleted node must not be left without their
+	// replacement. Changed nodes had their replacement checked above.
+	deleted := make(map[string]bool)
+	for _, op := range operations {
+		if _, exists := staged.nodes[op.ID]; op.Type == OperationDelete && !exists {
+			deleted[op.ID] = true
+		}
+	}
+	if len(deleted) > 0 {
+		if violations := staged.replacementViolations(deleted, changed); len(violations) > 0 {
+			return nil, fmt.Errorf("transaction validation failed: %w", &ConstraintError{Violations: violations})
+		}
+	}
+
+	// Any new cycle must pass through an edge of a changed node
+	for _, id := range sortedKeys(changed) {
+		node, exists := staged.nodes[id]
+		if !exists {
+			continue
+		}
+		if err := staged.detectCyclesFrom(node, lookup); err != nil {
+			return nil, fmt.Errorf("transaction would introduce cycle: %w", err)
+		}
+	}
+
+	// The relationships must also agree on the execution order
+	if err := staged.detectOrderingConflicts(changedNodes, lookup); err != nil {
+		return nil, fmt.Errorf("transaction would introduce cycle: %w", err)
+	}
+
+	return staged, nil
+}
+
+// checkOperationRevision checks the expected revision of an operation against
+// the node as it was before the transaction, since that is the revision the
+// caller read; earlier operations in the same transaction may already have
+// replaced it, for example by mirroring inverse edges. Nodes added earlier in
+// the transaction are checked as staged.
+// The caller must hold the lock.
+func (m *Manager) checkOperationRevision(op Operation, staged *types.Node) error {
+	existing, exists := m.nodes[op.ID]
+	if !exists {
+		existing = staged
+	}
+	return writeOptions{expectedRevision: op.ExpectedRevision}.checkRevision(existing)
+}
+
+// mirror stores the nodes whose inverse edges change with a node and records
+// them as changed.
+// The caller must hold the lock.
+func (m *Manager) mirror(before, after *types.Node, changed map[string]bool) {
+	for _, replacement := range m.mirrorInverseEdges(before, after) {
+		m.stageNode(replacement.new)
+		changed[replacement.new.ID] = true
+	}
+}
+
+// stageNode stores a node in a staging graph and records it as dirty. The
+// incoming edge index is kept up to date once it has been built, since deletes
+// staged later read it to find the nodes pointing at the deleted node.
+// The caller must hold the lock.
+func (m *Manager) stageNode(node *types.Node) {
+	if m.incoming != nil {
+		if old, exists := m.nodes[node.ID]; exists {
+			m.unindexEdges(old)
+		}
+		m.indexEdges(node)
+	}
+	m.nodes[node.ID] = node
+	m.markDirty(node.ID)
+}
+
+// validateChangedNodes checks that the edges of the given nodes only use
+// registered relationships and only reference nodes that exist. Missing
+// references are reported as a wrapped *DanglingReferenceError.
+// The caller must hold the lock.
+func (m *Manager) validateChangedNodes(changed map[string]bool) error {
+	var problems []string
+	dangling := &DanglingReferenceError{}
+
+	for _, id := range sortedKeys(changed) {
+		node, exists := m.nodes[id]
+		if !exists {
+			continue
+		}
+
+		for relationshipName, targetIDs := range node.EdgeIDs {
+			if len(targetIDs) > 0 && !m.isRelationshipRegistered(relationshipName) {
+				problems = append(problems, fmt.Sprintf("%s: unregistered relationship %s", id, relationshipName))
+			}
+		}
+		dangling.merge(findDanglingReferences(node, m.nodeExists))
+	}
+
+	switch {
+	case len(dangling.Missing) > 0 && len(problems) > 0:
+		return fmt.Errorf("transaction validation failed:\n  - %s\n  - %w", strings.Join(problems, "\n  - "), dangling)
+	case len(dangling.Missing) > 0:
+		return fmt.Errorf("transaction validation failed: %w", dangling)
+	case len(problems) > 0:
+		return fmt.Errorf("transaction validation failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,384 @@
+package graph_manager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+)
+
+// transactionRelationships are the relationships transactions are tested with
+var transactionRelationships = []types.Relationship{
+	{Name: "prerequisites", Description: "Tasks that must complete before this one", Direction: types.DirectionBackward},
+	{Name: "related_to", Description: "Related tasks", Direction: types.DirectionNone},
+}
+
+func TestTransactionCommit(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name                   string
+		setupNodes             []*types.Node
+		allowForwardReferences bool
+		stage                  func(tx *Transaction) error
+		wantError              bool
+		expectedError          string
+		expectedCount          int
+		validateGraph          func(t *testing.T, manager *Manager)
+	}{
+		{
+			name:                   "add nodes that reference each other",
+			allowForwardReferences: true,
+			stage: func(tx *Transaction) error {
+				if err := tx.AddNode(&types.Node{
+					ID:        "task-a",
+					Name:      "Task A",
+					EdgeIDs:   map[string][]string{"related_to": {"task-b"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				}); err != nil {
+					return err
+				}
+				return tx.AddNode(&types.Node{
+					ID:        "task-b",
+					Name:      "Task B",
+					EdgeIDs:   map[string][]string{"prerequisites": {"task-a"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+			},
+			expectedCount: 2,
+			validateGraph: func(t *testing.T, manager *Manager) {
+				nodeA := manager.nodes["task-a"]
+				edges := nodeA.GetEdges("related_to")
+				if len(edges) != 1 || edges[0].To != manager.nodes["task-b"] {
+					t.Error("task-a should have a resolved edge to task-b")
+				}
+				if nodes, _ := manager.GetNodesByTag("missing"); len(nodes) != 0 {
+					t.Error("Tag cache should be rebuilt after commit")
+				}
+			},
+		},
+		{
+			name: "restructure through an intermediate cycle",
+			setupNodes: []*types.Node{
+				{ID: "task-a", Name: "Task A", EdgeIDs: map[string][]string{"prerequisites": {"task-b"}}, CreatedAt: now, UpdatedAt: now},
+				{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				// Reverse the dependency: the first step alone would be a cycle
+				if err := tx.UpdateNode(&types.Node{
+					ID:        "task-b",
+					Name:      "Task B",
+					EdgeIDs:   map[string][]string{"prerequisites": {"task-a"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				}); err != nil {
+					return err
+				}
+				return tx.UpdateNode(&types.Node{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now})
+			},
+			expectedCount: 2,
+			validateGraph: func(t *testing.T, manager *Manager) {
+				if ids := manager.nodes["task-a"].GetEdgeIDs("prerequisites"); len(ids) != 0 {
+					t.Errorf("task-a should have no prerequisites, got %v", ids)
+				}
+				if ids := manager.nodes["task-b"].GetEdgeIDs("prerequisites"); len(ids) != 1 || ids[0] != "task-a" {
+					t.Errorf("task-b should depend on task-a, got %v", ids)
+				}
+			},
+		},
+		{
+			name: "delete cleans up references",
+			setupNodes: []*types.Node{
+				{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now},
+				{ID: "task-b", Name: "Task B", EdgeIDs: map[string][]string{"prerequisites": {"task-a"}}, CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				return tx.DeleteNode("task-a")
+			},
+			expectedCount: 1,
+			validateGraph: func(t *testing.T, manager *Manager) {
+				if ids := manager.nodes["task-b"].GetEdgeIDs("prerequisites"); len(ids) != 0 {
+					t.Errorf("task-b should no longer reference task-a, got %v", ids)
+				}
+			},
+		},
+		{
+			name: "delete after an add staged after another delete",
+			setupNodes: []*types.Node{
+				{ID: "task-x", Name: "Task X", CreatedAt: now, UpdatedAt: now},
+				{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				// The first delete indexes the staged edges, which must then
+				// include task-a so the second delete cleans it up
+				if err := tx.DeleteNode("task-x"); err != nil {
+					return err
+				}
+				if err := tx.AddNode(&types.Node{
+					ID:        "task-a",
+					Name:      "Task A",
+					EdgeIDs:   map[string][]string{"prerequisites": {"task-b"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				}); err != nil {
+					return err
+				}
+				return tx.DeleteNode("task-b")
+			},
+			expectedCount: 1,
+			validateGraph: func(t *testing.T, manager *Manager) {
+				if ids := manager.nodes["task-a"].GetEdgeIDs("prerequisites"); len(ids) != 0 {
+					t.Errorf("task-a should no longer reference task-b, got %v", ids)
+				}
+			},
+		},
+		{
+			name: "final graph with cycle is rejected",
+			setupNodes: []*types.Node{
+				{ID: "task-a", Name: "Task A", EdgeIDs: map[string][]string{"prerequisites": {"task-b"}}, CreatedAt: now, UpdatedAt: now},
+				{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				return tx.UpdateNode(&types.Node{
+					ID:        "task-b",
+					Name:      "Task B",
+					EdgeIDs:   map[string][]string{"prerequisites": {"task-a"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+			},
+			wantError:     true,
+			expectedError: "would introduce cycle",
+			expectedCount: 2,
+		},
+		{
+			name:                   "unresolved forward reference is rejected",
+			allowForwardReferences: true,
+			stage: func(tx *Transaction) error {
+				return tx.AddNode(&types.Node{
+					ID:        "task-a",
+					Name:      "Task A",
+					EdgeIDs:   map[string][]string{"prerequisites": {"missing"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+			},
+			wantError:     true,
+			expectedError: "references to missing nodes: task-a -> prerequisites: missing",
+			expectedCount: 0,
+		},
+		{
+			name: "unregistered relationship is rejected",
+			setupNodes: []*types.Node{
+				{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				return tx.AddNode(&types.Node{
+					ID:        "task-b",
+					Name:      "Task B",
+					EdgeIDs:   map[string][]string{"validates": {"task-a"}},
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+			},
+			wantError:     true,
+			expectedError: "unregistered relationship validates",
+			expectedCount: 1,
+		},
+		{
+			name: "failed operation rolls back earlier operations",
+			setupNodes: []*types.Node{
+				{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now},
+			},
+			stage: func(tx *Transaction) error {
+				if err := tx.AddNode(&types.Node{ID: "task-b", Name: "Task B", CreatedAt: now, UpdatedAt: now}); err != nil {
+					return err
+				}
+				if err := tx.DeleteNode("task-a"); err != nil {
+					return err
+				}
+				return tx.UpdateNode(&types.Node{ID: "missing", Name: "Missing", CreatedAt: now, UpdatedAt: now})
+			},
+			wantError:     true,
+			expectedError: "node with ID missing not found",
+			expectedCount: 1,
+			validateGraph: func(t *testing.T, manager *Manager) {
+				if _, exists := manager.nodes["task-a"]; !exists {
+					t.Error("task-a should not have been deleted")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, transactionRelationships...)
+			manager.SetAllowForwardReferences(tt.allowForwardReferences)
+			for _, node := range tt.setupNodes {
+				manager.nodes[node.ID] = node
+			}
+
+			tx := manager.Begin()
+			if err := tt.stage(tx); err != nil {
+				t.Fatalf("Failed to stage operations: %v", err)
+			}
+
+			err := tx.Commit()
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if len(manager.nodes) != tt.expectedCount {
+				t.Errorf("Expected %d nodes, got %d", tt.expectedCount, len(manager.nodes))
+			}
+
+			if tt.validateGraph != nil {
+				tt.validateGraph(t, manager)
+			}
+		})
+	}
+}
+
+func TestTransactionCommitPublishesOnlyChangedNodes(t *testing.T) {
+	manager := newTestManager(t, transactionRelationships...)
+	for _, node := range []*types.Node{
+		{ID: "root", Name: "Root"},
+		{ID: "child", Name: "Child", EdgeIDs: map[string][]string{"prerequisites": {"root"}}},
+		{ID: "other", Name: "Other", Tags: []string{"untouched"}},
+	} {
+		if err := manager.AddNode(node); err != nil {
+			t.Fatalf("Failed to add node %s: %v", node.ID, err)
+		}
+	}
+	heldChild, _ := manager.GetNode("child")
+	heldOther, _ := manager.GetNode("other")
+
+	tx := manager.Begin()
+	if err := tx.UpdateNode(&types.Node{ID: "root", Name: "Root", Summary: "Updated"}); err != nil {
+		t.Fatalf("Failed to stage update: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	if other, _ := manager.GetNode("other"); other != heldOther {
+		t.Error("Nodes the transaction didn't reach should not be replaced")
+	}
+	if tagged, _ := manager.GetNodesByTag("untouched"); len(tagged) != 1 || tagged[0] != heldOther {
+		t.Error("Tag cache should keep the untouched node")
+	}
+	if edges := heldChild.GetEdges("prerequisites"); len(edges) != 1 || edges[0].To.Summary != "" {
+		t.Error("Published nodes should not be modified by a commit")
+	}
+
+	child, _ := manager.GetNode("child")
+	root, _ := manager.GetNode("root")
+	if child == heldChild {
+		t.Error("Nodes pointing at an updated node should be replaced")
+	}
+	if edges := child.GetEdges("prerequisites"); len(edges) != 1 || edges[0].To != root {
+		t.Error("child should point at the committed root")
+	}
+	if dependents, _ := manager.GetDependents("root"); len(dependents["prerequisites"]) != 1 {
+		t.Errorf("Expected child as the dependent of root, got %v", dependents)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	manager := newTestManager(t, transactionRelationships...)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tx := manager.Begin()
+	if err := tx.AddNode(&types.Node{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to stage add: %v", err)
+	}
+	tx.Rollback()
+
+	if len(manager.nodes) != 0 {
+		t.Errorf("Expected no nodes after rollback, got %d", len(manager.nodes))
+	}
+	if err := tx.Commit(); err != ErrTransactionClosed {
+		t.Errorf("Expected ErrTransactionClosed after rollback, got %v", err)
+	}
+	if err := tx.AddNode(&types.Node{ID: "task-b"}); err != ErrTransactionClosed {
+		t.Errorf("Expected ErrTransactionClosed when staging after rollback, got %v", err)
+	}
+}
+
+func TestTransactionStagingValidation(t *testing.T) {
+	manager := newTestManager(t, transactionRelationships...)
+	tx := manager.Begin()
+
+	if err := tx.AddNode(nil); err == nil {
+		t.Error("Expected error when staging nil node")
+	}
+	if err := tx.UpdateNode(&types.Node{}); err == nil {
+		t.Error("Expected error when staging node with empty ID")
+	}
+	if err := tx.DeleteNode(""); err == nil {
+		t.Error("Expected error when staging delete with empty ID")
+	}
+	if len(tx.Operations()) != 0 {
+		t.Errorf("Expected no staged operations, got %d", len(tx.Operations()))
+	}
+}
+
+func TestTransactionRejectsDanglingReferencesWhenStaging(t *testing.T) {
+	manager := newTestManager(t, transactionRelationships...)
+	now := time.Now().UTC().Truncate(time.Second)
+	manager.nodes["task-a"] = &types.Node{ID: "task-a", Name: "Task A", CreatedAt: now, UpdatedAt: now}
+
+	tx := manager.Begin()
+
+	// A reference to a node that doesn't exist yet is rejected immediately
+	err := tx.AddNode(&types.Node{
+		ID:      "task-b",
+		Name:    "Task B",
+		EdgeIDs: map[string][]string{"prerequisites": {"task-c"}},
+	})
+	var dangling *DanglingReferenceError
+	if !errors.As(err, &dangling) {
+		t.Fatalf("Expected DanglingReferenceError, got %v", err)
+	}
+	if ids := dangling.MissingIDs(); len(ids) != 1 || ids[0] != "task-c" {
+		t.Errorf("Expected missing IDs [task-c], got %v", ids)
+	}
+
+	// Nodes added earlier in the transaction can be referenced
+	if err := tx.AddNode(&types.Node{ID: "task-c", Name: "Task C"}); err != nil {
+		t.Fatalf("Failed to stage add: %v", err)
+	}
+	if err := tx.AddNode(&types.Node{
+		ID:      "task-b",
+		Name:    "Task B",
+		EdgeIDs: map[string][]string{"prerequisites": {"task-a", "task-c"}},
+	}); err != nil {
+		t.Errorf("Expected reference to staged node to be accepted, got %v", err)
+	}
+
+	// Nodes deleted earlier in the transaction can't
+	if err := tx.DeleteNode("task-a"); err != nil {
+		t.Fatalf("Failed to stage delete: %v", err)
+	}
+	if err := tx.AddNode(&types.Node{
+		ID:      "task-d",
+		Name:    "Task D",
+		EdgeIDs: map[string][]string{"related_to": {"task-a"}},
+	}); !errors.As(err, &dangling) {
+		t.Errorf("Expected DanglingReferenceError for deleted node, got %v", err)
+	}
+
+	if len(tx.Operations()) != 3 {
+		t.Errorf("Expected 3 staged operations, got %d", len(tx.Operations()))
+	}
+}
@@ -0,0 +1,122 @@
+package graph_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+
+	"go.uber.org/zap"
+)
+
+// TraversalResult is a node reached by a traversal and the number of edges
+// followed to reach it by the shortest path
+type TraversalResult struct {
+	Node  *types.Node
+	Depth int
+}
+
+// Descendants returns every node reachable from the given node by following
+// edges of the relationship, nearest first. A maxDepth of zero or less means no
+// limit. Each node is reported once, at the depth it was first reached, so the
+// traversal terminates even if the relationship contains a cycle.
+// Pass WithFacts to follow only the edges whose conditions hold.
+func (m *Manager) Descendants(id, relationshipName string, maxDepth int, opts ...QueryOption) ([]TraversalResult, error) {
+	options := newQueryOptions(opts)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	start, exists := m.nodes[id]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	// Targets are looked up rather than followed through resolved edges, whose
+	// own edges may be older than the current nodes
+	results := m.traverse(start, maxDepth, func(node *types.Node) []*types.Node {
+		targetIDs := node.GetEdgeIDs(relationshipName)
+		targets := make([]*types.Node, 0, len(targetIDs))
+		for _, targetID := range targetIDs {
+			if target, exists := m.nodes[targetID]; exists && options.follows(node, relationshipName, target, target) {
+				targets = append(targets, target)
+			}
+		}
+		return targets
+	})
+
+	m.logger.Debug("Found descendants",
+		zap.String("node_id", id),
+		zap.String("relationship", relationshipName),
+		zap.Int("count", len(results)),
+	)
+
+	return results, nil
+}
+
+// Ancestors returns every node from which the given node can be reached by
+// following edges of the relationship, nearest first. A maxDepth of zero or less
+// means no limit. Each node is reported once, at the depth it was first reached.
+// Pass WithFacts to follow only the edges whose conditions hold.
+func (m *Manager) Ancestors(id, relationshipName string, maxDepth int, opts ...QueryOption) ([]TraversalResult, error) {
+	options := newQueryOptions(opts)
+	m.ensureIncomingIndex()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	start, exists := m.nodes[id]
+	if !exists {
+		return nil, fmt.Errorf("node with ID %s not found", id)
+	}
+
+	results := m.traverse(start, maxDepth, func(node *types.Node) []*types.Node {
+		sourceIDs := m.incoming[node.ID][relationshipName]
+		sources := make([]*types.Node, 0, len(sourceIDs))
+		for _, sourceID := range sourceIDs {
+			if source, exists := m.nodes[sourceID]; exists && options.follows(source, relationshipName, node, source) {
+				sources = append(sources, source)
+			}
+		}
+		return sources
+	})
+
+	m.logger.Debug("Found ancestors",
+		zap.String("node_id", id),
+		zap.String("relationship", relationshipName),
+		zap.Int("count", len(results)),
+	)
+
+	return results, nil
+}
+
+// traverse walks the graph breadth first from start, following the neighbours
+// returned by next, and returns the nodes reached in order of depth and then ID.
+// The start node itself is not included.
+// The caller must hold the lock.
+func (m *Manager) traverse(start *types.Node, maxDepth int, next func(node *types.Node) []*types.Node) []TraversalResult {
+	visited := map[string]bool{start.ID: true}
+	frontier := []*types.Node{start}
+	var results []TraversalResult
+
+	for depth := 1; len(frontier) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		var reached []*types.Node
+		for _, node := range frontier {
+			for _, neighbour := range next(node) {
+				if neighbour == nil || visited[neighbour.ID] {
+					continue
+				}
+				visited[neighbour.ID] = true
+				reached = append(reached, neighbour)
+			}
+		}
+
+		sort.Slice(reached, func(i, j int) bool { return reached[i].ID < reached[j].ID })
+		for _, node := range reached {
+			results = append(results, TraversalResult{Node: node, Depth: depth})
+		}
+		frontier = reached
+	}
+
+	return results
+}
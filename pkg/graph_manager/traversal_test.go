@@ -0,0 +1,103 @@
+package graph_manager
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"common-tasks-mcp/pkg/graph_manager/types"
+	"common-tasks-mcp/pkg/logger"
+)
+
+// traversalTestNodes builds a diamond of prerequisites:
+// deploy -> build, test; build -> checkout; test -> checkout
+func traversalTestNodes() []*types.Node {
+	return []*types.Node{
+		{ID: "checkout", Name: "Checkout"},
+		{ID: "build", Name: "Build", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}}},
+		{ID: "test", Name: "Test", EdgeIDs: map[string][]string{"prerequisites": {"checkout"}, "related": {"build"}}},
+		{ID: "deploy", Name: "Deploy", EdgeIDs: map[string][]string{"prerequisites": {"test", "build"}}},
+	}
+}
+
+// traversalIDs flattens traversal results to "id@depth" strings
+func traversalIDs(results []TraversalResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = fmt.Sprintf("%s@%d", result.Node.ID, result.Depth)
+	}
+	return ids
+}
+
+func TestDescendants(t *testing.T) {
+	manager := newTestManager(t)
+	addTestNodes(t, manager, traversalTestNodes()...)
+
+	tests := []struct {
+		name     string
+		id       string
+		maxDepth int
+		expected []string
+	}{
+		{"unlimited", "deploy", 0, []string{"build@1", "test@1", "checkout@2"}},
+		{"depth limit", "deploy", 1, []string{"build@1", "test@1"}},
+		{"leaf", "checkout", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := manager.Descendants(tt.id, "prerequisites", tt.maxDepth)
+			if err != nil {
+				t.Fatalf("Descendants failed: %v", err)
+			}
+			if got := traversalIDs(results); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := manager.Descendants("missing", "prerequisites", 0); err == nil {
+		t.Error("Expected error for missing node")
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	manager := newTestManager(t)
+	addTestNodes(t, manager, traversalTestNodes()...)
+
+	results, err := manager.Ancestors("checkout", "prerequisites", 0)
+	if err != nil {
+		t.Fatalf("Ancestors failed: %v", err)
+	}
+	if got, expected := traversalIDs(results), []string{"build@1", "test@1", "deploy@2"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// Ancestors reflect later changes to the graph
+	if err := manager.DeleteNode("test"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	results, _ = manager.Ancestors("checkout", "prerequisites", 1)
+	if got, expected := traversalIDs(results), []string{"build@1"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestTraversalIsCycleSafe(t *testing.T) {
+	log, _ := logger.New(false)
+	manager := NewManager(log)
+
+	// Seed a cycle directly, as loading a broken file could
+	manager.nodes["task-a"] = &types.Node{ID: "task-a", EdgeIDs: map[string][]string{"related": {"task-b"}}}
+	manager.nodes["task-b"] = &types.Node{ID: "task-b", EdgeIDs: map[string][]string{"related": {"task-a"}}}
+	if err := manager.ResolveNodePointers(); err != nil {
+		t.Fatalf("Failed to resolve pointers: %v", err)
+	}
+
+	results, err := manager.Descendants("task-a", "related", 0)
+	if err != nil {
+		t.Fatalf("Descendants failed: %v", err)
+	}
+	if got, expected := traversalIDs(results), []string{"task-b@1"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
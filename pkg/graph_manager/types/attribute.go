@@ -0,0 +1,172 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// AttributeType is the type of value an attribute holds
+type AttributeType string
+
+const (
+	// AttributeString holds text, optionally restricted by a pattern
+	AttributeString AttributeType = "string"
+
+	// AttributeInteger holds a whole number
+	AttributeInteger AttributeType = "integer"
+
+	// AttributeNumber holds any number
+	AttributeNumber AttributeType = "number"
+
+	// AttributeBoolean holds true or false
+	AttributeBoolean AttributeType = "boolean"
+)
+
+// AttributeDefinition declares an attribute nodes may carry, such as a
+// preparation time or an owner, and the values it accepts
+type AttributeDefinition struct {
+	// Name is the key of the attribute in the Attributes of the nodes
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the type of the values: "string", "integer", "number" or "boolean"
+	Type AttributeType `json:"type" yaml:"type"`
+
+	// Description explains the attribute in human-readable form
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Required rejects nodes without the attribute
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Enum lists the allowed values. Empty allows any value of the type.
+	Enum []any `json:"enum,omitempty" yaml:"enum,omitempty"`
+
+	// Pattern is a regular expression string values must match
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	// Default is set on nodes added without the attribute
+	Default any `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// Validate checks if the attribute definition is valid
+func (d AttributeDefinition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("attribute name is required")
+	}
+
+	switch d.Type {
+	case AttributeString, AttributeInteger, AttributeNumber, AttributeBoolean:
+		// Valid
+	default:
+		return fmt.Errorf("invalid type: %s", d.Type)
+	}
+
+	if d.Pattern != "" {
+		if d.Type != AttributeString {
+			return fmt.Errorf("pattern is only allowed on string attributes")
+		}
+		if _, err := regexp.Compile(d.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	for _, value := range d.Enum {
+		if err := d.checkType(value); err != nil {
+			return fmt.Errorf("invalid enum value: %w", err)
+		}
+	}
+	if d.Default != nil {
+		if err := d.Check(d.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+// Check returns an error if the value is not allowed for the attribute
+func (d AttributeDefinition) Check(value any) error {
+	if err := d.checkType(value); err != nil {
+		return err
+	}
+
+	if len(d.Enum) > 0 {
+		allowed := false
+		for _, enumValue := range d.Enum {
+			if AttributeValuesEqual(value, enumValue) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%v is not one of %v", value, d.Enum)
+		}
+	}
+
+	if d.Pattern != "" {
+		// Validate has checked the pattern compiles
+		if !regexp.MustCompile(d.Pattern).MatchString(value.(string)) {
+			return fmt.Errorf("%q does not match %s", value, d.Pattern)
+		}
+	}
+	return nil
+}
+
+// checkType returns an error if the value is not of the attribute's type
+func (d AttributeDefinition) checkType(value any) error {
+	switch d.Type {
+	case AttributeString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case AttributeInteger:
+		// JSON decodes every number as a float64
+		if number, ok := attributeNumber(value); ok && number == float64(int64(number)) {
+			return nil
+		}
+	case AttributeNumber:
+		if _, ok := attributeNumber(value); ok {
+			return nil
+		}
+	case AttributeBoolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not of type %s", value, d.Type)
+}
+
+// AttributeValuesEqual reports whether two attribute values are equal. Numbers
+// are compared by value, so the int read from YAML equals the float64 read from JSON.
+func AttributeValuesEqual(a, b any) bool {
+	aNumber, aIsNumber := attributeNumber(a)
+	bNumber, bIsNumber := attributeNumber(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && aNumber == bNumber
+	}
+	return a == b
+}
+
+// FormatAttributeValue formats an attribute value for display, writing numbers
+// the same way whichever type they were decoded as
+func FormatAttributeValue(value any) string {
+	if number, ok := attributeNumber(value); ok {
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// attributeNumber returns the value as a float64 if it is a number
+func attributeNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
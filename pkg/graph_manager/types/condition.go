@@ -0,0 +1,502 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Facts is the context conditions are evaluated against: key/value facts about
+// the situation, such as {"environment": "prod", "changed_paths": ["db/schema.sql"]}.
+// Values are strings, numbers, booleans or lists of them.
+type Facts map[string]any
+
+// Condition is a parsed condition expression, such as
+//
+//	environment == "prod" && (changed_paths contains "db/" || force)
+//
+// Operands are fact names, double-quoted strings, numbers, true, false and
+// lists like ["go", "rust"]. The operators are:
+//
+//   - == and != compare values; numbers compare by value
+//   - <, <=, > and >= compare numbers
+//   - a contains b is true if the string a, or one of the strings in the list a, contains b
+//   - a in b is true if a equals one of the values in the list b, or is part of the string b
+//   - &&, || and ! combine conditions, and parentheses group them
+//
+// A fact on its own holds if it is true, a non-empty string or list, or a
+// non-zero number. A fact that isn't set makes every comparison with it false.
+type Condition struct {
+	source string
+	root   conditionExpr
+}
+
+// ParseCondition parses a condition expression
+func ParseCondition(source string) (*Condition, error) {
+	tokens, err := tokenizeCondition(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %s", p.peek())
+	}
+	return &Condition{source: source, root: root}, nil
+}
+
+// String returns the expression the condition was parsed from
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Evaluate reports whether the condition holds for the facts, and explains the
+// result with the values of the facts it refers to, e.g. `environment is "staging"`
+func (c *Condition) Evaluate(facts Facts) (bool, string) {
+	holds := truthy(c.root.eval(facts))
+
+	names := make(map[string]bool)
+	c.root.facts(names)
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	explanations := make([]string, len(sorted))
+	for i, name := range sorted {
+		if value, exists := facts[name]; exists {
+			explanations[i] = fmt.Sprintf("%s is %s", name, formatConditionValue(value))
+		} else {
+			explanations[i] = fmt.Sprintf("%s is not set", name)
+		}
+	}
+	return holds, strings.Join(explanations, ", ")
+}
+
+// EvaluateCondition parses and evaluates a condition, as Evaluate does. An
+// empty condition always holds, and an invalid one never does.
+func EvaluateCondition(source string, facts Facts) (bool, string) {
+	if source == "" {
+		return true, ""
+	}
+	condition, err := ParseCondition(source)
+	if err != nil {
+		return false, fmt.Sprintf("invalid condition: %v", err)
+	}
+	return condition.Evaluate(facts)
+}
+
+// conditionExpr is a node of a parsed condition
+type conditionExpr interface {
+	// eval returns the value of the expression, nil for a fact that isn't set
+	eval(facts Facts) any
+
+	// facts adds the names of the facts the expression refers to
+	facts(names map[string]bool)
+}
+
+// conditionLiteral is a string, number, boolean or list written in the condition
+type conditionLiteral struct{ value any }
+
+func (e conditionLiteral) eval(Facts) any              { return e.value }
+func (e conditionLiteral) facts(names map[string]bool) {}
+
+// conditionFact is a reference to a fact
+type conditionFact struct{ name string }
+
+func (e conditionFact) eval(facts Facts) any        { return facts[e.name] }
+func (e conditionFact) facts(names map[string]bool) { names[e.name] = true }
+
+// conditionList is a list of values written in the condition
+type conditionList struct{ items []conditionExpr }
+
+func (e conditionList) eval(facts Facts) any {
+	values := make([]any, len(e.items))
+	for i, item := range e.items {
+		values[i] = item.eval(facts)
+	}
+	return values
+}
+
+func (e conditionList) facts(names map[string]bool) {
+	for _, item := range e.items {
+		item.facts(names)
+	}
+}
+
+// conditionNot negates a condition
+type conditionNot struct{ operand conditionExpr }
+
+func (e conditionNot) eval(facts Facts) any        { return !truthy(e.operand.eval(facts)) }
+func (e conditionNot) facts(names map[string]bool) { e.operand.facts(names) }
+
+// conditionBinary is a comparison or a combination of two conditions
+type conditionBinary struct {
+	op          string
+	left, right conditionExpr
+}
+
+func (e conditionBinary) eval(facts Facts) any {
+	switch e.op {
+	case "&&":
+		return truthy(e.left.eval(facts)) && truthy(e.right.eval(facts))
+	case "||":
+		return truthy(e.left.eval(facts)) || truthy(e.right.eval(facts))
+	}
+
+	left, right := e.left.eval(facts), e.right.eval(facts)
+	if left == nil || right == nil {
+		return false
+	}
+	switch e.op {
+	case "==":
+		return conditionValuesEqual(left, right)
+	case "!=":
+		return !conditionValuesEqual(left, right)
+	case "<", "<=", ">", ">=":
+		leftNumber, leftOK := attributeNumber(left)
+		rightNumber, rightOK := attributeNumber(right)
+		if !leftOK || !rightOK {
+			return false
+		}
+		switch e.op {
+		case "<":
+			return leftNumber < rightNumber
+		case "<=":
+			return leftNumber <= rightNumber
+		case ">":
+			return leftNumber > rightNumber
+		default:
+			return leftNumber >= rightNumber
+		}
+	case "contains":
+		return conditionContains(left, right)
+	case "in":
+		if list, ok := conditionValues(right); ok {
+			for _, item := range list {
+				if conditionValuesEqual(left, item) {
+					return true
+				}
+			}
+			return false
+		}
+		return conditionContains(right, left)
+	}
+	return false
+}
+
+func (e conditionBinary) facts(names map[string]bool) {
+	e.left.facts(names)
+	e.right.facts(names)
+}
+
+// conditionContains reports whether the string, or one of the strings in the
+// list, contains the text
+func conditionContains(value, text any) bool {
+	substring, ok := text.(string)
+	if !ok {
+		return false
+	}
+	if s, ok := value.(string); ok {
+		return strings.Contains(s, substring)
+	}
+	list, _ := conditionValues(value)
+	for _, item := range list {
+		if s, ok := item.(string); ok && strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionValuesEqual compares two strings, numbers or booleans. Other
+// values, such as lists, are never equal.
+func conditionValuesEqual(a, b any) bool {
+	if !conditionScalar(a) || !conditionScalar(b) {
+		return false
+	}
+	return AttributeValuesEqual(a, b)
+}
+
+// conditionScalar reports whether a value is a string, number or boolean
+func conditionScalar(value any) bool {
+	if _, isNumber := attributeNumber(value); isNumber {
+		return true
+	}
+	switch value.(type) {
+	case string, bool:
+		return true
+	}
+	return false
+}
+
+// conditionValues returns the values of a list fact or literal
+func conditionValues(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case []string:
+		values := make([]any, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// truthy reports whether a value holds as a condition on its own
+func truthy(value any) bool {
+	if number, ok := attributeNumber(value); ok {
+		return number != 0
+	}
+	if list, ok := conditionValues(value); ok {
+		return len(list) > 0
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	return false
+}
+
+// formatConditionValue formats a fact value for explanations
+func formatConditionValue(value any) string {
+	if list, ok := conditionValues(value); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = formatConditionValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return FormatAttributeValue(value)
+}
+
+// conditionToken is a token of a condition expression
+type conditionToken struct {
+	kind  string // "op", "ident", "string" or "number"
+	text  string
+	value any
+}
+
+// String describes the token for error messages
+func (t conditionToken) String() string {
+	if t.kind == "end" {
+		return t.text
+	}
+	return strconv.Quote(t.text)
+}
+
+// tokenizeCondition splits a condition expression into tokens
+func tokenizeCondition(source string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(source) && source[end] != '"' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			value, err := strconv.Unquote(source[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", source[i:end+1])
+			}
+			tokens = append(tokens, conditionToken{kind: "string", text: source[i : end+1], value: value})
+			i = end + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(source) && (source[end] == '.' || (source[end] >= '0' && source[end] <= '9')) {
+				end++
+			}
+			value, err := strconv.ParseFloat(source[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", source[i:end])
+			}
+			tokens = append(tokens, conditionToken{kind: "number", text: source[i:end], value: value})
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(source) && (source[end] == '_' || source[end] == '.' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			word := source[i:end]
+			switch word {
+			case "contains", "in":
+				tokens = append(tokens, conditionToken{kind: "op", text: word})
+			default:
+				tokens = append(tokens, conditionToken{kind: "ident", text: word})
+			}
+			i = end
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, i+1)
+			}
+			tokens = append(tokens, conditionToken{kind: "op", text: op})
+			i += len(op)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	return tokens, nil
+}
+
+// conditionParser parses tokens into an expression by recursive descent
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *conditionParser) peek() conditionToken {
+	if p.done() {
+		return conditionToken{kind: "end", text: "end of condition"}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is the given operator
+func (p *conditionParser) accept(op string) bool {
+	if token := p.peek(); token.kind == "op" && token.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses conditions joined by ||
+func (p *conditionParser) parseOr() (conditionExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionBinary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses conditions joined by &&
+func (p *conditionParser) parseAnd() (conditionExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionBinary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses a negated condition, a parenthesized condition or a comparison
+func (p *conditionParser) parseNot() (conditionExpr, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return conditionNot{operand: operand}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected \")\", got %s", p.peek())
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses an operand, optionally compared with another
+func (p *conditionParser) parseComparison() (conditionExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "contains", "in"} {
+		if p.accept(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return conditionBinary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parseOperand parses a fact name, a literal or a list
+func (p *conditionParser) parseOperand() (conditionExpr, error) {
+	token := p.peek()
+	switch token.kind {
+	case "string", "number":
+		p.pos++
+		return conditionLiteral{value: token.value}, nil
+	case "ident":
+		p.pos++
+		switch token.text {
+		case "true":
+			return conditionLiteral{value: true}, nil
+		case "false":
+			return conditionLiteral{value: false}, nil
+		}
+		return conditionFact{name: token.text}, nil
+	}
+
+	if p.accept("[") {
+		var items []conditionExpr
+		for !p.accept("]") {
+			if len(items) > 0 && !p.accept(",") {
+				return nil, fmt.Errorf("expected \",\" or \"]\", got %s", p.peek())
+			}
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return conditionList{items: items}, nil
+	}
+	return nil, fmt.Errorf("expected a value, got %s", token)
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestConditionEvaluate(t *testing.T) {
+	facts := Facts{
+		"environment":   "prod",
+		"changed_paths": []any{"db/migrations/001.sql", "api/handler.go"},
+		"languages":     []string{"go", "sql"},
+		"replicas":      float64(3),
+		"force":         false,
+	}
+
+	tests := []struct {
+		condition string
+		holds     bool
+	}{
+		{`environment == "prod"`, true},
+		{`environment != "prod"`, false},
+		{`environment == "staging" || environment == "prod"`, true},
+		{`environment == "prod" && force`, false},
+		{`environment == "prod" && !force`, true},
+		{`changed_paths contains "db/"`, true},
+		{`changed_paths contains "web/"`, false},
+		{`environment contains "ro"`, true},
+		{`"go" in languages`, true},
+		{`"rust" in languages`, false},
+		{`environment in ["staging", "prod"]`, true},
+		{`"od" in environment`, true},
+		{`replicas >= 3 && replicas < 5`, true},
+		{`replicas > 3`, false},
+		{`replicas == 3`, true},
+		{`environment > 3`, false},
+		{`languages`, true},
+		{`force`, false},
+		{`!(environment == "prod" && force) && true`, true},
+		// Facts that aren't set make comparisons false, even !=
+		{`region == "eu"`, false},
+		{`region != "eu"`, false},
+		{`!(region == "eu")`, true},
+		{`region`, false},
+		// Lists never equal a value
+		{`languages == "go"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			condition, err := ParseCondition(tt.condition)
+			if err != nil {
+				t.Fatalf("Failed to parse condition: %v", err)
+			}
+			if holds, explanation := condition.Evaluate(facts); holds != tt.holds {
+				t.Errorf("Expected %v, got %v (%s)", tt.holds, holds, explanation)
+			}
+		})
+	}
+}
+
+func TestConditionExplanation(t *testing.T) {
+	holds, explanation := EvaluateCondition(`environment == "prod" && changed_paths contains "db/" && region == "eu"`, Facts{
+		"environment":   "staging",
+		"changed_paths": []string{"api/handler.go"},
+	})
+	if holds {
+		t.Error("Expected the condition not to hold")
+	}
+	expected := `changed_paths is ["api/handler.go"], environment is "staging", region is not set`
+	if explanation != expected {
+		t.Errorf("Expected explanation %q, got %q", expected, explanation)
+	}
+
+	if holds, explanation := EvaluateCondition("", nil); !holds || explanation != "" {
+		t.Errorf("An empty condition should always hold, got %v (%s)", holds, explanation)
+	}
+	if holds, explanation := EvaluateCondition(`environment ==`, Facts{}); holds || explanation == "" {
+		t.Errorf("An invalid condition should not hold, got %v (%s)", holds, explanation)
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for _, condition := range []string{
+		``,
+		`environment ==`,
+		`environment == "prod`,
+		`(environment == "prod"`,
+		`environment = "prod"`,
+		`environment == "prod" force`,
+		`"go" in ["go" "rust"]`,
+		`&& force`,
+	} {
+		if _, err := ParseCondition(condition); err == nil {
+			t.Errorf("Expected an error parsing %q", condition)
+		}
+	}
+}
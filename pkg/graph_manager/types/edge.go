@@ -9,6 +9,9 @@ type Edge struct {
 
 	// Category is the relationship type (e.g., "prerequisites", "downstream_required", "validates")
 	Type *Relationship `json:"type" yaml:"type"`
+
+	// Attributes are the note, condition and weight of the edge, if any
+	Attributes EdgeAttributes `json:"attributes" yaml:"attributes"`
 }
 
 // NewEdge creates a new edge with the given parameters
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EdgeAttributes are optional details of an edge: why it exists, when it
+// applies and how much it matters. The zero value means none.
+type EdgeAttributes struct {
+	// Note explains the edge, e.g. why a prerequisite is needed
+	Note string `json:"note,omitempty" yaml:"note,omitempty"`
+
+	// Condition is an expression describing when the edge applies,
+	// e.g. `changed_paths contains "db/"`
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+
+	// Weight ranks the edge among the other targets of its relationship,
+	// such as a priority. Zero means unweighted.
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// IsZero reports whether no attribute is set
+func (a EdgeAttributes) IsZero() bool {
+	return a == EdgeAttributes{}
+}
+
+// EdgeTarget is one entry of a node's edges as written in YAML and JSON: the
+// target ID alone, or an object with the ID and the attributes of the edge.
+//
+//	prerequisites:
+//	  - build
+//	  - id: migrate
+//	    note: Schema changes need the new columns
+//	    condition: changed_paths contains "db/"
+type EdgeTarget struct {
+	ID             string `json:"id" yaml:"id"`
+	EdgeAttributes `yaml:",inline"`
+}
+
+// edgeTargetObject is the object form of an EdgeTarget, without its methods
+type edgeTargetObject EdgeTarget
+
+// MarshalYAML writes the target as a bare ID when it has no attributes
+func (t EdgeTarget) MarshalYAML() (interface{}, error) {
+	if t.EdgeAttributes.IsZero() {
+		return t.ID, nil
+	}
+	return edgeTargetObject(t), nil
+}
+
+// UnmarshalYAML reads a bare ID or the object form
+func (t *EdgeTarget) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = EdgeTarget{}
+		return value.Decode(&t.ID)
+	}
+	var object edgeTargetObject
+	if err := value.Decode(&object); err != nil {
+		return err
+	}
+	*t = EdgeTarget(object)
+	return nil
+}
+
+// MarshalJSON writes the target as a bare ID when it has no attributes
+func (t EdgeTarget) MarshalJSON() ([]byte, error) {
+	if t.EdgeAttributes.IsZero() {
+		return json.Marshal(t.ID)
+	}
+	return json.Marshal(edgeTargetObject(t))
+}
+
+// UnmarshalJSON reads a bare ID or the object form
+func (t *EdgeTarget) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*t = EdgeTarget{ID: id}
+		return nil
+	}
+	var object edgeTargetObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*t = EdgeTarget(object)
+	return nil
+}
+
+// SplitEdgeTargets separates edges given as targets into the target IDs and
+// the attributes of the edges that have any, both keyed by relationship name
+func SplitEdgeTargets(edges map[string][]EdgeTarget) (map[string][]string, map[string]map[string]EdgeAttributes) {
+	if edges == nil {
+		return nil, nil
+	}
+
+	edgeIDs := make(map[string][]string, len(edges))
+	var attributes map[string]map[string]EdgeAttributes
+	for relationshipName, targets := range edges {
+		ids := make([]string, len(targets))
+		for i, target := range targets {
+			ids[i] = target.ID
+			if target.EdgeAttributes.IsZero() {
+				continue
+			}
+			if attributes == nil {
+				attributes = make(map[string]map[string]EdgeAttributes)
+			}
+			if attributes[relationshipName] == nil {
+				attributes[relationshipName] = make(map[string]EdgeAttributes)
+			}
+			attributes[relationshipName][target.ID] = target.EdgeAttributes
+		}
+		edgeIDs[relationshipName] = ids
+	}
+	return edgeIDs, attributes
+}
@@ -1,22 +1,51 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"sort"
 	"time"
 )
 
 // Node represents a node in the task graph with configurable DAG relationships
 type Node struct {
-	ID          string   `json:"id" yaml:"id"`
-	Name        string   `json:"name" yaml:"name"`
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+
+	// Kind names the kind of node, such as "service" or "runbook", in graphs
+	// that mix several. Empty for graphs with a single kind of node.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Status is the lifecycle state of the node. Empty means active.
+	Status NodeStatus `json:"status,omitempty" yaml:"status,omitempty"`
+
+	// SupersededBy is the ID of the node replacing this one when its status
+	// is superseded
+	SupersededBy string `json:"superseded_by,omitempty" yaml:"superseded_by,omitempty"`
+
 	Summary     string   `json:"summary" yaml:"summary"`
 	Description string   `json:"description" yaml:"description"`
 	Tags        []string `json:"tags" yaml:"tags"`
 
+	// Condition is an expression over the request context describing when the
+	// node applies, such as `environment == "prod"`. Empty means always; see Condition.
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+
 	// EdgeIDs maps relationship names to lists of target node IDs (persisted to YAML)
 	// Example: {"prerequisites": ["task-a", "task-b"], "downstream_required": ["task-c"]}
 	EdgeIDs map[string][]string `json:"edges" yaml:"edges"`
 
+	// EdgeAttributes maps relationship names to target node IDs to the
+	// attributes of those edges. Edges without attributes have no entry.
+	// Persisted inside edges; see EdgeTarget.
+	EdgeAttributes map[string]map[string]EdgeAttributes `json:"-" yaml:"-"`
+
+	// Attributes holds typed values declared in the attribute definitions,
+	// such as {"prep_time": 10, "owner": "platform-team"}
+	Attributes map[string]any `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+
 	// Edges maps relationship names to lists of resolved edges (runtime only, not persisted)
 	// The edges are populated by the Manager after loading from disk
 	Edges map[string][]Edge `json:"-" yaml:"-"`
@@ -39,6 +68,10 @@ func (n *Node) Equals(other *Node) bool {
 	// Compare scalar fields
 	if n.ID != other.ID ||
 		n.Name != other.Name ||
+		n.Kind != other.Kind ||
+		n.Status != other.Status ||
+		n.SupersededBy != other.SupersededBy ||
+		n.Condition != other.Condition ||
 		n.Summary != other.Summary ||
 		n.Description != other.Description {
 		return false
@@ -78,6 +111,26 @@ func (n *Node) Equals(other *Node) bool {
 		}
 	}
 
+	// Compare the attributes of the edges
+	for relationshipName, ids := range n.EdgeIDs {
+		for _, id := range ids {
+			if n.GetEdgeAttributes(relationshipName, id) != other.GetEdgeAttributes(relationshipName, id) {
+				return false
+			}
+		}
+	}
+
+	// Compare Attributes map
+	if len(n.Attributes) != len(other.Attributes) {
+		return false
+	}
+	for name, value := range n.Attributes {
+		otherValue, exists := other.Attributes[name]
+		if !exists || !AttributeValuesEqual(value, otherValue) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -90,12 +143,16 @@ func (n *Node) Clone() *Node {
 
 	// Clone the node with all scalar fields
 	clone := &Node{
-		ID:          n.ID,
-		Name:        n.Name,
-		Summary:     n.Summary,
-		Description: n.Description,
-		CreatedAt:   n.CreatedAt,
-		UpdatedAt:   n.UpdatedAt,
+		ID:           n.ID,
+		Name:         n.Name,
+		Kind:         n.Kind,
+		Status:       n.Status,
+		SupersededBy: n.SupersededBy,
+		Condition:    n.Condition,
+		Summary:      n.Summary,
+		Description:  n.Description,
+		CreatedAt:    n.CreatedAt,
+		UpdatedAt:    n.UpdatedAt,
 	}
 
 	// Deep copy Tags slice
@@ -114,6 +171,23 @@ func (n *Node) Clone() *Node {
 		}
 	}
 
+	// Copy the attributes of the edges the node still has
+	for relationshipName, ids := range n.EdgeIDs {
+		for _, id := range ids {
+			if attributes := n.GetEdgeAttributes(relationshipName, id); !attributes.IsZero() {
+				clone.SetEdgeAttributes(relationshipName, id, attributes)
+			}
+		}
+	}
+
+	// Copy Attributes map; its values are scalars
+	if n.Attributes != nil {
+		clone.Attributes = make(map[string]any, len(n.Attributes))
+		for name, value := range n.Attributes {
+			clone.Attributes[name] = value
+		}
+	}
+
 	// Note: Edges map (resolved pointers) is intentionally left as nil
 	// and will be resolved by Manager.ResolveEdges()
 
@@ -151,7 +225,8 @@ func (n *Node) SetEdgeIDs(relationshipName string, ids []string) error {
 	return nil
 }
 
-// AddEdgeID adds a single ID to a specific relationship.
+// AddEdgeID adds a single ID to a specific relationship. Adding an ID the
+// relationship already lists is a no-op.
 // Returns an error if the receiver is nil.
 func (n *Node) AddEdgeID(relationshipName string, id string) error {
 	if n == nil {
@@ -160,10 +235,48 @@ func (n *Node) AddEdgeID(relationshipName string, id string) error {
 	if n.EdgeIDs == nil {
 		n.EdgeIDs = make(map[string][]string)
 	}
+	for _, existing := range n.EdgeIDs[relationshipName] {
+		if existing == id {
+			return nil
+		}
+	}
 	n.EdgeIDs[relationshipName] = append(n.EdgeIDs[relationshipName], id)
 	return nil
 }
 
+// GetEdgeAttributes returns the attributes of the edge to the target through
+// the relationship, or the zero value if it has none
+func (n *Node) GetEdgeAttributes(relationshipName, id string) EdgeAttributes {
+	if n == nil {
+		return EdgeAttributes{}
+	}
+	return n.EdgeAttributes[relationshipName][id]
+}
+
+// SetEdgeAttributes sets the attributes of the edge to the target through the
+// relationship. Zero attributes remove them.
+// Returns an error if the receiver is nil.
+func (n *Node) SetEdgeAttributes(relationshipName, id string, attributes EdgeAttributes) error {
+	if n == nil {
+		return fmt.Errorf("cannot set edge attributes on nil node")
+	}
+	if attributes.IsZero() {
+		delete(n.EdgeAttributes[relationshipName], id)
+		if len(n.EdgeAttributes[relationshipName]) == 0 {
+			delete(n.EdgeAttributes, relationshipName)
+		}
+		return nil
+	}
+	if n.EdgeAttributes == nil {
+		n.EdgeAttributes = make(map[string]map[string]EdgeAttributes)
+	}
+	if n.EdgeAttributes[relationshipName] == nil {
+		n.EdgeAttributes[relationshipName] = make(map[string]EdgeAttributes)
+	}
+	n.EdgeAttributes[relationshipName][id] = attributes
+	return nil
+}
+
 // SetEdges sets the resolved edges for a specific relationship, replacing any existing edges.
 // Also updates the corresponding EdgeIDs map.
 // Returns an error if the receiver is nil or if any edge has a nil To node.
@@ -216,3 +329,101 @@ func (n *Node) AddEdge(relationshipName string, edge Edge) error {
 	n.EdgeIDs[relationshipName] = append(n.EdgeIDs[relationshipName], edge.To.ID)
 	return nil
 }
+
+// Revision returns a short hash of the node's persisted fields. It changes
+// whenever the node's content changes, so it can be used to detect concurrent
+// modifications. Nil and empty tag and edge lists hash the same, and timestamps
+// are compared in UTC, so a node keeps its revision when it is saved and reloaded.
+func (n *Node) Revision() string {
+	if n == nil {
+		return ""
+	}
+
+	h := sha256.New()
+	writeRevisionField(h, n.ID)
+	writeRevisionField(h, n.Name)
+	writeRevisionField(h, n.Summary)
+	writeRevisionField(h, n.Description)
+	writeRevisionField(h, n.CreatedAt.UTC().Format(time.RFC3339Nano))
+	writeRevisionField(h, n.UpdatedAt.UTC().Format(time.RFC3339Nano))
+
+	fmt.Fprintf(h, "tags:%d;", len(n.Tags))
+	for _, tag := range n.Tags {
+		writeRevisionField(h, tag)
+	}
+
+	relationshipNames := make([]string, 0, len(n.EdgeIDs))
+	for relationshipName, ids := range n.EdgeIDs {
+		if len(ids) > 0 {
+			relationshipNames = append(relationshipNames, relationshipName)
+		}
+	}
+	sort.Strings(relationshipNames)
+	fmt.Fprintf(h, "edges:%d;", len(relationshipNames))
+	for _, relationshipName := range relationshipNames {
+		writeRevisionField(h, relationshipName)
+		fmt.Fprintf(h, "%d;", len(n.EdgeIDs[relationshipName]))
+		for _, id := range n.EdgeIDs[relationshipName] {
+			writeRevisionField(h, id)
+		}
+	}
+
+	// Only written when set, so nodes without a kind keep their revisions
+	if n.Kind != "" {
+		fmt.Fprint(h, "kind;")
+		writeRevisionField(h, n.Kind)
+	}
+	if n.Condition != "" {
+		fmt.Fprint(h, "condition;")
+		writeRevisionField(h, n.Condition)
+	}
+	if n.Status != "" {
+		fmt.Fprint(h, "status;")
+		writeRevisionField(h, string(n.Status))
+	}
+	if n.SupersededBy != "" {
+		fmt.Fprint(h, "superseded_by;")
+		writeRevisionField(h, n.SupersededBy)
+	}
+
+	// Likewise for attributes. Numbers hash the same whichever type they were
+	// decoded as, but differently from the same text in a string.
+	if len(n.Attributes) > 0 {
+		names := make([]string, 0, len(n.Attributes))
+		for name := range n.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(h, "attributes:%d;", len(names))
+		for _, name := range names {
+			value := n.Attributes[name]
+			writeRevisionField(h, name)
+			if _, isString := value.(string); isString {
+				fmt.Fprint(h, "s")
+			}
+			writeRevisionField(h, FormatAttributeValue(value))
+		}
+	}
+	// And for the attributes of edges
+	for _, relationshipName := range relationshipNames {
+		for _, id := range n.EdgeIDs[relationshipName] {
+			attributes := n.GetEdgeAttributes(relationshipName, id)
+			if attributes.IsZero() {
+				continue
+			}
+			fmt.Fprint(h, "edge_attributes;")
+			writeRevisionField(h, relationshipName)
+			writeRevisionField(h, id)
+			writeRevisionField(h, attributes.Note)
+			writeRevisionField(h, attributes.Condition)
+			writeRevisionField(h, FormatAttributeValue(attributes.Weight))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// writeRevisionField writes a length-prefixed value so adjacent fields can't run together
+func writeRevisionField(h hash.Hash, value string) {
+	fmt.Fprintf(h, "%d:%s;", len(value), value)
+}
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// persistedNode is the form of a node in YAML and JSON. Its edges hold the
+// attributes of each edge next to the target ID.
+type persistedNode struct {
+	ID           string                  `json:"id" yaml:"id"`
+	Name         string                  `json:"name" yaml:"name"`
+	Kind         string                  `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Status       NodeStatus              `json:"status,omitempty" yaml:"status,omitempty"`
+	SupersededBy string                  `json:"superseded_by,omitempty" yaml:"superseded_by,omitempty"`
+	Summary      string                  `json:"summary" yaml:"summary"`
+	Description  string                  `json:"description" yaml:"description"`
+	Tags         []string                `json:"tags" yaml:"tags"`
+	Condition    string                  `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Edges        map[string][]EdgeTarget `json:"edges" yaml:"edges"`
+	Attributes   map[string]any          `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	CreatedAt    time.Time               `json:"created_at" yaml:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at" yaml:"updated_at"`
+}
+
+// persisted returns the persisted form of the node
+func (n Node) persisted() persistedNode {
+	var edges map[string][]EdgeTarget
+	if n.EdgeIDs != nil {
+		edges = make(map[string][]EdgeTarget, len(n.EdgeIDs))
+		for relationshipName, ids := range n.EdgeIDs {
+			targets := make([]EdgeTarget, len(ids))
+			for i, id := range ids {
+				targets[i] = EdgeTarget{ID: id, EdgeAttributes: n.GetEdgeAttributes(relationshipName, id)}
+			}
+			edges[relationshipName] = targets
+		}
+	}
+
+	return persistedNode{
+		ID:           n.ID,
+		Name:         n.Name,
+		Kind:         n.Kind,
+		Status:       n.Status,
+		SupersededBy: n.SupersededBy,
+		Summary:      n.Summary,
+		Description:  n.Description,
+		Tags:         n.Tags,
+		Condition:    n.Condition,
+		Edges:        edges,
+		Attributes:   n.Attributes,
+		CreatedAt:    n.CreatedAt,
+		UpdatedAt:    n.UpdatedAt,
+	}
+}
+
+// fromPersisted sets the node's persisted fields from their persisted form
+func (n *Node) fromPersisted(p persistedNode) {
+	edgeIDs, edgeAttributes := SplitEdgeTargets(p.Edges)
+	*n = Node{
+		ID:             p.ID,
+		Name:           p.Name,
+		Kind:           p.Kind,
+		Status:         p.Status,
+		SupersededBy:   p.SupersededBy,
+		Summary:        p.Summary,
+		Description:    p.Description,
+		Tags:           p.Tags,
+		Condition:      p.Condition,
+		EdgeIDs:        edgeIDs,
+		EdgeAttributes: edgeAttributes,
+		Attributes:     p.Attributes,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+// MarshalYAML writes the node in its persisted form
+func (n Node) MarshalYAML() (interface{}, error) {
+	return n.persisted(), nil
+}
+
+// UnmarshalYAML reads a node written in its persisted form. Edges may be
+// bare target IDs, as written before edges had attributes.
+func (n *Node) UnmarshalYAML(value *yaml.Node) error {
+	var p persistedNode
+	if err := value.Decode(&p); err != nil {
+		return err
+	}
+	n.fromPersisted(p)
+	return nil
+}
+
+// MarshalJSON writes the node in its persisted form
+func (n Node) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.persisted())
+}
+
+// UnmarshalJSON reads a node written in its persisted form
+func (n *Node) UnmarshalJSON(data []byte) error {
+	var p persistedNode
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	n.fromPersisted(p)
+	return nil
+}
@@ -0,0 +1,70 @@
+package types
+
+import "fmt"
+
+// NodeFields lists the node fields a node kind can require
+var NodeFields = []string{"name", "summary", "description", "tags"}
+
+// NodeKind describes one kind of node in a graph that mixes several, such as
+// tasks, services and runbooks, and what nodes of that kind must look like
+type NodeKind struct {
+	// Name is the identifier stored in the Kind field of the nodes
+	Name string `json:"name" yaml:"name"`
+
+	// RequiredFields lists the fields that may not be empty on nodes of this
+	// kind. See NodeFields.
+	RequiredFields []string `json:"required_fields,omitempty" yaml:"required_fields,omitempty"`
+
+	// Relationships lists the relationships nodes of this kind may have edges
+	// of. Empty allows every relationship.
+	Relationships []string `json:"relationships,omitempty" yaml:"relationships,omitempty"`
+}
+
+// KindName describes the kind stored in a node's Kind field for messages,
+// calling nodes without a kind untyped
+func KindName(kind string) string {
+	if kind == "" {
+		return "untyped"
+	}
+	return kind
+}
+
+// Validate checks if the node kind configuration is valid
+func (k NodeKind) Validate() error {
+	if k.Name == "" {
+		return fmt.Errorf("node kind name is required")
+	}
+	for _, field := range k.RequiredFields {
+		if !containsString(NodeFields, field) {
+			return fmt.Errorf("unknown required field %s: expected one of %v", field, NodeFields)
+		}
+	}
+	return nil
+}
+
+// MissingFields returns the required fields that are empty on the node
+func (k NodeKind) MissingFields(node *Node) []string {
+	var missing []string
+	for _, field := range k.RequiredFields {
+		var empty bool
+		switch field {
+		case "name":
+			empty = node.Name == ""
+		case "summary":
+			empty = node.Summary == ""
+		case "description":
+			empty = node.Description == ""
+		case "tags":
+			empty = len(node.Tags) == 0
+		}
+		if empty {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// AllowsRelationship reports whether nodes of this kind may have edges of the relationship
+func (k NodeKind) AllowsRelationship(relationshipName string) bool {
+	return len(k.Relationships) == 0 || containsString(k.Relationships, relationshipName)
+}
@@ -0,0 +1,50 @@
+package types
+
+import "fmt"
+
+// NodeStatus is the lifecycle state of a node
+type NodeStatus string
+
+const (
+	// StatusDraft marks a node that is still being written and shouldn't be followed yet
+	StatusDraft NodeStatus = "draft"
+
+	// StatusActive marks a node in use. Nodes without a status are active.
+	StatusActive NodeStatus = "active"
+
+	// StatusDeprecated marks a node that should no longer be used
+	StatusDeprecated NodeStatus = "deprecated"
+
+	// StatusSuperseded marks a node replaced by the node in its SupersededBy
+	StatusSuperseded NodeStatus = "superseded"
+)
+
+// NodeStatuses lists the lifecycle states in order
+var NodeStatuses = []NodeStatus{StatusDraft, StatusActive, StatusDeprecated, StatusSuperseded}
+
+// Validate checks if the status is one of NodeStatuses or empty
+func (s NodeStatus) Validate() error {
+	if s == "" {
+		return nil
+	}
+	for _, status := range NodeStatuses {
+		if s == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown status %s: expected one of %v", s, NodeStatuses)
+}
+
+// OrActive returns the status, or StatusActive if it is empty
+func (s NodeStatus) OrActive() NodeStatus {
+	if s == "" {
+		return StatusActive
+	}
+	return s
+}
+
+// IsDeprecated reports whether the status is deprecated or superseded, which
+// new edges may not point at unless explicitly allowed
+func (s NodeStatus) IsDeprecated() bool {
+	return s == StatusDeprecated || s == StatusSuperseded
+}
@@ -109,6 +109,75 @@ func TestNodeJSONMarshalling(t *testing.T) {
 	}
 }
 
+func TestNodeEdgeAttributesEncoding(t *testing.T) {
+	// Bare IDs and the object form can be mixed
+	data := []byte(`id: deploy
+name: Deploy
+edges:
+  prerequisites:
+    - build
+    - id: migrate
+      note: Schema changes need the new columns
+      condition: changed_paths contains "db/"
+      weight: 2
+`)
+	var node Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		t.Fatalf("Failed to unmarshal YAML: %v", err)
+	}
+	if ids := node.GetEdgeIDs("prerequisites"); len(ids) != 2 || ids[0] != "build" || ids[1] != "migrate" {
+		t.Fatalf("Expected prerequisites [build migrate], got %v", ids)
+	}
+	want := EdgeAttributes{Note: "Schema changes need the new columns", Condition: `changed_paths contains "db/"`, Weight: 2}
+	if got := node.GetEdgeAttributes("prerequisites", "migrate"); got != want {
+		t.Errorf("Expected attributes %+v, got %+v", want, got)
+	}
+	if got := node.GetEdgeAttributes("prerequisites", "build"); !got.IsZero() {
+		t.Errorf("Expected no attributes on build, got %+v", got)
+	}
+
+	// Edges without attributes are written as bare IDs
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		t.Fatalf("Failed to marshal YAML: %v", err)
+	}
+	if !strings.Contains(string(data), "- build\n") || !strings.Contains(string(data), "- id: migrate\n") {
+		t.Errorf("Unexpected YAML:\n%s", data)
+	}
+	var reloaded Node
+	if err := yaml.Unmarshal(data, &reloaded); err != nil {
+		t.Fatalf("Failed to unmarshal YAML: %v", err)
+	}
+	if !reloaded.Equals(&node) {
+		t.Error("Edge attributes should survive a YAML round trip")
+	}
+
+	data, err = json.Marshal(&node)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+	var decoded Node
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	if !decoded.Equals(&node) {
+		t.Errorf("Edge attributes should survive a JSON round trip: %s", data)
+	}
+
+	// Clones copy the attributes, and changing them changes the revision
+	clone := node.Clone()
+	if !clone.Equals(&node) {
+		t.Error("Clone() should copy the edge attributes")
+	}
+	clone.SetEdgeAttributes("prerequisites", "migrate", EdgeAttributes{Weight: 3})
+	if clone.Equals(&node) || clone.Revision() == node.Revision() {
+		t.Error("Changing edge attributes should change equality and the revision")
+	}
+	if got := node.GetEdgeAttributes("prerequisites", "migrate"); got != want {
+		t.Error("Changing a clone's edge attributes should not affect the original")
+	}
+}
+
 func TestNodeEquals(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 
@@ -185,6 +254,37 @@ func TestNodeEquals(t *testing.T) {
 			},
 			shouldEqual: false,
 		},
+		{
+			name:  "different Kind should not be equal",
+			node1: baseNode,
+			node2: &Node{
+				ID:          "test-1",
+				Name:        "Test Node",
+				Kind:        "runbook",
+				Summary:     "Summary",
+				Description: "Description",
+				Tags:        []string{"tag1", "tag2"},
+				EdgeIDs: map[string][]string{
+					"prerequisites": {"prereq-1", "prereq-2"},
+					"validates":     {"test-1"},
+				},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			shouldEqual: false,
+		},
+		{
+			name:        "different Attributes should not be equal",
+			node1:       &Node{ID: "test-1", Attributes: map[string]any{"prep_time": 10}},
+			node2:       &Node{ID: "test-1", Attributes: map[string]any{"prep_time": 15}},
+			shouldEqual: false,
+		},
+		{
+			name:        "numbers decoded as different types should be equal",
+			node1:       &Node{ID: "test-1", Attributes: map[string]any{"prep_time": 10}},
+			node2:       &Node{ID: "test-1", Attributes: map[string]any{"prep_time": float64(10)}},
+			shouldEqual: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -203,6 +303,8 @@ func TestNodeClone(t *testing.T) {
 	node := &Node{
 		ID:          "test-1",
 		Name:        "Test Node",
+		Kind:        "runbook",
+		Condition:   `environment == "prod"`,
 		Summary:     "Summary",
 		Description: "Description",
 		Tags:        []string{"tag1", "tag2"},
@@ -210,8 +312,9 @@ func TestNodeClone(t *testing.T) {
 			"prerequisites": {"prereq-1", "prereq-2"},
 			"validates":     {"test-1"},
 		},
-		CreatedAt: now,
-		UpdatedAt: now,
+		Attributes: map[string]any{"owner": "platform"},
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
 	clone := node.Clone()
@@ -236,6 +339,10 @@ func TestNodeClone(t *testing.T) {
 	if node.EdgeIDs["prerequisites"][0] == "modified" {
 		t.Error("Modifying clone.EdgeIDs should not affect original")
 	}
+	clone.Attributes["owner"] = "modified"
+	if node.Attributes["owner"] == "modified" {
+		t.Error("Modifying clone.Attributes should not affect original")
+	}
 }
 
 func TestNodeEdgeMethods(t *testing.T) {
@@ -283,6 +390,12 @@ func TestNodeEdgeMethods(t *testing.T) {
 			t.Fatalf("AddEdgeID failed: %v", err)
 		}
 
+		// Adding an existing ID again doesn't duplicate it
+		err = node.AddEdgeID("prerequisites", "prereq-1")
+		if err != nil {
+			t.Fatalf("AddEdgeID failed: %v", err)
+		}
+
 		ids := node.GetEdgeIDs("prerequisites")
 		if len(ids) != 2 {
 			t.Errorf("Expected 2 IDs, got %d", len(ids))
@@ -341,3 +454,84 @@ func TestNodeEdgeMethods(t *testing.T) {
 		}
 	})
 }
+
+func TestNodeRevision(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	node := &Node{
+		ID:      "task-a",
+		Name:    "Task A",
+		Tags:    []string{"backend"},
+		EdgeIDs: map[string][]string{"prerequisites": {"task-b"}, "related": nil},
+		Attributes: map[string]any{
+			"prep_time": 10,
+			"owner":     "platform",
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	revision := node.Revision()
+	if len(revision) != 16 {
+		t.Errorf("Expected a 16 character revision, got %q", revision)
+	}
+
+	// Equivalent representations keep the revision
+	equivalent := node.Clone()
+	delete(equivalent.EdgeIDs, "related")
+	equivalent.CreatedAt = created.In(time.FixedZone("UTC+2", 2*60*60))
+	if equivalent.Revision() != revision {
+		t.Error("Equivalent nodes should have the same revision")
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		t.Fatalf("Failed to marshal node: %v", err)
+	}
+	var reloaded Node
+	if err := yaml.Unmarshal(data, &reloaded); err != nil {
+		t.Fatalf("Failed to unmarshal node: %v", err)
+	}
+	if reloaded.Revision() != revision {
+		t.Error("Revision should survive a YAML round trip")
+	}
+
+	// JSON decodes numbers as float64
+	data, err = json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Failed to marshal node: %v", err)
+	}
+	var decoded Node
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal node: %v", err)
+	}
+	if decoded.Revision() != revision {
+		t.Error("Revision should survive a JSON round trip")
+	}
+
+	// Any content change produces a new revision
+	changes := map[string]func(n *Node){
+		"name":        func(n *Node) { n.Name = "Renamed" },
+		"summary":     func(n *Node) { n.Summary = "Summary" },
+		"kind":        func(n *Node) { n.Kind = "runbook" },
+		"condition":   func(n *Node) { n.Condition = `environment == "prod"` },
+		"status":      func(n *Node) { n.Status = StatusDeprecated },
+		"superseded":  func(n *Node) { n.SupersededBy = "task-c" },
+		"attributes":  func(n *Node) { n.Attributes["prep_time"] = 15 },
+		"value type":  func(n *Node) { n.Attributes["prep_time"] = "10" },
+		"tags":        func(n *Node) { n.Tags = append(n.Tags, "database") },
+		"edges":       func(n *Node) { n.EdgeIDs["prerequisites"] = append(n.EdgeIDs["prerequisites"], "task-c") },
+		"updated_at":  func(n *Node) { n.UpdatedAt = n.UpdatedAt.Add(time.Second) },
+		"field shift": func(n *Node) { n.Name, n.Summary = "Task", " A" },
+	}
+	for name, change := range changes {
+		changed := node.Clone()
+		change(changed)
+		if changed.Revision() == revision {
+			t.Errorf("Changing %s should change the revision", name)
+		}
+	}
+
+	var nilNode *Node
+	if nilNode.Revision() != "" {
+		t.Error("Nil node should have an empty revision")
+	}
+}
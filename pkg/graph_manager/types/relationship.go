@@ -3,7 +3,7 @@ package types
 import "fmt"
 
 // Relationship represents a type/category of relationship between nodes in the graph.
-// All relationships form directed acyclic graphs (DAGs).
+// Relationships form directed acyclic graphs (DAGs) unless their kind allows cycles.
 // For example, "prerequisites" (must be preceded by) or "downstream_required" (must be run after).
 type Relationship struct {
 	// Name is the identifier for this relationship type (e.g., "prerequisites", "validates")
@@ -17,6 +17,18 @@ type Relationship struct {
 	// "forward" means it points to things that come after (e.g., downstream tasks)
 	// "none" means the relationship has no temporal ordering
 	Direction RelationshipDirection `json:"direction" yaml:"direction"`
+
+	// Kind is the shape of the graph the relationship forms: "dag" (the default
+	// when empty), "symmetric" or "graph". See RelationshipKind.
+	Kind RelationshipKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Inverse optionally names the relationship that expresses the same link
+	// from the other end, e.g. "downstream_required" for "prerequisites".
+	// Declaring it on either side pairs both relationships.
+	Inverse string `json:"inverse,omitempty" yaml:"inverse,omitempty"`
+
+	// Constraints restrict the targets each node may have through the relationship
+	Constraints RelationshipConstraints `json:"constraints,omitempty" yaml:"constraints,omitempty"`
 }
 
 // NewRelationship creates a new relationship type with the given parameters
@@ -42,5 +54,36 @@ func (r Relationship) Validate() error {
 		return fmt.Errorf("invalid direction: %s", r.Direction)
 	}
 
+	switch r.Kind {
+	case "", KindDAG, KindGraph:
+		// Valid
+	case KindSymmetric:
+		// A symmetric edge runs both ways, so it can't order its nodes, and it
+		// is already its own inverse
+		if r.Direction != DirectionNone {
+			return fmt.Errorf("symmetric relationship must have direction none, got %s", r.Direction)
+		}
+		if r.Inverse != "" {
+			return fmt.Errorf("symmetric relationship cannot have an inverse")
+		}
+	default:
+		return fmt.Errorf("invalid kind: %s", r.Kind)
+	}
+
+	// A relationship that is its own inverse would pair every edge with one in
+	// the opposite direction; that's what the symmetric kind is for
+	if r.Inverse == r.Name {
+		return fmt.Errorf("relationship cannot be its own inverse, use kind symmetric instead")
+	}
+
+	if err := r.Constraints.Validate(); err != nil {
+		return fmt.Errorf("invalid constraints: %w", err)
+	}
+
 	return nil
 }
+
+// AllowsCycles reports whether the relationship's kind permits cycles
+func (r Relationship) AllowsCycles() bool {
+	return r.Kind == KindSymmetric || r.Kind == KindGraph
+}
@@ -0,0 +1,70 @@
+package types
+
+import "fmt"
+
+// RelationshipConstraints restrict the targets a node may have through a
+// relationship. The zero value allows any targets.
+type RelationshipConstraints struct {
+	// MinTargets is the fewest targets a node may have once it uses the
+	// relationship, or when the relationship is required for one of its tags
+	MinTargets int `json:"min_targets,omitempty" yaml:"min_targets,omitempty"`
+
+	// MaxTargets is the most targets a node may have. Zero means no limit.
+	MaxTargets int `json:"max_targets,omitempty" yaml:"max_targets,omitempty"`
+
+	// RequiredForTags lists tags whose nodes must have at least one target
+	RequiredForTags []string `json:"required_for_tags,omitempty" yaml:"required_for_tags,omitempty"`
+
+	// NoSelfReferences rejects edges from a node to itself
+	NoSelfReferences bool `json:"no_self_references,omitempty" yaml:"no_self_references,omitempty"`
+
+	// UniqueTargets rejects listing the same target more than once
+	UniqueTargets bool `json:"unique_targets,omitempty" yaml:"unique_targets,omitempty"`
+
+	// SourceKinds lists the node kinds that may have edges of the relationship.
+	// Empty allows every kind.
+	SourceKinds []string `json:"source_kinds,omitempty" yaml:"source_kinds,omitempty"`
+
+	// TargetKinds lists the node kinds edges of the relationship may point at.
+	// Empty allows every kind.
+	TargetKinds []string `json:"target_kinds,omitempty" yaml:"target_kinds,omitempty"`
+}
+
+// Validate checks if the constraints are consistent
+func (c RelationshipConstraints) Validate() error {
+	if c.MinTargets < 0 {
+		return fmt.Errorf("min_targets cannot be negative")
+	}
+	if c.MaxTargets < 0 {
+		return fmt.Errorf("max_targets cannot be negative")
+	}
+	if c.MaxTargets > 0 && c.MinTargets > c.MaxTargets {
+		return fmt.Errorf("min_targets %d is greater than max_targets %d", c.MinTargets, c.MaxTargets)
+	}
+	for _, tag := range c.RequiredForTags {
+		if tag == "" {
+			return fmt.Errorf("required_for_tags cannot contain an empty tag")
+		}
+	}
+	return nil
+}
+
+// AllowsSource reports whether nodes of the kind may have edges of the relationship
+func (c RelationshipConstraints) AllowsSource(kind string) bool {
+	return len(c.SourceKinds) == 0 || containsString(c.SourceKinds, kind)
+}
+
+// AllowsTarget reports whether edges of the relationship may point at nodes of the kind
+func (c RelationshipConstraints) AllowsTarget(kind string) bool {
+	return len(c.TargetKinds) == 0 || containsString(c.TargetKinds, kind)
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package types
+
+// RelationshipKind represents the shape of the graph a relationship forms
+type RelationshipKind string
+
+const (
+	// KindDAG indicates the relationship forms a directed acyclic graph. Changes
+	// that would introduce a cycle are rejected. This is the default.
+	KindDAG RelationshipKind = "dag"
+
+	// KindSymmetric indicates the relationship holds in both directions, so an
+	// edge from one node to another is kept on both nodes
+	KindSymmetric RelationshipKind = "symmetric"
+
+	// KindGraph indicates the relationship forms a directed graph that may contain cycles
+	KindGraph RelationshipKind = "graph"
+)
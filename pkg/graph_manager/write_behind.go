@@ -0,0 +1,96 @@
+package graph_manager
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WriteBehind coalesces persistence of a manager's pending changes.
+// Schedule starts a timer if none is running; when it fires, every change made
+// in the meantime is flushed in a single persist. With a zero interval Schedule
+// flushes immediately. Failed background flushes are logged and retried after
+// another interval.
+type WriteBehind struct {
+	manager  *Manager
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	closed bool
+}
+
+// NewWriteBehind creates a write-behind persister for the manager. The manager
+// must have been loaded from or persisted to a directory, which is where
+// changes are flushed to.
+func NewWriteBehind(manager *Manager, interval time.Duration) *WriteBehind {
+	return &WriteBehind{
+		manager:  manager,
+		interval: interval,
+		logger:   manager.logger,
+	}
+}
+
+// Schedule requests that pending changes be persisted. With a zero interval,
+// or once the persister is closed, changes are flushed before returning.
+func (w *WriteBehind) Schedule() error {
+	w.mu.Lock()
+	if w.interval <= 0 || w.closed {
+		w.mu.Unlock()
+		return w.manager.Flush()
+	}
+	defer w.mu.Unlock()
+
+	if w.timer == nil {
+		w.logger.Debug("Scheduling write-behind flush", zap.Duration("interval", w.interval))
+		w.timer = time.AfterFunc(w.interval, w.flushInBackground)
+	}
+	return nil
+}
+
+// Flush cancels any scheduled flush and persists pending changes now
+func (w *WriteBehind) Flush() error {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.mu.Unlock()
+
+	return w.manager.Flush()
+}
+
+// Close flushes pending changes. Later calls to Schedule flush immediately.
+func (w *WriteBehind) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+
+	return w.Flush()
+}
+
+// flushInBackground runs when the write-behind timer fires
+func (w *WriteBehind) flushInBackground() {
+	w.mu.Lock()
+	w.timer = nil
+	w.mu.Unlock()
+
+	pending := w.manager.PendingChanges()
+	if err := w.manager.Flush(); err != nil {
+		w.logger.Error("Write-behind flush failed, will retry",
+			zap.Int("pending_changes", pending),
+			zap.Error(err),
+		)
+
+		w.mu.Lock()
+		if !w.closed && w.timer == nil {
+			w.timer = time.AfterFunc(w.interval, w.flushInBackground)
+		}
+		w.mu.Unlock()
+		return
+	}
+
+	w.logger.Debug("Write-behind flush complete", zap.Int("changes_flushed", pending))
+}